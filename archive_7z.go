@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"           // Matches the 7z signature
+	"encoding/binary" // Decodes little-endian header fields
+	"errors"          // Creates 7z errors
+	"fmt"             // Formats 7z errors
+	"hash/crc32"      // Verifies header checksums
+	"strings"         // Normalizes entry names
+	"unicode/utf16"   // Decodes UTF-16 file names
+)
+
+var sevenZipSignature = []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c} // Marks 7z archives
+
+// Property IDs used in 7z headers
+const (
+	sevenZipEnd                   = 0x00 // kEnd
+	sevenZipHeader                = 0x01 // kHeader
+	sevenZipArchiveProperties     = 0x02 // kArchiveProperties
+	sevenZipAdditionalStreamsInfo = 0x03 // kAdditionalStreamsInfo
+	sevenZipMainStreamsInfo       = 0x04 // kMainStreamsInfo
+	sevenZipFilesInfo             = 0x05 // kFilesInfo
+	sevenZipPackInfo              = 0x06 // kPackInfo
+	sevenZipUnpackInfo            = 0x07 // kUnPackInfo
+	sevenZipSubStreamsInfo        = 0x08 // kSubStreamsInfo
+	sevenZipSize                  = 0x09 // kSize
+	sevenZipCRC                   = 0x0a // kCRC
+	sevenZipFolder                = 0x0b // kFolder
+	sevenZipCodersUnpackSize      = 0x0c // kCodersUnPackSize
+	sevenZipNumUnpackStream       = 0x0d // kNumUnPackStream
+	sevenZipEmptyStream           = 0x0e // kEmptyStream
+	sevenZipEmptyFile             = 0x0f // kEmptyFile
+	sevenZipName                  = 0x11 // kName
+	sevenZipEncodedHeader         = 0x17 // kEncodedHeader
+) // End of 7z property IDs
+
+// Checks the 7z signature header and the checksums of the start and end headers
+func check7zHeaders(data []byte) ([]byte, error) { // Function to locate the 7z end header
+	if len(data) < 32 || !bytes.HasPrefix(data, sevenZipSignature) { // Signature header is 32 bytes
+		return nil, errors.New("missing 7z signature") // Not a 7z archive
+	}
+	if crc32.ChecksumIEEE(data[12:32]) != binary.LittleEndian.Uint32(data[8:]) { // Start header CRC
+		return nil, errors.New("7z start header CRC mismatch") // Corrupt archive
+	}
+	headerOffset := binary.LittleEndian.Uint64(data[12:])                // Offset of the end header after the signature header
+	headerSize := binary.LittleEndian.Uint64(data[20:])                  // Size of the end header
+	available := uint64(len(data) - 32)                                  // Bytes after the signature header
+	if headerOffset > available || headerSize > available-headerOffset { // End header past the end of the file
+		return nil, errors.New("truncated 7z archive") // Most likely a partial transfer
+	}
+	header := data[32+headerOffset : 32+headerOffset+headerSize]             // The end header
+	if crc32.ChecksumIEEE(header) != binary.LittleEndian.Uint32(data[28:]) { // End header CRC
+		return nil, errors.New("7z header CRC mismatch") // Corrupt archive
+	}
+	return header, nil // Return the end header
+} // End of check7zHeaders function
+
+// Lists the files of a 7z archive from its header without decompressing any file contents. Only plain
+// headers can be read; compressed (encoded) headers, 7-Zip's default, would need an LZMA decoder.
+func list7z(data []byte) ([]archiveEntry, error) { // Function to read 7z file names and sizes
+	header, err := check7zHeaders(data) // Locate and verify the end header
+	if err != nil {                     // Handle corrupt archives
+		return nil, err // Propagate the error
+	}
+	if len(header) == 0 { // Empty archives have no header
+		return nil, nil // Nothing to list
+	}
+	reader := &sevenZipReader{data: header} // Read the header
+	switch reader.byte() {                  // Plain or compressed header
+	case sevenZipHeader: // Plain header
+		entries := reader.header() // Parse it
+		return entries, reader.err // Return the files
+	case sevenZipEncodedHeader: // Compressed header
+		return nil, errors.New("7z header is compressed and cannot be listed without an LZMA decoder") // Report the limitation
+	}
+	return nil, errors.New("unknown 7z header type") // Corrupt archive
+} // End of list7z function
+
+// Reads consecutive 7z header fields, remembering the first error
+type sevenZipReader struct {
+	data []byte // Remaining header bytes
+	err  error  // First malformed field
+} // End of sevenZipReader struct
+
+// Packed streams and folders described by a StreamsInfo block
+type sevenZipStreams struct {
+	folders     []sevenZipFolderInfo // Coder chains
+	streamSizes []uint64             // Unpacked size of every file stream, in order
+} // End of sevenZipStreams struct
+
+// One folder: a chain of coders producing a single unpacked stream
+type sevenZipFolderInfo struct {
+	unpackSize uint64 // Size of the folder's final output
+	hasCRC     bool   // Whether the folder output has a CRC
+} // End of sevenZipFolderInfo struct
+
+// Parses a plain header: optional archive properties and additional streams, the main streams, then the files
+func (reader *sevenZipReader) header() []archiveEntry { // Method to parse a plain 7z header
+	id := reader.byte()                  // First property
+	if id == sevenZipArchiveProperties { // Archive properties
+		for reader.err == nil && reader.byte() != sevenZipEnd { // Skip every property
+			reader.skip(reader.number()) // Skip its data
+		}
+		id = reader.byte() // Next property
+	}
+	if id == sevenZipAdditionalStreamsInfo { // Streams used by the header itself
+		reader.streamsInfo() // Parse and ignore them
+		id = reader.byte()   // Next property
+	}
+	var streamSizes []uint64           // Sizes of the non-empty files, in order
+	if id == sevenZipMainStreamsInfo { // Streams holding the file contents
+		streamSizes = reader.streamsInfo().streamSizes // Parse them
+		id = reader.byte()                             // Next property
+	}
+	var entries []archiveEntry   // Collected files
+	if id == sevenZipFilesInfo { // File names and attributes
+		entries = reader.filesInfo(streamSizes) // Parse them
+		id = reader.byte()                      // Next property
+	}
+	if id != sevenZipEnd { // Every header ends with kEnd
+		reader.fail("unexpected 7z header property") // Report the corruption
+	}
+	return entries // Return the files
+} // End of header method
+
+// Parses StreamsInfo: skips the packed streams, then reads the folders and the unpacked size of every file stream
+func (reader *sevenZipReader) streamsInfo() *sevenZipStreams { // Method to parse 7z streams info
+	streams := &sevenZipStreams{} // Parsed streams
+	id := reader.byte()           // First property
+	if id == sevenZipPackInfo {   // Packed streams
+		reader.number()                                                                      // Skip the pack position
+		packStreams := reader.count()                                                        // Number of packed streams
+		for id = reader.byte(); reader.err == nil && id != sevenZipEnd; id = reader.byte() { // Sizes and CRCs
+			switch id { // Handle the property
+			case sevenZipSize: // Packed sizes
+				for index := 0; index < packStreams && reader.err == nil; index++ { // One per stream
+					reader.number() // Skip the size
+				}
+			case sevenZipCRC: // Packed CRCs
+				reader.digests(uint64(packStreams)) // Skip the CRCs
+			default: // Unknown property
+				reader.fail("unexpected 7z pack info property") // Report the corruption
+			}
+		}
+		id = reader.byte() // Next property
+	}
+	if id == sevenZipUnpackInfo { // Folders (coder chains)
+		streams.folders = reader.unpackInfo() // Parse them
+		id = reader.byte()                    // Next property
+	}
+	for _, folder := range streams.folders { // Without substreams every folder holds one file
+		streams.streamSizes = append(streams.streamSizes, folder.unpackSize) // One stream per folder
+	}
+	if id == sevenZipSubStreamsInfo { // Files packed together in one folder
+		streams.streamSizes = reader.subStreamsInfo(streams.folders) // Parse them
+		id = reader.byte()                                           // Next property
+	}
+	if id != sevenZipEnd { // StreamsInfo ends with kEnd
+		reader.fail("unexpected 7z streams info property") // Report the corruption
+	}
+	return streams // Return the streams
+} // End of streamsInfo method
+
+// Parses UnpackInfo: every folder's coders and final unpacked size
+func (reader *sevenZipReader) unpackInfo() []sevenZipFolderInfo { // Method to parse 7z folders
+	if reader.byte() != sevenZipFolder { // Folders come first
+		reader.fail("missing 7z folder list") // Report the corruption
+		return nil                            // Nothing parsed
+	}
+	folders := make([]sevenZipFolderInfo, reader.count()) // Parsed folders
+	if reader.byte() != 0 {                               // Folders stored elsewhere
+		reader.fail("external 7z folders are not supported") // Report the limitation
+		return nil                                           // Nothing parsed
+	}
+
+	outStreams := make([]uint64, len(folders))  // Output streams per folder
+	finalOutput := make([]uint64, len(folders)) // Index of each folder's unbound output stream
+	for folder := range folders {               // Parse every folder
+		var totalIn, totalOut uint64                                   // Stream counts over all coders
+		coders := reader.count()                                       // Number of coders
+		for coder := 0; coder < coders && reader.err == nil; coder++ { // Parse every coder
+			flags := reader.byte()            // Coder flags
+			reader.skip(uint64(flags & 0x0f)) // Skip the codec ID
+			if flags&0x10 != 0 {              // Complex coder
+				totalIn += reader.number()  // Input streams
+				totalOut += reader.number() // Output streams
+			} else { // Simple coder
+				totalIn++  // One input stream
+				totalOut++ // One output stream
+			}
+			if flags&0x20 != 0 { // Coder properties
+				reader.skip(reader.number()) // Skip them
+			}
+		}
+		bound := map[uint64]bool{}                                            // Output streams consumed by other coders
+		for pair := uint64(1); pair < totalOut && reader.err == nil; pair++ { // totalOut-1 bind pairs
+			reader.number()               // Input index
+			bound[reader.number()] = true // Output index
+		}
+		if packed := totalIn - (totalOut - 1); totalOut > 0 && packed > 1 { // Explicit packed stream indexes
+			for index := uint64(0); index < packed && reader.err == nil; index++ { // One per packed stream
+				reader.number() // Skip the index
+			}
+		}
+		outStreams[folder] = totalOut                          // Remember the stream count
+		for output := uint64(0); output < totalOut; output++ { // Find the unbound output
+			if !bound[output] { // Not consumed by another coder
+				finalOutput[folder] = output // The folder's result
+				break                        // Done
+			}
+		}
+	}
+
+	if reader.byte() != sevenZipCodersUnpackSize { // Sizes follow the folders
+		reader.fail("missing 7z unpack sizes") // Report the corruption
+		return nil                             // Nothing parsed
+	}
+	for folder := range folders { // Read every folder's output sizes
+		for output := uint64(0); output < outStreams[folder] && reader.err == nil; output++ { // One size per output stream
+			size := reader.number()            // Output size
+			if output == finalOutput[folder] { // The folder's result
+				folders[folder].unpackSize = size // Remember it
+			}
+		}
+	}
+	id := reader.byte()    // Optional CRCs, then kEnd
+	if id == sevenZipCRC { // Folder CRCs
+		for folder, defined := range reader.digests(uint64(len(folders))) { // Parse them
+			folders[folder].hasCRC = defined // Remember which folders have one
+		}
+		id = reader.byte() // Next property
+	}
+	if id != sevenZipEnd { // UnpackInfo ends with kEnd
+		reader.fail("unexpected 7z unpack info property") // Report the corruption
+	}
+	return folders // Return the folders
+} // End of unpackInfo method
+
+// Parses SubStreamsInfo and returns the size of every file stream across all folders
+func (reader *sevenZipReader) subStreamsInfo(folders []sevenZipFolderInfo) []uint64 { // Method to split folders into files
+	streamCounts := make([]uint64, len(folders)) // Files per folder
+	for folder := range streamCounts {           // Default to one file per folder
+		streamCounts[folder] = 1 // One file
+	}
+	id := reader.byte()                // First property
+	if id == sevenZipNumUnpackStream { // Explicit file counts
+		for folder := range streamCounts { // One count per folder
+			streamCounts[folder] = reader.number() // Files in the folder
+		}
+		id = reader.byte() // Next property
+	}
+
+	var streamSizes []uint64                  // Size of every file stream
+	for folder, count := range streamCounts { // Split every folder
+		if count == 0 || reader.err != nil { // Folder without files
+			continue // Nothing to split
+		}
+		var sum uint64                                                                                 // Sizes read so far
+		for stream := uint64(1); stream < count && id == sevenZipSize && reader.err == nil; stream++ { // All but the last size are stored
+			size := reader.number()                 // Stream size
+			streamSizes = append(streamSizes, size) // Record it
+			sum += size                             // Add it up
+		}
+		if sum > folders[folder].unpackSize { // Sizes larger than the folder
+			reader.fail("7z substream sizes exceed the folder size") // Report the corruption
+			break                                                    // Stop splitting
+		}
+		streamSizes = append(streamSizes, folders[folder].unpackSize-sum) // The last stream takes the rest
+	}
+	if id == sevenZipSize { // Sizes were consumed above
+		id = reader.byte() // Next property
+	}
+
+	if id == sevenZipCRC { // Stream CRCs
+		var withoutCRC uint64                     // Streams whose CRC is not already known from the folder
+		for folder, count := range streamCounts { // Count them
+			if count != 1 || !folders[folder].hasCRC { // Folder CRC does not cover the stream
+				withoutCRC += count // Needs its own CRC
+			}
+		}
+		reader.digests(withoutCRC) // Skip the CRCs
+		id = reader.byte()         // Next property
+	}
+	if id != sevenZipEnd { // SubStreamsInfo ends with kEnd
+		reader.fail("unexpected 7z substreams property") // Report the corruption
+	}
+	return streamSizes // Return the stream sizes
+} // End of subStreamsInfo method
+
+// Parses FilesInfo: names plus the empty-stream flags that mark directories and empty files
+func (reader *sevenZipReader) filesInfo(streamSizes []uint64) []archiveEntry { // Method to parse 7z file records
+	fileCount := reader.count()            // Number of files
+	emptyStream := make([]bool, fileCount) // Files without contents (directories or empty files)
+	var emptyFile []bool                   // Which empty-stream files are files rather than directories
+	names := make([]string, fileCount)     // File names
+	for reader.err == nil {                // Visit every property
+		propertyType := reader.number()  // Property type
+		if propertyType == sevenZipEnd { // End of the file properties
+			break // Done
+		}
+		property := &sevenZipReader{data: reader.bytes(reader.number())} // Property data
+		switch propertyType {                                            // Handle the property
+		case sevenZipEmptyStream: // Empty-stream flags
+			emptyStream = property.bits(uint64(fileCount)) // Parse them
+		case sevenZipEmptyFile: // Empty-file flags, one per empty stream
+			emptyFile = property.bits(uint64(countTrue(emptyStream))) // Parse them
+		case sevenZipName: // File names
+			if property.byte() != 0 { // Names stored elsewhere
+				property.fail("external 7z names are not supported") // Report the limitation
+			}
+			for index := range names { // One NUL-terminated UTF-16LE name per file
+				names[index] = property.utf16String() // Decode the name
+			}
+		}
+		if property.err != nil { // Malformed property
+			reader.err = property.err // Report it
+		}
+	}
+
+	var entries []archiveEntry       // Collected files
+	stream, empty := 0, 0            // Indexes into the stream sizes and the empty-file flags
+	for index, name := range names { // Pair every name with its size
+		if reader.err != nil { // Stop on malformed headers
+			break // Done
+		}
+		entry := archiveEntry{Name: strings.ReplaceAll(name, "\\", "/")} // Describe the file
+		if emptyStream[index] {                                          // No contents: a directory or an empty file
+			isFile := empty < len(emptyFile) && emptyFile[empty] // Empty files are flagged explicitly
+			empty++                                              // Advance through the empty-file flags
+			if !isFile {                                         // Directories are implied by file paths
+				continue // Skip the directory
+			}
+		} else { // Regular file with contents
+			if stream >= len(streamSizes) { // More files than streams
+				reader.fail("7z file without a stream") // Report the corruption
+				break                                   // Stop pairing
+			}
+			entry.Size = int64(streamSizes[stream]) // Size of its stream
+			stream++                                // Advance to the next stream
+		}
+		entries = append(entries, entry) // Record the file
+	}
+	return entries // Return the files
+} // End of filesInfo method
+
+// Reads a CRC list: an all-defined byte (or a bit vector), then one CRC32 per defined item
+func (reader *sevenZipReader) digests(count uint64) []bool { // Method to skip 7z digests
+	defined := make([]bool, count) // Which items have a CRC
+	if reader.byte() != 0 {        // All items have one
+		for index := range defined { // Mark every item
+			defined[index] = true // Defined
+		}
+	} else { // Explicit bit vector
+		defined = reader.bits(count) // Parse it
+	}
+	reader.skip(4 * uint64(countTrue(defined))) // Skip the CRCs
+	return defined                              // Return the flags
+} // End of digests method
+
+// Reads a bit vector, most significant bit first
+func (reader *sevenZipReader) bits(count uint64) []bool { // Method to read 7z bit vectors
+	packed := reader.bytes((count + 7) / 8) // Packed bits
+	flags := make([]bool, count)            // Unpacked flags
+	for index := range flags {              // Unpack every bit
+		if packed != nil { // Not truncated
+			flags[index] = packed[index/8]&(0x80>>(index%8)) != 0 // Test the bit
+		}
+	}
+	return flags // Return the flags
+} // End of bits method
+
+// Reads a NUL-terminated UTF-16LE string
+func (reader *sevenZipReader) utf16String() string { // Method to read a 7z file name
+	var units []uint16 // UTF-16 code units
+	for {              // Read until the terminator
+		unit := reader.bytes(2) // Next code unit
+		if unit == nil {        // Truncated name
+			return "" // Empty name
+		}
+		if unit[0] == 0 && unit[1] == 0 { // Terminator
+			return string(utf16.Decode(units)) // Decode the name
+		}
+		units = append(units, binary.LittleEndian.Uint16(unit)) // Collect the unit
+	}
+} // End of utf16String method
+
+// Reads a 7z variable-length number: the leading one bits of the first byte give the number of extra bytes
+func (reader *sevenZipReader) number() uint64 { // Method to read a 7z number
+	first := reader.byte()               // First byte
+	var value uint64                     // Decoded value
+	mask := byte(0x80)                   // Current length bit
+	for index := 0; index < 8; index++ { // At most eight extra bytes
+		if first&mask == 0 { // No more extra bytes
+			return value | uint64(first&(mask-1))<<(8*index) // Add the remaining high bits
+		}
+		value |= uint64(reader.byte()) << (8 * index) // Add the next byte
+		mask >>= 1                                    // Next length bit
+	}
+	return value // Full 64-bit value
+} // End of number method
+
+// Reads a number used as a count, guarding against counts larger than the header could describe
+func (reader *sevenZipReader) count() int { // Method to read a 7z count
+	value := reader.number()                // Raw count
+	if value > uint64(len(reader.data))+1 { // Every counted item takes at least one byte
+		reader.fail(fmt.Sprintf("7z count %d exceeds the header size", value)) // Report the corruption
+		return 0                                                               // Nothing to count
+	}
+	return int(value) // Return the count
+} // End of count method
+
+// Reads one byte
+func (reader *sevenZipReader) byte() byte { // Method to read a byte
+	field := reader.bytes(1) // Consume the byte
+	if field == nil {        // Truncated header
+		return 0 // Zero value (kEnd)
+	}
+	return field[0] // Return the byte
+} // End of byte method
+
+// Skips bytes
+func (reader *sevenZipReader) skip(count uint64) { // Method to skip bytes
+	reader.bytes(count) // Consume the bytes
+} // End of skip method
+
+// Reads count raw bytes, or nil past the end of the header
+func (reader *sevenZipReader) bytes(count uint64) []byte { // Method to read raw bytes
+	if count > uint64(len(reader.data)) { // Past the end of the header
+		reader.fail("truncated 7z header") // Remember the error
+		return nil                         // No bytes
+	}
+	field := reader.data[:count]      // Bytes to return
+	reader.data = reader.data[count:] // Consume them
+	return field                      // Return the bytes
+} // End of bytes method
+
+// Records a malformed header and empties the reader
+func (reader *sevenZipReader) fail(message string) { // Method to record a header error
+	if reader.err == nil { // Keep the first error
+		reader.err = errors.New(message) // Record the error
+	}
+	reader.data = nil // Stop reading
+} // End of fail method
+
+// Counts the true values in a slice
+func countTrue(flags []bool) int { // Function to count set flags
+	count := 0                   // Number of set flags
+	for _, flag := range flags { // Check every flag
+		if flag { // Set flag
+			count++ // Count it
+		}
+	}
+	return count // Return the count
+} // End of countTrue function
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"           // Matches RAR signatures
+	"encoding/binary" // Decodes little-endian header fields
+	"errors"          // Creates RAR errors
+	"fmt"             // Formats RAR errors
+	"hash/crc32"      // Verifies header checksums
+	"strings"         // Normalizes entry names
+)
+
+var rar4Signature = []byte("Rar!\x1a\x07\x00")     // Marks RAR 1.5–4.x archives
+var rar5Signature = []byte("Rar!\x1a\x07\x01\x00") // Marks RAR 5 archives
+
+var errRARHeadersEncrypted = errors.New("RAR headers are encrypted") // File names are unreadable without the password
+
+// Lists the files of a RAR archive from its (unencrypted) headers without decompressing anything
+func listRAR(data []byte) ([]archiveEntry, error) { // Function to read RAR file headers
+	switch { // Pick the header format
+	case bytes.HasPrefix(data, rar5Signature): // RAR 5
+		return listRAR5(data[len(rar5Signature):]) // Parse RAR 5 blocks
+	case bytes.HasPrefix(data, rar4Signature): // RAR 4
+		return listRAR4(data[len(rar4Signature):]) // Parse RAR 4 blocks
+	}
+	return nil, errors.New("missing RAR signature") // Not a RAR archive
+} // End of listRAR function
+
+// Parses RAR 5 blocks: CRC32, vint header size, then type, flags, optional extra and data sizes
+func listRAR5(data []byte) ([]archiveEntry, error) { // Function to read RAR 5 file headers
+	var entries []archiveEntry // Collected entries
+	position := 0              // Offset of the current block
+	for position < len(data) { // Visit every block
+		if len(data)-position < 5 { // Too short for a header
+			return nil, errors.New("truncated RAR header") // Report the truncation
+		}
+		headerCRC := binary.LittleEndian.Uint32(data[position:])           // Checksum of the header
+		headerSize, sizeLength := readRARVint(data[position+4:])           // Size of the header after the size field
+		headerStart := position + 4 + sizeLength                           // First byte of the header
+		if sizeLength == 0 || headerSize > uint64(len(data)-headerStart) { // Corrupt or truncated size
+			return nil, errors.New("truncated RAR header") // Report the truncation
+		}
+		headerEnd := headerStart + int(headerSize)                       // Last byte of the header
+		if crc32.ChecksumIEEE(data[position+4:headerEnd]) != headerCRC { // Verify the header
+			return nil, fmt.Errorf("RAR header CRC mismatch at offset %d", position) // Report the corruption
+		}
+
+		header := rarFieldReader{data: data[headerStart:headerEnd]} // Read the header fields
+		headerType := header.vint()                                 // Block type
+		headerFlags := header.vint()                                // Common header flags
+		if headerFlags&0x01 != 0 {                                  // Extra area present
+			header.vint() // Extra area size (the area sits at the end of the header)
+		}
+		var dataSize uint64        // Bytes following the header
+		if headerFlags&0x02 != 0 { // Data area present
+			dataSize = header.vint() // Size of the packed data
+		}
+
+		switch headerType { // Handle the block type
+		case 2: // File header
+			fileFlags := header.vint()    // File flags
+			unpackedSize := header.vint() // Uncompressed size
+			header.vint()                 // Attributes
+			if fileFlags&0x02 != 0 {      // Modification time present
+				header.skip(4) // Skip the time
+			}
+			if fileFlags&0x04 != 0 { // Data CRC present
+				header.skip(4) // Skip the CRC
+			}
+			header.vint()                            // Compression information
+			header.vint()                            // Host OS
+			name := header.bytes(int(header.vint())) // UTF-8 file name
+			if header.err != nil {                   // Malformed file header
+				return nil, header.err // Report the error
+			}
+			if fileFlags&0x01 == 0 { // Files only; directories are implied
+				entries = append(entries, archiveEntry{Name: strings.ReplaceAll(string(name), "\\", "/"), Size: int64(unpackedSize)}) // Record the file
+			}
+		case 4: // Archive encryption header
+			return nil, errRARHeadersEncrypted // File names are unreadable
+		case 5: // End of archive
+			return entries, nil // Done
+		}
+		if dataSize > uint64(len(data)-headerEnd) { // Data area past the end of the file
+			return nil, errors.New("truncated RAR data") // Report the truncation
+		}
+		position = headerEnd + int(dataSize) // Skip to the next block
+	}
+	return entries, nil // Archive without an end block
+} // End of listRAR5 function
+
+// Parses RAR 4 blocks: CRC16, type, flags, header size and an optional 32-bit data size
+func listRAR4(data []byte) ([]archiveEntry, error) { // Function to read RAR 4 file headers
+	var entries []archiveEntry    // Collected entries
+	position := 0                 // Offset of the current block
+	for position+7 <= len(data) { // Visit every block
+		headerCRC := binary.LittleEndian.Uint16(data[position:])         // Low 16 bits of the header CRC32
+		headerType := data[position+2]                                   // Block type
+		headerFlags := binary.LittleEndian.Uint16(data[position+3:])     // Block flags
+		headerSize := int(binary.LittleEndian.Uint16(data[position+5:])) // Header size including these fields
+		if headerSize < 7 || headerSize > len(data)-position {           // Corrupt or truncated header
+			return nil, errors.New("truncated RAR header") // Report the truncation
+		}
+		if uint16(crc32.ChecksumIEEE(data[position+2:position+headerSize])) != headerCRC { // Verify the header
+			return nil, fmt.Errorf("RAR header CRC mismatch at offset %d", position) // Report the corruption
+		}
+
+		header := rarFieldReader{data: data[position+7 : position+headerSize]} // Read the type-specific fields
+		var dataSize uint64                                                    // Bytes following the header
+		if headerFlags&0x8000 != 0 {                                           // Long block: a data size follows
+			dataSize = uint64(header.uint32()) // Packed size
+		}
+
+		switch headerType { // Handle the block type
+		case 0x73: // Main archive header
+			if headerFlags&0x0080 != 0 { // Headers are encrypted
+				return nil, errRARHeadersEncrypted // File names are unreadable
+			}
+		case 0x74: // File header
+			unpackedSize := uint64(header.uint32()) // Low 32 bits of the uncompressed size
+			header.skip(1 + 4 + 4 + 1 + 1)          // Host OS, file CRC, time, version, method
+			nameSize := int(header.uint16())        // Length of the name field
+			header.skip(4)                          // Attributes
+			if headerFlags&0x0100 != 0 {            // 64-bit sizes
+				dataSize |= uint64(header.uint32()) << 32     // High 32 bits of the packed size
+				unpackedSize |= uint64(header.uint32()) << 32 // High 32 bits of the uncompressed size
+			}
+			name := header.bytes(nameSize) // File name
+			if header.err != nil {         // Malformed file header
+				return nil, header.err // Report the error
+			}
+			if headerFlags&0x0200 != 0 { // Unicode names store an ASCII form, a NUL, then an encoded form
+				name, _, _ = bytes.Cut(name, []byte{0}) // Keep the ASCII form
+			}
+			if headerFlags&0x00e0 != 0x00e0 { // Files only; directories are implied
+				entries = append(entries, archiveEntry{Name: strings.ReplaceAll(string(name), "\\", "/"), Size: int64(unpackedSize)}) // Record the file
+			}
+		case 0x7b: // End of archive
+			return entries, nil // Done
+		}
+		if dataSize > uint64(len(data)-position-headerSize) { // Data area past the end of the file
+			return nil, errors.New("truncated RAR data") // Report the truncation
+		}
+		position += headerSize + int(dataSize) // Skip to the next block
+	}
+	return entries, nil // Archive without an end block
+} // End of listRAR4 function
+
+// Decodes a RAR 5 variable-length integer (7 bits per byte, least significant first), returning the value and
+// the number of bytes read, or 0 bytes when the integer is truncated or too long
+func readRARVint(data []byte) (uint64, int) { // Function to decode a vint
+	var value uint64                                           // Decoded value
+	for index := 0; index < len(data) && index < 10; index++ { // At most 10 bytes encode 64 bits
+		value |= uint64(data[index]&0x7f) << (7 * index) // Add the low 7 bits
+		if data[index]&0x80 == 0 {                       // No continuation bit
+			return value, index + 1 // Done
+		}
+	}
+	return 0, 0 // Truncated or too long
+} // End of readRARVint function
+
+// Reads consecutive fields from a RAR header, remembering the first error
+type rarFieldReader struct {
+	data []byte // Remaining header bytes
+	err  error  // First read past the end of the header
+} // End of rarFieldReader struct
+
+// Reads a RAR 5 variable-length integer
+func (reader *rarFieldReader) vint() uint64 { // Method to read a vint
+	value, length := readRARVint(reader.data) // Decode the integer
+	if length == 0 {                          // Truncated field
+		reader.fail() // Remember the error
+		return 0      // Zero value
+	}
+	reader.data = reader.data[length:] // Consume the bytes
+	return value                       // Return the value
+} // End of vint method
+
+// Reads a little-endian 16-bit integer
+func (reader *rarFieldReader) uint16() uint16 { // Method to read two bytes
+	field := reader.bytes(2) // Consume the bytes
+	if field == nil {        // Truncated field
+		return 0 // Zero value
+	}
+	return binary.LittleEndian.Uint16(field) // Decode the integer
+} // End of uint16 method
+
+// Reads a little-endian 32-bit integer
+func (reader *rarFieldReader) uint32() uint32 { // Method to read four bytes
+	field := reader.bytes(4) // Consume the bytes
+	if field == nil {        // Truncated field
+		return 0 // Zero value
+	}
+	return binary.LittleEndian.Uint32(field) // Decode the integer
+} // End of uint32 method
+
+// Skips bytes
+func (reader *rarFieldReader) skip(count int) { // Method to skip bytes
+	reader.bytes(count) // Consume the bytes
+} // End of skip method
+
+// Reads count raw bytes, or nil past the end of the header
+func (reader *rarFieldReader) bytes(count int) []byte { // Method to read raw bytes
+	if count < 0 || count > len(reader.data) { // Past the end of the header
+		reader.fail() // Remember the error
+		return nil    // No bytes
+	}
+	field := reader.data[:count]      // Bytes to return
+	reader.data = reader.data[count:] // Consume them
+	return field                      // Return the bytes
+} // End of bytes method
+
+// Records a read past the end of the header and empties the reader
+func (reader *rarFieldReader) fail() { // Method to record a truncated header
+	if reader.err == nil { // Keep the first error
+		reader.err = errors.New("truncated RAR header field") // Record the truncation
+	}
+	reader.data = nil // Stop reading
+} // End of fail method
@@ -0,0 +1,141 @@
+package betaflight
+
+import (
+	"errors"  // Unwraps parse error lists
+	"fmt"     // Formats problem messages
+	"strings" // Matches batch arguments
+)
+
+// What a file in the dump corpus is
+type Kind string
+
+// Kinds of dump files
+const (
+	KindDump     Kind = "dump"     // Full `dump` output: every setting, with profiles and rate profiles
+	KindDiff     Kind = "diff"     // `diff all` output or a target config: a header and only the non-default settings
+	KindSnippet  Kind = "snippet"  // CLI commands without a firmware header, meant to be pasted on top of a config
+	KindVTXTable Kind = "vtxtable" // Only a vtxtable block
+	KindNotCLI   Kind = "not_cli"  // Not CLI input at all (firmware hex, ESC settings, ...)
+) // End of dump kinds
+
+const fullDumpSettings = 200 // A dump prints every master setting (400+ since 4.0); a diff rarely changes this many
+
+// Codes of completeness and encoding problems
+const (
+	ProblemMissingHeader    = "missing_header"     // A dump whose firmware header was not copied
+	ProblemMissingSave      = "missing_save"       // No save command, so pasting the file changes nothing permanently
+	ProblemMissingBatchEnd  = "missing_batch_end"  // batch start without batch end
+	ProblemMissingProfiles  = "missing_profiles"   // A dump without its profile section
+	ProblemMissingRates     = "missing_rates"      // A dump without its rate profile section
+	ProblemCommandsAfterEnd = "commands_after_end" // Commands after save or batch end are never applied
+	ProblemUnparsableLines  = "unparsable_lines"   // Lines the CLI would reject
+	ProblemBOM              = "bom"                // UTF-8 byte order mark
+	ProblemCRLF             = "crlf"               // Windows line endings
+	ProblemInvalidUTF8      = "invalid_utf8"       // Bytes that are not UTF-8
+) // End of problem codes
+
+// One completeness or encoding problem
+type Problem struct {
+	Code    string `json:"code"`           // Problem code (see the Problem constants)
+	Line    int    `json:"line,omitempty"` // Line it refers to, if any
+	Message string `json:"message"`        // Description
+} // End of Problem struct
+
+// The kind of a dump file and what is missing from it
+type Classification struct {
+	Kind     Kind      `json:"kind"`     // What the file is
+	Batch    bool      `json:"batch"`    // Whether the commands are wrapped in batch start / batch end
+	Saved    bool      `json:"saved"`    // Whether the file ends with save
+	Complete bool      `json:"complete"` // Whether no problems were found
+	Problems []Problem `json:"problems"` // Structural problems, rejected lines, then encoding problems
+	Encoding Encoding  `json:"encoding"` // Line endings and byte order mark
+} // End of Classification struct
+
+// Classifies a parsed dump; parseErr is the error Parse returned with it
+func Classify(dump *Dump, parseErr error) *Classification { // Function to classify a dump file
+	var lineErrors ErrorList         // Lines the parser rejected
+	errors.As(parseErr, &lineErrors) // Empty when the parse was clean
+
+	result := &Classification{Kind: dump.kind(len(lineErrors)), Problems: []Problem{}, Encoding: dump.Encoding} // Start with the kind
+	if result.Kind == KindNotCLI {                                                                              // Completeness means nothing for these files
+		result.Complete = true // Nothing is missing from what it is
+		return result          // Done
+	}
+
+	batchStart, batchEnd, save, lastCommand := 0, 0, 0, 0 // Lines of the structural commands
+	for _, command := range dump.Commands {               // Find them
+		lastCommand = command.Line // Last command so far
+		switch {                   // Structural commands
+		case command.Name == "batch" && len(command.Args) > 0 && strings.EqualFold(command.Args[0], "start"): // batch start
+			batchStart = command.Line // Record it
+		case command.Name == "batch" && len(command.Args) > 0 && strings.EqualFold(command.Args[0], "end"): // batch end
+			batchEnd = command.Line // Record it
+		case command.Name == "save": // save
+			save = command.Line // Record it
+		}
+	}
+	result.Batch = batchStart > 0 // Wrapped in a batch
+	result.Saved = save > 0       // Ends with save
+
+	if result.Kind == KindDump && dump.Build == nil { // Copied without the version block
+		result.add(ProblemMissingHeader, 1, "dump has no firmware header; the firmware version is unknown") // Report it
+	}
+	if batchStart > 0 && batchEnd == 0 { // The batch never ends
+		result.add(ProblemMissingBatchEnd, batchStart, "batch start without batch end; the CLI keeps waiting for more commands") // Report it
+	}
+	if save == 0 { // Nothing saves the configuration
+		result.add(ProblemMissingSave, lastCommand, "no save command after the last command; the configuration is lost on reboot") // Report it
+	}
+	for _, command := range dump.Ignored { // Commands the parser left out of the model
+		result.add(ProblemCommandsAfterEnd, command.Line, fmt.Sprintf("%s after save or batch end is not applied", command.Name)) // Report each one
+	}
+	if result.Kind == KindDump && len(dump.Profiles) == 0 { // Dump cut off before the profiles
+		result.add(ProblemMissingProfiles, lastCommand, "dump has no profile section; it looks truncated") // Report it
+	}
+	if result.Kind == KindDump && len(dump.RateProfiles) == 0 { // Dump cut off before the rate profiles
+		result.add(ProblemMissingRates, lastCommand, "dump has no rateprofile section; it looks truncated") // Report it
+	}
+	for _, lineError := range lineErrors { // Lines the CLI would reject
+		result.add(ProblemUnparsableLines, lineError.Line, lineError.Message) // Report each one
+	}
+	if dump.Encoding.BOM { // Byte order mark
+		result.add(ProblemBOM, 1, "file starts with a UTF-8 byte order mark") // Report it
+	}
+	if dump.Encoding.CRLFLines > 0 { // Windows line endings
+		result.add(ProblemCRLF, 0, fmt.Sprintf("%d of %d lines end in CR LF", dump.Encoding.CRLFLines, dump.Encoding.Lines)) // Report it
+	}
+	if dump.Encoding.InvalidUTF8Lines > 0 { // Broken characters
+		result.add(ProblemInvalidUTF8, 0, fmt.Sprintf("%d lines are not valid UTF-8", dump.Encoding.InvalidUTF8Lines)) // Report it
+	}
+	result.Complete = len(result.Problems) == 0 // Complete when nothing was found
+	return result                               // Return the classification
+} // End of Classify function
+
+// Records a problem
+func (result *Classification) add(code string, line int, message string) { // Method to add a problem
+	result.Problems = append(result.Problems, Problem{Code: code, Line: line, Message: message}) // Append it
+} // End of add method
+
+// Decides what kind of file the dump came from
+func (dump *Dump) kind(unparsable int) Kind { // Method to pick the dump kind
+	if len(dump.Commands) == 0 || unparsable > len(dump.Commands) { // Mostly lines the CLI would reject
+		return KindNotCLI // Not CLI input
+	}
+	vtxTableOnly := true                    // Whether every command is part of a vtxtable block
+	for _, command := range dump.Commands { // Check every command
+		switch command.Name { // vtxtable blocks may be wrapped and saved
+		case "vtxtable", "batch", "save", "exit", "defaults": // Allowed in a vtxtable file
+		default: // Anything else
+			vtxTableOnly = false // Not vtxtable-only
+		}
+	}
+	switch { // Pick the kind
+	case vtxTableOnly && dump.VTXTable != nil: // Only a table
+		return KindVTXTable // vtxtable file
+	case len(dump.Master) >= fullDumpSettings: // Every setting printed
+		return KindDump // Full dump, even if the header was not copied
+	case dump.Build == nil: // No firmware header
+		return KindSnippet // Pasted commands
+	}
+	return KindDiff // Only changes
+} // End of kind method
@@ -0,0 +1,96 @@
+package betaflight
+
+import (
+	"fmt"     // Builds master settings
+	"strings" // Builds files
+	"testing" // Test framework
+)
+
+// Returns the lines of a full dump: header, enough master settings, profiles, rate profiles and save
+func fullDumpLines() []string { // Helper to build a complete dump
+	lines := []string{header44, "batch start", "defaults nosave"} // Header and batch
+	for index := range fullDumpSettings {                         // Every master setting
+		lines = append(lines, fmt.Sprintf("set setting_%d = %d", index, index)) // One setting
+	}
+	return append(lines, "profile 0", "set p_pitch = 47", "rateprofile 0", "set roll_rc_rate = 100", "batch end", "save") // Profiles, then the end
+} // End of fullDumpLines function
+
+// Classifies text and returns the classification
+func classifyText(t *testing.T, text string) *Classification { // Helper to classify a file
+	t.Helper()                                  // Report the caller's line
+	dump, err := Parse(strings.NewReader(text)) // Parse it; rejected lines are part of the classification
+	if dump == nil {                            // Nothing came back
+		t.Fatalf("Parse() = nil, %v", err) // Stop the test
+	}
+	return Classify(dump, err) // Classify it
+} // End of classifyText function
+
+// Counts the problems with a code
+func problemCount(result *Classification, code string) int { // Helper to count problems
+	count := 0                                // Matches
+	for _, problem := range result.Problems { // Check every problem
+		if problem.Code == code { // Same code
+			count++ // Count it
+		}
+	}
+	return count // Return the count
+} // End of problemCount function
+
+// Checks that a complete dump has no problems and the kinds of the other files
+func TestClassifyKinds(t *testing.T) { // Test of the file kinds
+	full := classifyText(t, strings.Join(fullDumpLines(), "\n"))                                          // Complete dump
+	if full.Kind != KindDump || !full.Complete || !full.Batch || !full.Saved || len(full.Problems) != 0 { // Nothing missing
+		t.Errorf("dump = %+v", full) // Report it
+	}
+	tests := []struct {
+		name string // Case name
+		text string // File content
+		kind Kind   // Expected kind
+	}{
+		{name: "diff", text: header44 + "\nset gyro_lpf1_static_hz = 0\nsave", kind: KindDiff},                                             // Header and a few changes
+		{name: "snippet", text: "set gyro_lpf1_static_hz = 0\nsave", kind: KindSnippet},                                                    // No header
+		{name: "vtxtable", text: strings.Join(vtxTableLines, "\n") + "\nsave", kind: KindVTXTable},                                         // Only a table
+		{name: "not cli", text: ":020000040800F2\n:10000000004002201D0D0008\n:1000100000000000000000000000000000000000", kind: KindNotCLI}, // Intel hex
+		{name: "headless dump", text: strings.Join(fullDumpLines()[1:], "\n"), kind: KindDump},                                             // Settings count without the header
+	} // End of cases
+	for _, test := range tests { // Run every case
+		if result := classifyText(t, test.text); result.Kind != test.kind { // Wrong kind
+			t.Errorf("%s: kind = %s, want %s", test.name, result.Kind, test.kind) // Report it
+		}
+	}
+} // End of TestClassifyKinds function
+
+// Checks each problem code on a broken variant of the complete dump, which is the negative case of every code
+func TestClassifyProblems(t *testing.T) { // Test of the problem codes
+	dump := strings.Join(fullDumpLines(), "\n") // Complete dump
+	tests := []struct {
+		name string // Case name
+		text string // File content
+		code string // Expected problem
+		line int    // Line of the problem
+	}{
+		{name: "missing header", text: strings.TrimPrefix(dump, header44+"\n"), code: ProblemMissingHeader, line: 1},                                                  // Header not copied
+		{name: "missing save", text: strings.TrimSuffix(dump, "\nsave"), code: ProblemMissingSave, line: fullDumpSettings + 8},                                        // Ends at batch end
+		{name: "missing batch end", text: strings.Replace(dump, "batch end\n", "", 1), code: ProblemMissingBatchEnd, line: 2},                                         // Batch never ends
+		{name: "missing profiles", text: strings.Replace(dump, "profile 0\nset p_pitch = 47\n", "", 1), code: ProblemMissingProfiles, line: fullDumpSettings + 7},     // No profile section
+		{name: "missing rates", text: strings.Replace(dump, "rateprofile 0\nset roll_rc_rate = 100\n", "", 1), code: ProblemMissingRates, line: fullDumpSettings + 7}, // No rate profile section
+		{name: "commands after end", text: dump + "\nset motor_pwm_rate = 480", code: ProblemCommandsAfterEnd, line: fullDumpSettings + 10},                           // After save
+		{name: "unparsable lines", text: strings.Replace(dump, "profile 0", "profile zero", 1), code: ProblemUnparsableLines, line: fullDumpSettings + 4},             // Not a number
+		{name: "bom", text: "\ufeff" + dump, code: ProblemBOM, line: 1},                                                                                               // Byte order mark
+		{name: "crlf", text: strings.ReplaceAll(dump, "\n", "\r\n"), code: ProblemCRLF, line: 0},                                                                      // Windows line endings
+		{name: "invalid utf8", text: strings.Replace(dump, "p_pitch = 47", "p_pitch = 47 \xff", 1), code: ProblemInvalidUTF8, line: 0},                                // Broken byte in a setting value
+	} // End of cases
+	for _, test := range tests { // Run every case
+		t.Run(test.name, func(t *testing.T) { // One subtest per case
+			result := classifyText(t, test.text)                                         // Classify the variant
+			if count := problemCount(result, test.code); count != 1 || result.Complete { // Exactly this problem
+				t.Fatalf("problems = %+v", result.Problems) // Stop the test
+			}
+			for _, problem := range result.Problems { // Find the line
+				if problem.Code == test.code && problem.Line != test.line { // Wrong line
+					t.Errorf("line = %d, want %d", problem.Line, test.line) // Report it
+				}
+			}
+		}) // End of subtest
+	}
+} // End of TestClassifyProblems function
@@ -0,0 +1,87 @@
+package betaflight
+
+import (
+	"fmt"  // Formats finding messages
+	"sort" // Orders feature names
+)
+
+// A feature name the CLI accepts and the releases that have it
+type featureName struct {
+	Name         string // Name printed by feature
+	Major        int    // First major version with the name
+	Minor        int    // First minor version with the name
+	RemovedMajor int    // First major version without the name (0 = still present)
+	RemovedMinor int    // First minor version without the name
+} // End of featureName struct
+
+// Feature names (featureNames[] in src/main/cli/cli.c); ANTI_GRAVITY is still accepted after it became always on
+var featureNames = []featureName{
+	{Name: "RX_PPM", Major: 4},                                           // PPM receiver
+	{Name: "INFLIGHT_ACC_CAL", Major: 4},                                 // In-flight accelerometer calibration
+	{Name: "RX_SERIAL", Major: 4},                                        // Serial receiver
+	{Name: "MOTOR_STOP", Major: 4},                                       // Motors stopped at zero throttle
+	{Name: "SERVO_TILT", Major: 4},                                       // Camera tilt servo
+	{Name: "SOFTSERIAL", Major: 4},                                       // Soft serial ports
+	{Name: "GPS", Major: 4},                                              // GPS
+	{Name: "RANGEFINDER", Major: 4},                                      // Rangefinder
+	{Name: "TELEMETRY", Major: 4},                                        // Telemetry
+	{Name: "3D", Major: 4},                                               // Reversible motors
+	{Name: "RX_PARALLEL_PWM", Major: 4},                                  // PWM receiver
+	{Name: "RX_MSP", Major: 4},                                           // MSP receiver
+	{Name: "RSSI_ADC", Major: 4},                                         // Analog RSSI
+	{Name: "LED_STRIP", Major: 4},                                        // LED strip
+	{Name: "DISPLAY", Major: 4},                                          // OLED dashboard
+	{Name: "OSD", Major: 4},                                              // On-screen display
+	{Name: "CHANNEL_FORWARDING", Major: 4},                               // AUX channels forwarded to servos
+	{Name: "TRANSPONDER", Major: 4},                                      // Race transponder
+	{Name: "AIRMODE", Major: 4},                                          // Air mode always on
+	{Name: "RX_SPI", Major: 4},                                           // SPI receiver
+	{Name: "SOFTSPI", Major: 4, RemovedMajor: 4, RemovedMinor: 2},        // Software SPI
+	{Name: "ESC_SENSOR", Major: 4},                                       // ESC telemetry
+	{Name: "ANTI_GRAVITY", Major: 4},                                     // Anti gravity
+	{Name: "DYNAMIC_FILTER", Major: 4, RemovedMajor: 4, RemovedMinor: 3}, // Dynamic notch (always built in since 4.3)
+} // End of featureNames
+
+// Applies the feature lines in order and returns the effective state of every feature the dump names; features
+// without a line are absent
+func (dump *Dump) FeatureStates() map[string]bool { // Method to compute effective features
+	features := map[string]bool{}          // Effective states
+	for _, toggle := range dump.Features { // Apply every line
+		features[toggle.Name] = toggle.Enabled // Later lines win
+	}
+	return features // Return the states
+} // End of FeatureStates method
+
+// Returns the features that are on once every feature line has been applied, sorted by name
+func (dump *Dump) EnabledFeatures() []string { // Method to list effective features
+	enabled := []string{}                        // Enabled features
+	for name, on := range dump.FeatureStates() { // Check every feature
+		if on { // Enabled
+			enabled = append(enabled, name) // Record it
+		}
+	}
+	sort.Strings(enabled) // Stable order
+	return enabled        // Return them
+} // End of EnabledFeatures method
+
+// Reports whether the firmware release accepts a feature name (nil build = any release)
+func FeatureDefined(build *Build, name string) bool { // Function to validate a feature name
+	for _, feature := range featureNames { // Find the name
+		if feature.Name == name { // Found it
+			return build.Defines(feature.Major, feature.Minor, feature.RemovedMajor, feature.RemovedMinor) // Present in this release
+		}
+	}
+	return false // Never defined
+} // End of FeatureDefined function
+
+// Flags feature lines naming features the firmware release does not have; the CLI rejects them
+func CheckFeatures(dump *Dump) []Finding { // Function to validate the feature lines
+	var findings []Finding                 // Results
+	for _, toggle := range dump.Features { // Check every line
+		if FeatureDefined(dump.Build, toggle.Name) { // Known name
+			continue // Next line
+		}
+		findings = append(findings, Finding{Severity: SeverityWarning, Code: "unknown_feature", Message: fmt.Sprintf("feature %s is not defined by this firmware release", toggle.Name), Lines: []int{toggle.Line}}) // Record it
+	}
+	return findings // Return the findings
+} // End of CheckFeatures function
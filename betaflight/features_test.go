@@ -0,0 +1,37 @@
+package betaflight
+
+import (
+	"slices"  // Compares feature lists
+	"testing" // Test framework
+)
+
+// Checks that later feature lines win and only enabled features are listed, sorted by name
+func TestEnabledFeatures(t *testing.T) { // Test of the effective feature set
+	dump := mustParse(t, "feature -TELEMETRY", "feature OSD", "feature LED_STRIP", "feature TELEMETRY", "feature -LED_STRIP") // A dump clears, then sets
+	if got, want := dump.EnabledFeatures(), []string{"OSD", "TELEMETRY"}; !slices.Equal(got, want) {                          // LED_STRIP was cleared last
+		t.Errorf("EnabledFeatures() = %v, want %v", got, want) // Report it
+	}
+	if states := dump.FeatureStates(); states["LED_STRIP"] || !states["TELEMETRY"] || len(states) != 3 { // Every named feature has a state
+		t.Errorf("FeatureStates() = %v", states) // Report it
+	}
+} // End of TestEnabledFeatures function
+
+// Checks that unknown_feature follows the release that added or removed the name
+func TestCheckFeatures(t *testing.T) { // Test of unknown_feature
+	tests := []struct {
+		header string // Firmware header ("" for a snippet)
+		line   string // feature line
+		count  int    // Expected findings
+	}{
+		{header: "# Betaflight / STM32F405 (S405) 4.2.11 Nov  9 2021 / 20:29:32 (948ba6339) MSP API: 1.43", line: "feature DYNAMIC_FILTER", count: 0}, // Still accepted in 4.2
+		{header: header44, line: "feature -DYNAMIC_FILTER", count: 1},                                                                                 // Removed in 4.3
+		{header: header44, line: "feature SOFTSPI", count: 1},                                                                                         // Removed in 4.2
+		{header: header44, line: "feature OSD", count: 0},                                                                                             // Always defined
+		{header: header44, line: "feature WARP_DRIVE", count: 1},                                                                                      // Never defined
+		{header: "", line: "feature DYNAMIC_FILTER", count: 0},                                                                                        // Unknown release accepts any known name
+	} // End of cases
+	for _, test := range tests { // Run every case
+		dump := mustParse(t, test.header, test.line)                      // Parse the case
+		expectCode(t, CheckFeatures(dump), "unknown_feature", test.count) // Count the findings
+	}
+} // End of TestCheckFeatures function
@@ -0,0 +1,82 @@
+package betaflight
+
+import (
+	"bytes"         // Compares reports
+	"encoding/json" // Encodes reports
+	"flag"          // Reads the -update flag
+	"os"            // Reads and writes golden files
+	"path/filepath" // Builds golden paths
+	"strings"       // Trims file extensions
+	"testing"       // Test framework
+)
+
+// Rewrites the golden files instead of comparing against them (go test ./betaflight -update)
+var updateGolden = flag.Bool("update", false, "rewrite testdata/golden from testdata/dumps")
+
+// What the package makes of one dump; the golden files hold it as indented JSON
+type goldenReport struct {
+	Build           *Build          `json:"build"`            // Firmware header
+	BoardName       string          `json:"board_name"`       // board_name
+	Classification  *Classification `json:"classification"`   // Kind and completeness
+	UARTs           []UART          `json:"uarts"`            // Serial ports
+	Switches        []Switch        `json:"switches"`         // Mode ranges
+	EnabledFeatures []string        `json:"enabled_features"` // Effective features
+	LEDs            []LayoutLED     `json:"leds"`             // LED strip layout
+	Findings        []Finding       `json:"findings"`         // Findings of every check
+	Errors          []string        `json:"errors"`           // Rejected lines and LED definitions
+} // End of goldenReport struct
+
+// Builds the golden report of a dump file
+func goldenReportFor(t *testing.T, path string) []byte { // Helper to run everything on one dump
+	t.Helper()                   // Report the caller's line
+	dump, err := ParseFile(path) // Parse it; rejected lines are part of the report
+	if dump == nil {             // Nothing came back
+		t.Fatalf("ParseFile(%s) = nil, %v", path, err) // Stop the test
+	}
+	report := goldenReport{Build: dump.Build, BoardName: dump.BoardName, Classification: Classify(dump, err), UARTs: dump.UARTs(), Switches: dump.Switches(), EnabledFeatures: dump.EnabledFeatures(), Findings: []Finding{}, Errors: []string{}} // Decoded views
+	report.Findings = append(report.Findings, CheckPins(dump)...)                                                                                                                                                                                 // Pin conflicts
+	report.Findings = append(report.Findings, CheckTimers(dump)...)                                                                                                                                                                               // Timer and DMA problems
+	report.Findings = append(report.Findings, CheckFeatures(dump)...)                                                                                                                                                                             // Unknown features
+	report.Findings = append(report.Findings, CheckModes(dump)...)                                                                                                                                                                                // Mode problems
+	report.Findings = append(report.Findings, CheckVTXTable(dump)...)                                                                                                                                                                             // vtxtable problems
+	var layoutErr error                                                                                                                                                                                                                           // Malformed LED definitions
+	report.LEDs, layoutErr = dump.LEDLayout()                                                                                                                                                                                                     // LED strip
+	for _, err := range []error{err, layoutErr} {                                                                                                                                                                                                 // Record both error lists
+		if err != nil { // Something was rejected
+			report.Errors = append(report.Errors, strings.Split(err.Error(), "\n")...) // One entry per line
+		}
+	}
+	encoded, err := json.MarshalIndent(report, "", "  ") // Encode the report
+	if err != nil {                                      // Should never happen
+		t.Fatalf("encode %s: %v", path, err) // Stop the test
+	}
+	return append(encoded, '\n') // End with a newline
+} // End of goldenReportFor function
+
+// Checks every dump in testdata/dumps, one per Betaflight release in the corpus, against its golden report
+func TestGoldenDumps(t *testing.T) { // Test of whole dumps
+	paths, err := filepath.Glob(filepath.Join("testdata", "dumps", "*.txt")) // Every golden dump
+	if err != nil || len(paths) == 0 {                                       // Missing test data
+		t.Fatalf("no golden dumps: %v", err) // Stop the test
+	}
+	for _, path := range paths { // Check every dump
+		name := strings.TrimSuffix(filepath.Base(path), ".txt") // Dump name
+		t.Run(name, func(t *testing.T) {                        // One subtest per dump
+			got := goldenReportFor(t, path)                                 // Current report
+			goldenPath := filepath.Join("testdata", "golden", name+".json") // Expected report
+			if *updateGolden {                                              // Rewrite it
+				if err := os.WriteFile(goldenPath, got, 0o644); err != nil { // Write failed
+					t.Fatalf("write %s: %v", goldenPath, err) // Stop the test
+				}
+				return // Nothing to compare
+			}
+			want, err := os.ReadFile(goldenPath) // Read the expected report
+			if err != nil {                      // Not generated yet
+				t.Fatalf("read %s: %v (run go test -update)", goldenPath, err) // Stop the test
+			}
+			if !bytes.Equal(got, want) { // The report changed
+				t.Errorf("%s differs from %s; run go test -update and review the diff", path, goldenPath) // Report it
+			}
+		}) // End of subtest
+	}
+} // End of TestGoldenDumps function
@@ -0,0 +1,251 @@
+package betaflight
+
+import (
+	"fmt"     // Formats colors and drawings
+	"math"    // Converts HSV to RGB
+	"strconv" // Parses LED definitions
+	"strings" // Splits LED definitions and builds drawings
+)
+
+// Base functions by code (ledBaseFunctionCodes in src/main/io/ledstrip.c)
+var ledFunctions = map[byte]string{'C': "COLOR", 'F': "FLIGHT_MODE", 'A': "ARM_STATE", 'L': "BATTERY", 'S': "RSSI", 'G': "GPS", 'R': "THRUST_RING"} // Code → function
+
+// Overlays by code (ledOverlayCodes; Y was added in 4.4)
+var ledOverlays = map[byte]string{'T': "THROTTLE", 'Y': "RAINBOW", 'O': "LARSON_SCANNER", 'B': "BLINK", 'V': "VTX", 'I': "INDICATOR", 'W': "WARNING"} // Code → overlay
+
+const ledDirections = "NESWUD" // Direction codes, in mode_color function order
+
+// Default palette (hsv[] in src/main/common/color.c); diffs only list the colors they change
+var defaultLEDColors = [][3]int{{0, 0, 0}, {0, 255, 255}, {0, 0, 255}, {30, 0, 255}, {60, 0, 255}, {90, 0, 255}, {120, 0, 255}, {150, 0, 255}, {180, 0, 255}, {210, 0, 255}, {240, 0, 255}, {270, 0, 255}, {300, 0, 255}, {330, 0, 255}, {0, 0, 0}, {0, 0, 0}} // Index → hue, saturation, value
+
+// mode_color modes used for the effective color
+const (
+	ledModeOrientation   = 0 // Directional colors while no flight mode is active
+	ledModeSpecial       = 6 // Special colors
+	specialColorDisarmed = 0 // Special color of ARM_STATE LEDs while disarmed
+) // End of mode_color constants
+
+const ledGridSize = 16 // LEDs are placed on a 16×16 grid
+
+// A decoded led line
+type LayoutLED struct {
+	Index        int      `json:"index"`         // LED number (position on the strip)
+	X            int      `json:"x"`             // Column on the grid (0-15)
+	Y            int      `json:"y"`             // Row on the grid (0-15)
+	Directions   string   `json:"directions"`    // Direction codes (N, E, S, W, U, D)
+	Function     string   `json:"function"`      // Base function code (C, F, A, L, S, G, R)
+	FunctionName string   `json:"function_name"` // Base function name (e.g. "ARM_STATE")
+	Overlays     []string `json:"overlays"`      // Overlay names (e.g. "INDICATOR")
+	ColorIndex   int      `json:"color_index"`   // Palette color set on the LED
+	Color        string   `json:"color"`         // Effective color while disarmed, as "#rrggbb"
+	Line         int      `json:"line"`          // Line number
+} // End of LayoutLED struct
+
+// Decodes the led lines into a layout with effective colors; unused slots ("0,0::C:0") are left out and malformed
+// definitions are returned as an ErrorList
+func (dump *Dump) LEDLayout() ([]LayoutLED, error) { // Method to decode the LED strip
+	var layout []LayoutLED          // Used LEDs
+	var lineErrors ErrorList        // Malformed definitions
+	for _, led := range dump.LEDs { // Decode every line
+		decoded, err := decodeLED(led.Definition) // Split the definition
+		if err != nil {                           // Malformed
+			lineErrors = append(lineErrors, &LineError{Line: led.Line, Text: led.Definition, Message: err.Error()}) // Report it
+			continue                                                                                                // Next LED
+		}
+		if decoded.X == 0 && decoded.Y == 0 && decoded.Directions == "" && decoded.Function == "C" && len(decoded.Overlays) == 0 && decoded.ColorIndex == 0 { // Empty slot
+			continue // Not on the strip
+		}
+		decoded.Index = led.Index                       // LED number
+		decoded.Line = led.Line                         // Line number
+		decoded.Color = dump.effectiveLEDColor(decoded) // Color while disarmed
+		layout = append(layout, decoded)                // Record it
+	}
+	if len(lineErrors) > 0 { // Some definitions were malformed
+		return layout, lineErrors // Return what could be decoded
+	}
+	return layout, nil // Clean decode
+} // End of LEDLayout method
+
+// Splits "X,Y:DIRECTIONS:FUNCTION+OVERLAYS:COLOR"; the older "X,Y:DIRECTIONS:FUNCTIONS:OVERLAYS:COLOR" form is accepted too
+func decodeLED(definition string) (LayoutLED, error) { // Function to decode one LED
+	var led LayoutLED                         // Result
+	fields := strings.Split(definition, ":")  // Definition fields
+	if len(fields) != 4 && len(fields) != 5 { // Neither form
+		return led, fmt.Errorf("LED definition needs 4 or 5 colon-separated fields") // Report it
+	}
+	x, y, found := strings.Cut(fields[0], ",") // Position
+	var errX, errY error                       // Position parse errors
+	led.X, errX = strconv.Atoi(x)              // Column
+	led.Y, errY = strconv.Atoi(y)              // Row
+
+	if !found || errX != nil || errY != nil || led.X < 0 || led.Y < 0 || led.X >= ledGridSize || led.Y >= ledGridSize { // Off the grid
+		return led, fmt.Errorf("invalid LED position %q", fields[0]) // Report it
+	}
+	for _, code := range []byte(fields[1]) { // Check every direction
+		if !strings.ContainsRune(ledDirections, rune(code)) { // Unknown direction
+			return led, fmt.Errorf("unknown LED direction %q", code) // Report it
+		}
+	}
+	led.Directions = fields[1] // Directions
+
+	led.Overlays = []string{}                          // Overlay names
+	codes := strings.Join(fields[2:len(fields)-1], "") // Function and overlay codes
+	for _, code := range []byte(codes) {               // Sort every code
+		if function, known := ledFunctions[code]; known && led.Function == "" { // Base function
+			led.Function, led.FunctionName = string(code), function // Record it
+		} else if overlay, known := ledOverlays[code]; known { // Overlay
+			led.Overlays = append(led.Overlays, overlay) // Record it
+		} else { // Unknown or second base function
+			return led, fmt.Errorf("unexpected LED function %q", code) // Report it
+		}
+	}
+	if led.Function == "" { // No base function
+		led.Function, led.FunctionName = "C", ledFunctions['C'] // The firmware defaults to COLOR
+	}
+	color, err := strconv.Atoi(fields[len(fields)-1])              // Palette color
+	if err != nil || color < 0 || color >= len(defaultLEDColors) { // Not a palette index
+		return led, fmt.Errorf("invalid LED color %q", fields[len(fields)-1]) // Report it
+	}
+	led.ColorIndex = color // Record it
+	return led, nil        // Return the LED
+} // End of decodeLED function
+
+// Picks the color an LED shows while disarmed: ARM_STATE uses the disarmed special color, FLIGHT_MODE the orientation
+// color of its first direction, everything else its own palette color
+func (dump *Dump) effectiveLEDColor(led LayoutLED) string { // Method to resolve an LED color
+	color := led.ColorIndex // Own palette color
+	switch led.Function {   // Functions driven by mode_color
+	case "A": // Arm state
+		color = dump.modeColor(ledModeSpecial, specialColorDisarmed, color) // Disarmed color
+	case "F": // Flight mode
+		if led.Directions != "" { // Directional LED
+			color = dump.modeColor(ledModeOrientation, strings.IndexByte(ledDirections, led.Directions[0]), color) // Orientation color
+		}
+	}
+	return dump.paletteColor(color) // Convert it
+} // End of effectiveLEDColor method
+
+// Returns the palette index of a mode_color entry, or fallback when the dump has none
+func (dump *Dump) modeColor(mode, function, fallback int) int { // Method to look up a mode color
+	color := fallback                       // Default
+	for _, entry := range dump.ModeColors { // Find the entry
+		if entry.Mode == mode && entry.Function == function { // Found it
+			color = entry.Color // Later lines win
+		}
+	}
+	return color // Return the index
+} // End of modeColor method
+
+// Converts a palette entry to "#rrggbb", using the dump's color lines over the default palette
+func (dump *Dump) paletteColor(index int) string { // Method to resolve a palette color
+	hsv := [3]int{}                                  // Hue, saturation, value
+	if index >= 0 && index < len(defaultLEDColors) { // Known index
+		hsv = defaultLEDColors[index] // Default color
+	}
+	for _, color := range dump.Colors { // Apply the color lines
+		if color.Index == index { // Same entry
+			hsv = [3]int{color.Hue, color.Saturation, color.Value} // Later lines win
+		}
+	}
+	return hsvToHex(hsv[0], hsv[1], hsv[2]) // Convert it
+} // End of paletteColor method
+
+// Converts a firmware HSV color (hue 0-359; saturation 0-255 where 0 is fully saturated and 255 is white; value 0-255)
+// to "#rrggbb"
+func hsvToHex(hue, saturation, value int) string { // Function to convert a palette color
+	h := math.Mod(float64(hue), 360) / 60     // Hue sector
+	s := float64(255-saturation) / 255        // Saturation, inverted like the firmware
+	v := float64(value) / 255                 // Brightness
+	c := v * s                                // Chroma
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1)) // Second component
+	var r, g, b float64                       // Components before the brightness offset
+	switch int(h) {                           // Pick the sector
+	case 0: // Red to yellow
+		r, g = c, x // Record it
+	case 1: // Yellow to green
+		r, g = x, c // Record it
+	case 2: // Green to cyan
+		g, b = c, x // Record it
+	case 3: // Cyan to blue
+		g, b = x, c // Record it
+	case 4: // Blue to magenta
+		r, b = x, c // Record it
+	default: // Magenta to red
+		r, b = c, x // Record it
+	}
+	m := v - c                                                                                                              // Brightness offset
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round((r+m)*255)), int(math.Round((g+m)*255)), int(math.Round((b+m)*255))) // Hex color
+} // End of hsvToHex function
+
+// Returns the first and last column and row the layout uses
+func layoutBounds(layout []LayoutLED) (int, int, int, int) { // Function to crop a drawing
+	left, top, right, bottom := ledGridSize, ledGridSize, 0, 0 // Start outside the grid
+	for _, led := range layout {                               // Grow to every LED
+		left, top = min(left, led.X), min(top, led.Y)         // Upper-left corner
+		right, bottom = max(right, led.X), max(bottom, led.Y) // Lower-right corner
+	}
+	return left, top, max(left, right), max(top, bottom) // Return the corners
+} // End of layoutBounds function
+
+// Draws the layout as a text grid, one cell per position showing the function code and palette color ("A6", "C2"; "*"
+// for several LEDs on one cell), followed by one legend line per LED
+func RenderLEDGrid(layout []LayoutLED) string { // Function to draw the layout as text
+	left, top, right, bottom := layoutBounds(layout) // Grid area in use
+	cells := make(map[[2]int][]LayoutLED)            // LEDs per position
+	for _, led := range layout {                     // Place every LED
+		cells[[2]int{led.X, led.Y}] = append(cells[[2]int{led.X, led.Y}], led) // Record it
+	}
+	var text strings.Builder         // Drawing
+	text.WriteString("   ")          // Corner
+	for x := left; x <= right; x++ { // Column numbers
+		fmt.Fprintf(&text, "%4d", x) // Right-aligned
+	}
+	text.WriteString("\n")           // End of the header
+	for y := top; y <= bottom; y++ { // Draw every row
+		fmt.Fprintf(&text, "%3d", y)     // Row number
+		for x := left; x <= right; x++ { // Draw every cell
+			leds := cells[[2]int{x, y}] // LEDs on the cell
+			switch len(leds) {          // Pick the symbol
+			case 0: // Empty
+				text.WriteString("   .") // Placeholder
+			case 1: // One LED
+				fmt.Fprintf(&text, "%4s", fmt.Sprintf("%s%d", leds[0].Function, leds[0].ColorIndex)) // Function and color
+			default: // Stacked LEDs
+				text.WriteString("   *") // Marker
+			}
+		}
+		text.WriteString("\n") // End of the row
+	}
+	for _, led := range layout { // Legend
+		overlays, directions := strings.Join(led.Overlays, ","), led.Directions // Optional fields
+		if overlays == "" {                                                     // No overlays
+			overlays = "-" // Placeholder
+		}
+		if directions == "" { // No directions
+			directions = "-" // Placeholder
+		}
+		fmt.Fprintf(&text, "#%d\t%d,%d\t%s\t%s\t%s\tcolor %d %s\n", led.Index, led.X, led.Y, led.FunctionName, overlays, directions, led.ColorIndex, led.Color) // One line per LED
+	}
+	return text.String() // Return the drawing
+} // End of RenderLEDGrid function
+
+// Draws the layout as an SVG image: one circle per LED filled with its effective color, labeled with its function code
+// and strip number, with the overlays and directions in a tooltip
+func RenderLEDSVG(layout []LayoutLED) string { // Function to draw the layout as SVG
+	const cell = 40                                           // Pixels per grid cell
+	left, top, right, bottom := layoutBounds(layout)          // Grid area in use
+	width, height := (right-left+1)*cell, (bottom-top+1)*cell // Canvas size
+	var svg strings.Builder                                   // Drawing
+
+	fmt.Fprintf(&svg, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height) // Canvas
+	fmt.Fprintf(&svg, "<rect width=\"%d\" height=\"%d\" fill=\"#202020\"/>\n", width, height)                                                        // Dark background
+
+	for _, led := range layout { // Draw every LED
+		cx, cy := (led.X-left)*cell+cell/2, (led.Y-top)*cell+cell/2                                                                                                                                                                  // Cell center
+		fmt.Fprintf(&svg, "<g><title>LED %d (%d,%d) %s %s %s color %d %s</title>\n", led.Index, led.X, led.Y, led.FunctionName, strings.Join(led.Overlays, ","), led.Directions, led.ColorIndex, led.Color)                          // Tooltip
+		fmt.Fprintf(&svg, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"%s\" stroke=\"#808080\"/>\n", cx, cy, cell*2/5, led.Color)                                                                                                    // LED
+		fmt.Fprintf(&svg, "<text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"12\" text-anchor=\"middle\" fill=\"#808080\" stroke=\"#000000\" stroke-width=\"0.3\">%s%d</text></g>\n", cx, cy+4, led.Function, led.Index) // Function and strip number
+	}
+	svg.WriteString("</svg>\n") // Close the canvas
+	return svg.String()         // Return the drawing
+} // End of RenderLEDSVG function
@@ -0,0 +1,47 @@
+package betaflight
+
+import (
+	"errors"  // Unwraps the error list
+	"slices"  // Compares overlay lists
+	"testing" // Test framework
+)
+
+// Checks that both definition forms decode, empty slots are skipped and colors follow the palette and mode colors
+func TestLEDLayout(t *testing.T) { // Test of the LED layout
+	dump := mustParse(t, "led 0 1,2:NE:FT:2", "led 1 0,0::C:0", "led 2 3,2::AI:5", "led 3 4,2:S:F:O:0", "color 5 240,0,255", "mode_color 6 0 6", "mode_color 0 2 6") // Current and older forms, an empty slot and color overrides
+	layout, err := dump.LEDLayout()                                                                                                                                  // Decode the strip
+	if err != nil || len(layout) != 3 {                                                                                                                              // The empty slot is left out
+		t.Fatalf("LEDLayout() = %+v, %v", layout, err) // Stop the test
+	}
+	first := layout[0]                                                                                                                                  // Flight mode LED facing north and east
+	if first.Index != 0 || first.X != 1 || first.Y != 2 || first.FunctionName != "FLIGHT_MODE" || !slices.Equal(first.Overlays, []string{"THROTTLE"}) { // Decoded fields
+		t.Errorf("led 0 = %+v", first) // Report it
+	}
+	if first.Color != "#ff0000" { // No orientation color for north, so its own color 2 (red; saturation 0 is full)
+		t.Errorf("led 0 color = %s, want #ff0000", first.Color) // Report it
+	}
+	if layout[1].Index != 2 || layout[1].Color != "#00ff00" { // ARM_STATE shows the disarmed special color 6, not its own blue color 5
+		t.Errorf("led 2 = %+v", layout[1]) // Report it
+	}
+	if layout[2].Color != "#00ff00" { // Older form, south orientation color 6
+		t.Errorf("led 3 = %+v", layout[2]) // Report it
+	}
+} // End of TestLEDLayout function
+
+// Checks that malformed definitions come back as line errors while the rest of the strip still decodes
+func TestLEDLayoutErrors(t *testing.T) { // Test of malformed LED definitions
+	dump := mustParse(t, "led 0 1,2::C:2", "led 1 16,0::C:2", "led 2 1,3:X:C:2", "led 3 1,4::CA:2", "led 4 1,5::C:16", "led 5 1,6:C:2") // One good LED, then each kind of mistake
+	layout, err := dump.LEDLayout()                                                                                                     // Decode the strip
+	var lineErrors ErrorList                                                                                                            // Rejected definitions
+	if !errors.As(err, &lineErrors) || len(lineErrors) != 5 {                                                                           // Every mistake is reported
+		t.Fatalf("LEDLayout() error = %v", err) // Stop the test
+	}
+	for index, line := range []int{2, 3, 4, 5, 6} { // One error per bad line
+		if lineErrors[index].Line != line { // Wrong line
+			t.Errorf("error %d on line %d, want %d", index, lineErrors[index].Line, line) // Report it
+		}
+	}
+	if len(layout) != 1 || layout[0].Color != "#ff0000" { // The good LED survives
+		t.Errorf("layout = %+v", layout) // Report it
+	}
+} // End of TestLEDLayoutErrors function
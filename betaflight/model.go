@@ -0,0 +1,212 @@
+// Package betaflight parses Betaflight CLI dumps (the output of `dump`, `diff all` and hand-written snippets)
+// into a typed model. Every parsed value keeps the line number it came from.
+package betaflight
+
+// A parsed CLI dump
+type Dump struct {
+	VersionLine    string       // Firmware header without the leading "# " (e.g. "Betaflight / STM32F7X2 (S7X2) 4.5.1 ...")
+	VersionLineNo  int          // Line of the firmware header (0 when absent)
+	Build          *Build       // Metadata decoded from the firmware header (nil when absent)
+	ConfigRev      string       // From the "# config rev: ..." comment (config-generating commit)
+	BoardName      string       // board_name
+	ManufacturerID string       // manufacturer_id
+	CraftName      string       // From the "# name: ..." comment ("-" means unset)
+	Resources      []Resource   // resource lines
+	Timers         []Timer      // timer lines with their pin comments
+	DMA            []DMA        // dma lines with their stream comments
+	Features       []Toggle     // feature lines, in order ("feature -X" clears, "feature X" sets)
+	Serial         []SerialPort // serial lines
+	Beepers        []Toggle     // beeper lines
+	Beacons        []Toggle     // beacon lines
+	Map            string       // Channel map (e.g. "AETR1234")
+	Mixer          string       // Mixer type (e.g. "QUADX")
+	LEDs           []LED        // led lines
+	Colors         []Color      // color lines
+	ModeColors     []ModeColor  // mode_color lines
+	Aux            []AuxRange   // aux lines
+	AdjRanges      []AdjRange   // adjrange lines
+	RxRanges       []RxRange    // rxrange lines
+	VTX            []VTXChannel // vtx lines
+	VTXTable       *VTXTable    // vtxtable lines (nil when absent)
+	Master         []Setting    // set lines outside any profile
+	Profiles       []*Profile   // set lines after "profile N"
+	RateProfiles   []*Profile   // set lines after "rateprofile N"
+	Commands       []Command    // Every command line in order, including those without a typed form (mixer, servo, rxfail, ...)
+	Ignored        []Command    // Command lines after save or batch end; the CLI never applies them, so they build no typed form
+	Encoding       Encoding     // Line endings and byte order mark of the file
+} // End of Dump struct
+
+// How the dump file was encoded; the CLI accepts CRLF and a BOM, but they mark files that went through an editor
+type Encoding struct {
+	Lines            int  `json:"lines"`              // Lines in the file
+	BOM              bool `json:"bom"`                // UTF-8 byte order mark before the first line
+	CRLFLines        int  `json:"crlf_lines"`         // Lines ending in CR LF
+	InvalidUTF8Lines int  `json:"invalid_utf8_lines"` // Lines that are not valid UTF-8
+	FinalNewline     bool `json:"final_newline"`      // Whether the last line ends with a newline
+} // End of Encoding struct
+
+// One command line as written
+type Command struct {
+	Name string   // Lowercase command name
+	Args []string // Whitespace-separated arguments
+	Line int      // Line number (1-based)
+} // End of Command struct
+
+// resource FUNCTION INDEX PIN
+type Resource struct {
+	Function string // Peripheral function (e.g. "MOTOR", "SERIAL_TX")
+	Index    int    // 1-based instance number
+	Pin      string // Pin such as "B04"; empty for NONE
+	Line     int    // Line number
+} // End of Resource struct
+
+// timer PIN AFn, with the "# pin B04: TIM3 CH1 (AF2)" comment that follows it
+type Timer struct {
+	Pin       string // Pin such as "B04"
+	Alternate string // Alternate function (e.g. "AF2"); empty for NONE
+	Timer     string // Timer from the comment (e.g. "TIM3")
+	Channel   string // Channel from the comment (e.g. "CH1", "CH2N")
+	Line      int    // Line number
+} // End of Timer struct
+
+// dma PERIPHERAL INDEX OPTION or dma pin PIN OPTION, with the stream comment that follows it
+type DMA struct {
+	Peripheral string     // "pin", "ADC", "SPI_TX", "UART_RX", "TIMUP", ...
+	Index      int        // Peripheral instance (0 for pins)
+	Pin        string     // Pin for "dma pin" lines
+	Option     int        // DMA option index; -1 for NONE
+	Stream     *DMAStream // Stream from the comment, if any
+	Line       int        // Line number
+} // End of DMA struct
+
+// A DMA stream as printed in dump comments: "DMA1 Stream 4 Channel 5" (F4/F7), "DMA1 Stream 4 Request 5" (H7)
+// or "DMA1 Channel 3 Request 1" (G4, where the DMAMUX channel plays the role of the stream)
+type DMAStream struct {
+	Controller int // DMA controller (1 or 2)
+	Stream     int // Stream (or G4 channel) number
+	Channel    int // F4/F7 channel selection; -1 when not printed
+	Request    int // H7/G4 DMAMUX request; -1 when not printed
+} // End of DMAStream struct
+
+// feature, beeper or beacon line: "NAME" enables, "-NAME" disables
+type Toggle struct {
+	Name    string // Uppercase name
+	Enabled bool   // false for "-NAME"
+	Line    int    // Line number
+} // End of Toggle struct
+
+// serial IDENTIFIER FUNCTIONS MSP_BAUD GPS_BAUD TELEMETRY_BAUD BLACKBOX_BAUD
+type SerialPort struct {
+	Identifier    int    // Port identifier (0 = UART1, 20 = USB VCP, 30 = SOFTSERIAL1, ...)
+	Functions     uint32 // Function bitmask
+	MSPBaud       int    // MSP baud rate
+	GPSBaud       int    // GPS baud rate
+	TelemetryBaud int    // Telemetry baud rate (0 = auto)
+	BlackboxBaud  int    // Blackbox baud rate
+	Line          int    // Line number
+} // End of SerialPort struct
+
+// led INDEX DEFINITION (e.g. "0,0::C:0"); decoded separately
+type LED struct {
+	Index      int    // LED number
+	Definition string // Position, directions, functions, overlays and color
+	Line       int    // Line number
+} // End of LED struct
+
+// color INDEX HUE,SATURATION,VALUE
+type Color struct {
+	Index      int // Palette slot
+	Hue        int // 0–359
+	Saturation int // 0–255
+	Value      int // 0–255
+	Line       int // Line number
+} // End of Color struct
+
+// mode_color MODE FUNCTION COLOR
+type ModeColor struct {
+	Mode     int // LED mode (orientation, headfree, ..., special, channel)
+	Function int // Direction or function within the mode
+	Color    int // Palette slot
+	Line     int // Line number
+} // End of ModeColor struct
+
+// aux INDEX MODE CHANNEL LOW HIGH LOGIC LINKED
+type AuxRange struct {
+	Index   int // Slot number
+	ModeID  int // Flight mode ID
+	Channel int // AUX channel, 0 = AUX1
+	Low     int // Range start (µs)
+	High    int // Range end (µs)
+	Logic   int // 0 = OR, 1 = AND
+	Linked  int // Mode ID this slot follows (0 = none)
+	Line    int // Line number
+} // End of AuxRange struct
+
+// adjrange INDEX SLOT CHANNEL LOW HIGH FUNCTION SWITCH CENTER SCALE
+type AdjRange struct {
+	Index         int // Slot number
+	Slot          int // Adjustment slot (unused since 4.1)
+	Channel       int // AUX channel enabling the adjustment
+	Low           int // Range start (µs)
+	High          int // Range end (µs)
+	Function      int // Adjustment function
+	SwitchChannel int // AUX channel driving the adjustment
+	Center        int // Center value for absolute adjustments
+	Scale         int // Scale for absolute adjustments
+	Line          int // Line number
+} // End of AdjRange struct
+
+// rxrange CHANNEL MIN MAX
+type RxRange struct {
+	Channel int // Stick channel (0 = roll)
+	Min     int // Lowest pulse (µs)
+	Max     int // Highest pulse (µs)
+	Line    int // Line number
+} // End of RxRange struct
+
+// vtx INDEX CHANNEL BAND VTX_CHANNEL POWER LOW HIGH
+type VTXChannel struct {
+	Index      int // Slot number
+	AuxChannel int // AUX channel selecting the slot
+	Band       int // Band (0 = unchanged)
+	Channel    int // Channel within the band (0 = unchanged)
+	Power      int // Power level (0 = unchanged)
+	Low        int // Range start (µs)
+	High       int // Range end (µs)
+	Line       int // Line number
+} // End of VTXChannel struct
+
+// The vtxtable block: bands with their frequencies, then power levels
+type VTXTable struct {
+	Bands       int       // Declared band count ("vtxtable bands")
+	Channels    int       // Declared channels per band ("vtxtable channels")
+	BandList    []VTXBand // "vtxtable band" lines
+	PowerLevels int       // Declared power level count
+	PowerValues []int     // Values sent to the VTX
+	PowerLabels []string  // Labels shown in the OSD
+	Line        int       // Line of the first vtxtable command
+} // End of VTXTable struct
+
+// vtxtable band NUMBER NAME LETTER FACTORY|CUSTOM FREQUENCIES...
+type VTXBand struct {
+	Number      int    // 1-based band number
+	Name        string // Band name (e.g. "BOSCAM_A")
+	Letter      string // Band letter (e.g. "A")
+	Factory     bool   // FACTORY bands use the VTX's own frequency table
+	Frequencies []int  // MHz per channel (0 = unused)
+	Line        int    // Line number
+} // End of VTXBand struct
+
+// set NAME = VALUE
+type Setting struct {
+	Name  string // Variable name
+	Value string // Value as written
+	Line  int    // Line number
+} // End of Setting struct
+
+// The settings of one PID or rate profile
+type Profile struct {
+	Index    int       // Profile number
+	Line     int       // Line of the "profile N" or "rateprofile N" command
+	Settings []Setting // set lines in the profile
+} // End of Profile struct
@@ -0,0 +1,172 @@
+package betaflight
+
+import (
+	"fmt"     // Formats finding messages
+	"sort"    // Orders the switch table
+	"strings" // Joins mode names
+)
+
+// A flight mode permanent ID and the releases that have it
+type flightMode struct {
+	ID           int    // Permanent ID printed by aux
+	Name         string // Name shown by the configurator
+	Major        int    // First major version with the mode
+	Minor        int    // First minor version with the mode
+	RemovedMajor int    // First major version without the mode (0 = still present)
+	RemovedMinor int    // First minor version without the mode
+} // End of flightMode struct
+
+// Flight modes by permanent ID (boxes[] in src/main/msp/msp_box.c); IDs of modes removed before 4.0 are never reused
+var flightModes = []flightMode{
+	{ID: 0, Name: "ARM", Major: 4},                                            // Arming
+	{ID: 1, Name: "ANGLE", Major: 4},                                          // Self-level
+	{ID: 2, Name: "HORIZON", Major: 4},                                        // Self-level near center
+	{ID: 4, Name: "ANTI GRAVITY", Major: 4, RemovedMajor: 4, RemovedMinor: 3}, // Always on since 4.3
+	{ID: 5, Name: "MAG", Major: 4},                                            // Heading hold
+	{ID: 6, Name: "HEADFREE", Major: 4},                                       // Headfree
+	{ID: 7, Name: "HEADADJ", Major: 4},                                        // Headfree reference
+	{ID: 8, Name: "CAMSTAB", Major: 4},                                        // Camera stabilization
+	{ID: 12, Name: "PASSTHRU", Major: 4},                                      // Fixed-wing passthrough
+	{ID: 13, Name: "BEEPER", Major: 4},                                        // Lost-model beeper
+	{ID: 15, Name: "LEDLOW", Major: 4},                                        // LED strip off
+	{ID: 17, Name: "CALIB", Major: 4},                                         // Accelerometer calibration
+	{ID: 19, Name: "OSD DISABLE", Major: 4},                                   // OSD off
+	{ID: 20, Name: "TELEMETRY", Major: 4},                                     // Telemetry on
+	{ID: 23, Name: "SERVO1", Major: 4},                                        // Servo 1
+	{ID: 24, Name: "SERVO2", Major: 4},                                        // Servo 2
+	{ID: 25, Name: "SERVO3", Major: 4},                                        // Servo 3
+	{ID: 26, Name: "BLACKBOX", Major: 4},                                      // Blackbox logging
+	{ID: 27, Name: "FAILSAFE", Major: 4},                                      // Failsafe
+	{ID: 28, Name: "AIR MODE", Major: 4},                                      // Air mode
+	{ID: 29, Name: "3D DISABLE / SWITCH", Major: 4},                           // 3D mode
+	{ID: 30, Name: "FPV ANGLE MIX", Major: 4},                                 // Camera angle compensation
+	{ID: 31, Name: "BLACKBOX ERASE (>30s)", Major: 4},                         // Flash erase
+	{ID: 32, Name: "CAMERA CONTROL 1", Major: 4},                              // Camera key 1
+	{ID: 33, Name: "CAMERA CONTROL 2", Major: 4},                              // Camera key 2
+	{ID: 34, Name: "CAMERA CONTROL 3", Major: 4},                              // Camera key 3
+	{ID: 35, Name: "FLIP OVER AFTER CRASH", Major: 4},                         // Turtle mode
+	{ID: 36, Name: "PREARM", Major: 4},                                        // Arming precondition
+	{ID: 37, Name: "GPS BEEP SATELLITE COUNT", Major: 4},                      // Satellite count beeps
+	{ID: 39, Name: "VTX PIT MODE", Major: 4},                                  // VTX pit mode
+	{ID: 40, Name: "USER1", Major: 4},                                         // User pin 1
+	{ID: 41, Name: "USER2", Major: 4},                                         // User pin 2
+	{ID: 42, Name: "USER3", Major: 4},                                         // User pin 3
+	{ID: 43, Name: "USER4", Major: 4},                                         // User pin 4
+	{ID: 44, Name: "PID AUDIO", Major: 4},                                     // PID audio
+	{ID: 45, Name: "PARALYZE", Major: 4},                                      // Disarm until reboot
+	{ID: 46, Name: "GPS RESCUE", Major: 4},                                    // Return to home
+	{ID: 47, Name: "ACRO TRAINER", Major: 4},                                  // Angle limit in acro
+	{ID: 48, Name: "VTX CONTROL DISABLE", Major: 4},                           // VTX changes off
+	{ID: 49, Name: "LAUNCH CONTROL", Major: 4},                                // Launch control
+	{ID: 50, Name: "MSP OVERRIDE", Major: 4, Minor: 2},                        // MSP channel override
+	{ID: 51, Name: "STICK COMMANDS DISABLE", Major: 4, Minor: 2},              // Stick commands off
+	{ID: 52, Name: "BEEPER MUTE", Major: 4, Minor: 3},                         // Beeper off
+	{ID: 53, Name: "READY", Major: 4, Minor: 5},                               // Race ready signal
+	{ID: 54, Name: "LAP TIMER RESET", Major: 4, Minor: 5},                     // Lap timer reset
+} // End of flightModes
+
+const armModeID = 0 // Permanent ID of ARM, which can never be a link target
+
+// One active mode range: a mode switched on by an AUX channel range, or following another mode
+type Switch struct {
+	ModeID  int    `json:"mode_id"`          // Permanent ID
+	Mode    string `json:"mode"`             // Mode name ("MODE <id>" when the release does not define it)
+	Channel int    `json:"channel"`          // AUX channel, 0 = AUX1
+	AUX     string `json:"aux"`              // Channel name (e.g. "AUX1")
+	Low     int    `json:"low"`              // Range start (µs, inclusive)
+	High    int    `json:"high"`             // Range end (µs, exclusive)
+	Logic   string `json:"logic"`            // "OR" or "AND" with the mode's other ranges
+	Linked  string `json:"linked,omitempty"` // Mode this one follows instead of a range
+	Line    int    `json:"line"`             // Line number
+} // End of Switch struct
+
+// Names a flight mode permanent ID for a firmware release (nil build = the latest release); reports false for IDs the
+// release does not define
+func ModeName(build *Build, id int) (string, bool) { // Function to name a flight mode
+	for _, mode := range flightModes { // Find the ID
+		if mode.ID != id { // Another mode
+			continue // Keep looking
+		}
+		if !build.Defines(mode.Major, mode.Minor, mode.RemovedMajor, mode.RemovedMinor) { // Not in this release
+			break // Unknown to this release
+		}
+		return mode.Name, true // Found it
+	}
+	return fmt.Sprintf("MODE %d", id), false // Unknown ID
+} // End of ModeName function
+
+// Decodes the active aux slots into a switch table ordered by channel and range; slots whose range is empty and that
+// follow no other mode are left out
+func (dump *Dump) Switches() []Switch { // Method to build the switch table
+	var switches []Switch           // Active slots
+	for _, slot := range dump.Aux { // Decode every slot
+		linked := slot.Linked != armModeID    // Follows another mode
+		if slot.Low >= slot.High && !linked { // Unused slot
+			continue // Leave it out
+		}
+		name, _ := ModeName(dump.Build, slot.ModeID) // Mode name
+
+		entry := Switch{ModeID: slot.ModeID, Mode: name, Channel: slot.Channel, AUX: fmt.Sprintf("AUX%d", slot.Channel+1), Low: slot.Low, High: slot.High, Logic: "OR", Line: slot.Line} // Decode it
+
+		if slot.Logic == 1 { // AND with the mode's other ranges
+			entry.Logic = "AND" // Record it
+		}
+		if linked { // Follows another mode
+			entry.Linked, _ = ModeName(dump.Build, slot.Linked) // Linked mode name
+		}
+		switches = append(switches, entry) // Record it
+	}
+	sort.SliceStable(switches, func(i, j int) bool { // Order by channel, then range
+		if switches[i].Channel != switches[j].Channel { // Different channels
+			return switches[i].Channel < switches[j].Channel // Lower channel first
+		}
+		return switches[i].Low < switches[j].Low // Lower range first
+	}) // End of sort
+	return switches // Return the table
+} // End of Switches method
+
+// Flags mode IDs the firmware release does not define, ranges of different modes overlapping on one channel, and ARM
+// sharing its channel with other modes
+func CheckModes(dump *Dump) []Finding { // Function to validate the mode switches
+	var findings []Finding           // Results
+	switches := dump.Switches()      // Active slots
+	for _, entry := range switches { // Check every mode ID
+		if _, known := ModeName(dump.Build, entry.ModeID); !known { // Not in this release
+			findings = append(findings, Finding{Severity: SeverityWarning, Code: "unknown_mode", Message: fmt.Sprintf("aux mode ID %d is not defined by this firmware release", entry.ModeID), Lines: []int{entry.Line}}) // Record it
+		}
+	}
+	for index, first := range switches { // Check every pair of ranges
+		if first.Linked != "" { // Follows another mode; its range is ignored
+			continue // Next slot
+		}
+		for _, second := range switches[index+1:] { // Later slots
+			if second.Linked != "" || second.Channel != first.Channel || second.ModeID == first.ModeID { // Not a conflict candidate
+				continue // Next slot
+			}
+			if first.ModeID == armModeID || second.ModeID == armModeID { // Reported per ARM channel below
+				continue // Next slot
+			}
+			if first.Low < second.High && second.Low < first.High { // Overlapping ranges
+				findings = append(findings, Finding{Severity: SeverityWarning, Code: "mode_overlap", Message: fmt.Sprintf("%s %d-%d (%s) overlaps %d-%d (%s); both modes turn on together", first.AUX, first.Low, first.High, first.Mode, second.Low, second.High, second.Mode), Lines: []int{first.Line, second.Line}}) // Record it
+			}
+		}
+	}
+	for _, arm := range switches { // Check every ARM range
+		if arm.ModeID != armModeID || arm.Linked != "" { // Not an ARM range
+			continue // Next slot
+		}
+		var others []string              // Other modes on the ARM channel
+		lines := []int{arm.Line}         // Lines involved
+		for _, other := range switches { // Find them
+			if other.ModeID == armModeID || other.Linked != "" || other.Channel != arm.Channel { // Not sharing the switch
+				continue // Next slot
+			}
+			others = append(others, fmt.Sprintf("%s %d-%d", other.Mode, other.Low, other.High)) // Describe it
+			lines = append(lines, other.Line)                                                   // Reference it
+		}
+		if len(others) > 0 { // ARM shares its switch
+			findings = append(findings, Finding{Severity: SeverityWarning, Code: "arm_shared_switch", Message: fmt.Sprintf("ARM on %s %d-%d shares the switch with %s", arm.AUX, arm.Low, arm.High, strings.Join(others, ", ")), Lines: lines}) // Record it
+		}
+	}
+	return findings // Return the findings
+} // End of CheckModes function
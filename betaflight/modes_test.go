@@ -0,0 +1,73 @@
+package betaflight
+
+import "testing" // Test framework
+
+// Firmware header of a 4.4 dump, which has BEEPER MUTE but not READY
+const header44 = "# Betaflight / STM32F7X2 (S7X2) 4.4.2 Jun  9 2023 / 12:55:07 (1d4ff4cb4) MSP API: 1.45"
+
+// Checks that mode IDs are named for the release and that removed or future modes are unknown
+func TestModeName(t *testing.T) { // Test of mode naming
+	build := &Build{Major: 4, Minor: 4} // 4.4 release
+	tests := []struct {
+		id    int    // Permanent ID
+		build *Build // Release
+		name  string // Expected name
+		known bool   // Whether the release defines it
+	}{
+		{id: 0, build: build, name: "ARM", known: true},                               // Always defined
+		{id: 53, build: build, name: "MODE 53", known: false},                         // READY arrived in 4.5
+		{id: 53, build: nil, name: "READY", known: true},                              // Latest release
+		{id: 4, build: build, name: "MODE 4", known: false},                           // ANTI GRAVITY was removed in 4.3
+		{id: 4, build: &Build{Major: 4, Minor: 2}, name: "ANTI GRAVITY", known: true}, // Still there in 4.2
+		{id: 99, build: nil, name: "MODE 99", known: false},                           // Never defined
+	} // End of cases
+	for _, test := range tests { // Run every case
+		if name, known := ModeName(test.build, test.id); name != test.name || known != test.known { // Wrong answer
+			t.Errorf("ModeName(%+v, %d) = %q, %v; want %q, %v", test.build, test.id, name, known, test.name, test.known) // Report it
+		}
+	}
+} // End of TestModeName function
+
+// Checks that empty slots are left out and the table is ordered by channel, then range
+func TestSwitches(t *testing.T) { // Test of the switch table
+	dump := mustParse(t, header44, "aux 0 0 1 1700 2100 0 0", "aux 1 1 0 1300 1700 0 0", "aux 2 2 0 900 1300 1 0", "aux 3 13 0 900 900 0 0", "aux 4 28 0 900 900 0 1") // ARM, ANGLE, HORIZON, an empty slot and AIR MODE linked to ANGLE
+	switches := dump.Switches()                                                                                                                                        // Decode the slots
+	if len(switches) != 4 {                                                                                                                                            // The empty slot is left out
+		t.Fatalf("Switches = %+v", switches) // Stop the test
+	}
+	order := []string{"HORIZON", "AIR MODE", "ANGLE", "ARM"} // AUX1 ranges by start, then AUX2
+	for index, name := range order {                         // Check the order
+		if switches[index].Mode != name { // Wrong position
+			t.Errorf("switch %d = %q, want %q", index, switches[index].Mode, name) // Report it
+		}
+	}
+	if switches[0].Logic != "AND" || switches[1].Linked != "ANGLE" || switches[3].AUX != "AUX2" { // Decoded fields
+		t.Errorf("Switches = %+v", switches) // Report it
+	}
+} // End of TestSwitches function
+
+// Checks unknown_mode, mode_overlap and arm_shared_switch, each with a case that must not be reported
+func TestCheckModes(t *testing.T) { // Test of the mode checks
+	tests := []struct {
+		name  string   // Case name
+		lines []string // aux lines
+		code  string   // Code under test
+		count int      // Expected findings
+	}{
+		{name: "unknown mode", lines: []string{"aux 0 53 1 1700 2100 0 0"}, code: "unknown_mode", count: 1},                              // READY on 4.4
+		{name: "known mode", lines: []string{"aux 0 52 1 1700 2100 0 0"}, code: "unknown_mode", count: 0},                                // BEEPER MUTE on 4.4
+		{name: "overlap", lines: []string{"aux 0 1 1 900 1600 0 0", "aux 1 2 1 1500 2100 0 0"}, code: "mode_overlap", count: 1},          // ANGLE and HORIZON share 1500-1600
+		{name: "adjacent", lines: []string{"aux 0 1 1 900 1500 0 0", "aux 1 2 1 1500 2100 0 0"}, code: "mode_overlap", count: 0},         // High ends are exclusive
+		{name: "other channel", lines: []string{"aux 0 1 1 900 1600 0 0", "aux 1 2 2 1500 2100 0 0"}, code: "mode_overlap", count: 0},    // Different switches
+		{name: "arm shared", lines: []string{"aux 0 0 0 1700 2100 0 0", "aux 1 13 0 900 1300 0 0"}, code: "arm_shared_switch", count: 1}, // BEEPER on the ARM switch
+		{name: "arm alone", lines: []string{"aux 0 0 0 1700 2100 0 0", "aux 1 13 1 900 1300 0 0"}, code: "arm_shared_switch", count: 0},  // BEEPER on its own switch
+		{name: "arm linked", lines: []string{"aux 0 0 0 1700 2100 0 0", "aux 1 28 0 900 900 0 1"}, code: "arm_shared_switch", count: 0},  // AIR MODE follows ANGLE, not a range
+		{name: "arm overlap", lines: []string{"aux 0 0 0 1300 2100 0 0", "aux 1 1 0 1700 2100 0 0"}, code: "mode_overlap", count: 0},     // Reported as arm_shared_switch instead
+	} // End of cases
+	for _, test := range tests { // Run every case
+		t.Run(test.name, func(t *testing.T) { // One subtest per case
+			findings := CheckModes(mustParse(t, append([]string{header44}, test.lines...)...)) // Run the check
+			expectCode(t, findings, test.code, test.count)                                     // Count the findings
+		}) // End of subtest
+	}
+} // End of TestCheckModes function
@@ -0,0 +1,598 @@
+package betaflight
+
+import (
+	"bufio"        // Reads dumps line by line
+	"bytes"        // Finds line endings
+	"fmt"          // Formats parse errors
+	"io"           // Accepts any reader
+	"os"           // Opens dump files
+	"regexp"       // Matches header and pin comments
+	"strconv"      // Parses numeric arguments
+	"strings"      // Splits command lines
+	"unicode/utf8" // Detects non-UTF-8 lines
+)
+
+// A problem with one line of a dump
+type LineError struct {
+	Line    int    // Line number (1-based)
+	Text    string // The offending line
+	Message string // What is wrong with it
+} // End of LineError struct
+
+// Formats the error as "line N: message: text"
+func (lineError *LineError) Error() string { // Method to describe a line error
+	return fmt.Sprintf("line %d: %s: %q", lineError.Line, lineError.Message, lineError.Text) // Line, problem and text
+} // End of Error method
+
+// Every line error found in a dump, in line order
+type ErrorList []*LineError
+
+// Summarizes the list: the first error and how many more there are
+func (list ErrorList) Error() string { // Method to describe the list
+	switch len(list) { // Pick the wording
+	case 0: // Should not happen; parsers return nil instead
+		return "no errors" // Nothing to report
+	case 1: // A single error
+		return list[0].Error() // Describe it
+	}
+	return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1) // First error plus a count
+} // End of Error method
+
+var versionHeaderPattern = regexp.MustCompile(`^# \S+ / \S+ \(\w+\) \d+\.\d+`)                                                             // "# Betaflight / STM32F7X2 (S7X2) 4.5.1 ..."
+var timerCommentPattern = regexp.MustCompile(`^# pin ([A-Z]\d+): (TIM\d+) (CH\d+N?) \((AF\d+)\)$`)                                         // "# pin B04: TIM3 CH1 (AF2)"
+var dmaCommentPattern = regexp.MustCompile(`^# (pin [A-Z]\d+|[A-Z_]+ \d+): DMA(\d+) (Stream|Channel) (\d+)(?: (Channel|Request) (\d+))?$`) // "# pin B04: DMA1 Stream 4 Channel 5"
+
+// Commands that are valid in a dump but have no typed form; they are kept in Dump.Commands only
+var untypedCommands = map[string]bool{ // Keyed by lowercase command name
+	"batch":    true, // batch start / batch end
+	"save":     true, // Saves and reboots
+	"exit":     true, // Leaves the CLI without saving
+	"defaults": true, // Resets to defaults (e.g. "defaults nosave")
+	"diff":     true, // Pasted diff command
+	"dump":     true, // Pasted dump command
+	"mmix":     true, // Custom motor mixer
+	"smix":     true, // Servo mixer
+	"servo":    true, // Servo endpoints
+	"rxfail":   true, // Failsafe channel behavior
+} // End of untypedCommands map
+
+// Parser state while reading one dump
+type parser struct {
+	dump     *Dump      // Model being built
+	errors   ErrorList  // Problems found so far
+	settings *[]Setting // Where set lines currently go (master or a profile)
+	command  Command    // Command being parsed
+	text     string     // Its full line
+	ended    bool       // A save or batch end was seen; the CLI never applies what follows
+} // End of parser struct
+
+// Parses a dump file
+func ParseFile(path string) (*Dump, error) { // Function to parse a dump on disk
+	file, err := os.Open(path) // Open the file
+	if err != nil {            // Handle missing files
+		return nil, err // Propagate the error
+	}
+	defer file.Close() // Close when done
+	return Parse(file) // Parse its contents
+} // End of ParseFile function
+
+// Parses a dump. The returned model holds everything that could be parsed; the error, if any, is an ErrorList
+// naming every line that could not be understood.
+func Parse(reader io.Reader) (*Dump, error) { // Function to parse a dump
+	state := &parser{dump: &Dump{}}                     // Empty model
+	state.settings = &state.dump.Master                 // Sets go to the master section until a profile is selected
+	encoding := &state.dump.Encoding                    // Line ending and byte order mark facts
+	scanner := bufio.NewScanner(reader)                 // Read line by line
+	scanner.Buffer(nil, 1<<20)                          // Allow long lines (vtxtable bands, pasted hex)
+	scanner.Split(encoding.scanLines)                   // Split like bufio.ScanLines while recording line endings
+	for lineNumber := 1; scanner.Scan(); lineNumber++ { // Visit every line
+		raw := scanner.Text()             // Line without its newline
+		encoding.Lines++                  // Count it
+		if strings.HasSuffix(raw, "\r") { // Windows line ending
+			encoding.CRLFLines++ // Count it
+		}
+		if !utf8.ValidString(raw) { // Not UTF-8 (e.g. pasted from a Latin-1 editor)
+			encoding.InvalidUTF8Lines++ // Count it
+		}
+		if lineNumber == 1 && strings.HasPrefix(raw, "\ufeff") { // A UTF-8 byte order mark precedes the first line
+			encoding.BOM = true                     // Record it
+			raw = strings.TrimPrefix(raw, "\ufeff") // Drop it
+		}
+		text := strings.TrimSpace(raw) // Drop CR and surrounding blanks
+		switch {                       // Classify the line
+		case text == "": // Blank line
+			continue // Nothing to parse
+		case strings.HasPrefix(text, "#"): // Comment
+			state.comment(text, lineNumber) // Comments carry the header and pin details
+		default: // Command
+			state.line(text, lineNumber) // Parse the command
+		}
+	}
+	if err := scanner.Err(); err != nil { // Read failure
+		return state.dump, err // Return what was parsed
+	}
+	if len(state.errors) > 0 { // Some lines were not understood
+		return state.dump, state.errors // Return the model with the errors
+	}
+	return state.dump, nil // Clean parse
+} // End of Parse function
+
+// Splits input into lines like bufio.ScanLines, but keeps a trailing CR so CRLF files can be detected and records
+// whether the last line ended with a newline
+func (encoding *Encoding) scanLines(data []byte, atEOF bool) (int, []byte, error) { // Method to split lines
+	if index := bytes.IndexByte(data, '\n'); index >= 0 { // A complete line
+		encoding.FinalNewline = true        // So far every line ended with a newline
+		return index + 1, data[:index], nil // Line without its newline
+	}
+	if atEOF && len(data) > 0 { // Last line without a newline
+		encoding.FinalNewline = false // Record it
+		return len(data), data, nil   // The rest of the input
+	}
+	return 0, nil, nil // Need more data
+} // End of scanLines method
+
+// Reads the comments that carry information: the firmware header, the craft name and pin details
+func (state *parser) comment(text string, lineNumber int) { // Method to parse a comment
+	switch { // Pick the comment kind
+	case state.dump.VersionLineNo == 0 && versionHeaderPattern.MatchString(text): // Firmware header
+		state.dump.VersionLine = strings.TrimPrefix(text, "# ") // Keep the header text
+		state.dump.VersionLineNo = lineNumber                   // And its line
+		build, err := ParseBuild(text)                          // Decode the build metadata
+		if err != nil {                                         // Header with a malformed date
+			state.errors = append(state.errors, &LineError{Line: lineNumber, Text: text, Message: err.Error()}) // Report it
+			return                                                                                              // Keep the raw header only
+		}
+		state.dump.Build = build // Record it
+	case configRevisionPattern.MatchString(text): // Config revision
+		state.dump.ConfigRev = configRevisionPattern.FindStringSubmatch(text)[1] // Record it
+	case strings.HasPrefix(text, "# name: "): // Craft name
+		state.dump.CraftName = strings.TrimPrefix(text, "# name: ") // Record it
+	default: // Pin comments follow the line they describe
+		if match := timerCommentPattern.FindStringSubmatch(text); match != nil { // Timer comment
+			if last := len(state.dump.Timers) - 1; last >= 0 && state.dump.Timers[last].Pin == match[1] { // Describes the previous timer line
+				state.dump.Timers[last].Timer = match[2]   // Timer
+				state.dump.Timers[last].Channel = match[3] // Channel
+			}
+			return // Done
+		}
+		if match := dmaCommentPattern.FindStringSubmatch(text); match != nil { // DMA comment
+			if last := len(state.dump.DMA) - 1; last >= 0 && state.dump.DMA[last].key() == match[1] { // Describes the previous dma line
+				state.dump.DMA[last].Stream = newDMAStream(match) // Attach the stream
+			}
+		}
+	}
+} // End of comment method
+
+// Builds a DMAStream from a matched DMA comment
+func newDMAStream(match []string) *DMAStream { // Function to decode a DMA comment
+	stream := &DMAStream{Channel: -1, Request: -1} // Unprinted fields are -1
+	stream.Controller, _ = strconv.Atoi(match[2])  // DMA controller
+	stream.Stream, _ = strconv.Atoi(match[4])      // Stream, or G4 channel
+	value, _ := strconv.Atoi(match[6])             // Channel or request selection
+	switch match[5] {                              // Which selection was printed
+	case "Channel": // F4/F7
+		stream.Channel = value // Channel selection
+	case "Request": // H7/G4
+		stream.Request = value // DMAMUX request
+	}
+	return stream // Return the stream
+} // End of newDMAStream function
+
+// The "pin B04" or "ADC 1" prefix used by the comment describing this DMA line
+func (dma DMA) key() string { // Method to match DMA comments
+	if dma.Peripheral == "pin" { // Pin DMA
+		return "pin " + dma.Pin // e.g. "pin B04"
+	}
+	return fmt.Sprintf("%s %d", dma.Peripheral, dma.Index) // e.g. "ADC 1"
+} // End of key method
+
+// Parses one command line
+func (state *parser) line(text string, lineNumber int) { // Method to parse a command
+	fields := strings.Fields(text)                                                                // Split on whitespace
+	state.text = text                                                                             // Remember the line for errors
+	state.command = Command{Name: strings.ToLower(fields[0]), Args: fields[1:], Line: lineNumber} // The CLI matches commands case-insensitively
+
+	handler, typed := commandParsers[state.command.Name] // Typed command
+	if !typed && !untypedCommands[state.command.Name] {  // Not a CLI command at all
+		state.fail("unknown command %q", fields[0]) // Report it
+		return                                      // Nothing to record
+	}
+	state.dump.Commands = append(state.dump.Commands, state.command) // Keep every command in order
+	if state.ended {                                                 // After save or batch end
+		if state.command.Name != "save" { // The save that follows batch end is the expected close
+			state.dump.Ignored = append(state.dump.Ignored, state.command) // Classify reports it
+		}
+		return // Never applied, so it changes nothing in the model
+	}
+	if typed { // Build the typed form
+		handler(state) // Parse the arguments
+	}
+	if endsInput(state.command) { // save reboots the flight controller
+		state.ended = true // Later lines are never applied
+	}
+} // End of line method
+
+// Reports whether a command ends what the CLI applies: save reboots, and batch end closes the pasted batch
+func endsInput(command Command) bool { // Function to detect the end of a paste
+	return command.Name == "save" || command.Name == "batch" && len(command.Args) > 0 && strings.EqualFold(command.Args[0], "end") // save or batch end
+} // End of endsInput function
+
+// Parsers for commands with a typed form, keyed by lowercase command name
+var commandParsers = map[string]func(*parser){ // Filled in below
+	"set":             (*parser).parseSet,            // set NAME = VALUE
+	"profile":         (*parser).parseProfile,        // profile N
+	"rateprofile":     (*parser).parseRateProfile,    // rateprofile N
+	"board_name":      (*parser).parseBoardName,      // board_name NAME
+	"manufacturer_id": (*parser).parseManufacturerID, // manufacturer_id ID
+	"name":            (*parser).parseName,           // name CRAFT (before 4.0)
+	"resource":        (*parser).parseResource,       // resource FUNCTION INDEX PIN
+	"timer":           (*parser).parseTimer,          // timer PIN AFn
+	"dma":             (*parser).parseDMA,            // dma PERIPHERAL INDEX OPTION
+	"feature":         (*parser).parseFeature,        // feature [-]NAME
+	"beeper":          (*parser).parseBeeper,         // beeper [-]NAME
+	"beacon":          (*parser).parseBeacon,         // beacon [-]NAME
+	"serial":          (*parser).parseSerial,         // serial ID FUNCTIONS BAUDS...
+	"map":             (*parser).parseMap,            // map AETR1234
+	"mixer":           (*parser).parseMixer,          // mixer QUADX
+	"led":             (*parser).parseLED,            // led N DEFINITION
+	"color":           (*parser).parseColor,          // color N H,S,V
+	"mode_color":      (*parser).parseModeColor,      // mode_color MODE FUNCTION COLOR
+	"aux":             (*parser).parseAux,            // aux N MODE CHANNEL LOW HIGH LOGIC LINKED
+	"adjrange":        (*parser).parseAdjRange,       // adjrange N SLOT CHANNEL LOW HIGH FUNCTION SWITCH CENTER SCALE
+	"rxrange":         (*parser).parseRxRange,        // rxrange N MIN MAX
+	"vtx":             (*parser).parseVTX,            // vtx N CHANNEL BAND CHANNEL POWER LOW HIGH
+	"vtxtable":        (*parser).parseVTXTable,       // vtxtable ...
+} // End of commandParsers map
+
+// Records an error for the current line
+func (state *parser) fail(format string, args ...any) { // Method to report a bad line
+	state.errors = append(state.errors, &LineError{Line: state.command.Line, Text: state.text, Message: fmt.Sprintf(format, args...)}) // Append the error
+} // End of fail method
+
+// Checks the argument count; optional trailing arguments may be missing
+func (state *parser) arguments(minimum, maximum int) bool { // Method to validate the argument count
+	count := len(state.command.Args)          // Arguments given
+	if count >= minimum && count <= maximum { // Within range
+		return true // Valid
+	}
+	if minimum == maximum { // Fixed count
+		state.fail("%s takes %d arguments, got %d", state.command.Name, minimum, count) // Report it
+	} else { // Range
+		state.fail("%s takes %d to %d arguments, got %d", state.command.Name, minimum, maximum, count) // Report it
+	}
+	return false // Invalid
+} // End of arguments method
+
+// Parses the arguments from index first on as integers; missing optional arguments are 0
+func (state *parser) integers(first, count int) ([]int, bool) { // Method to parse numeric arguments
+	values := make([]int, count) // Parsed values
+	for index := range values {  // Parse every argument present
+		position := first + index                // Argument position
+		if position >= len(state.command.Args) { // Optional argument left out
+			break // Keep the zero
+		}
+		value, err := strconv.Atoi(state.command.Args[position]) // Parse it
+		if err != nil {                                          // Not a number
+			state.fail("invalid number %q", state.command.Args[position]) // Report it
+			return nil, false                                             // Give up on the line
+		}
+		values[index] = value // Store it
+	}
+	return values, true // All present arguments were numbers
+} // End of integers method
+
+// Normalizes a pin argument: "b04" → "B04", "NONE" → ""
+func normalizePin(pin string) string { // Function to normalize pin names
+	pin = strings.ToUpper(pin) // Pins are printed in uppercase
+	if pin == "NONE" {         // Unassigned
+		return "" // No pin
+	}
+	return pin // Return the pin
+} // End of normalizePin function
+
+// set NAME = VALUE (spaces around "=" are optional)
+func (state *parser) parseSet() { // Method to parse a set line
+	assignment := strings.TrimSpace(state.text[len("set"):]) // Everything after "set"
+	name, value, found := strings.Cut(assignment, "=")       // Split at the first "="
+	name = strings.TrimSpace(name)                           // Variable name
+	if !found || name == "" {                                // "set" alone prints variables; not a dump line
+		state.fail("set needs NAME = VALUE") // Report it
+		return                               // Nothing to record
+	}
+	*state.settings = append(*state.settings, Setting{Name: strings.ToLower(name), Value: strings.TrimSpace(value), Line: state.command.Line}) // Record the setting
+} // End of parseSet method
+
+// profile N: later set lines belong to PID profile N
+func (state *parser) parseProfile() { // Method to parse a profile selection
+	state.selectProfile(&state.dump.Profiles) // Switch to the profile
+} // End of parseProfile method
+
+// rateprofile N: later set lines belong to rate profile N
+func (state *parser) parseRateProfile() { // Method to parse a rate profile selection
+	state.selectProfile(&state.dump.RateProfiles) // Switch to the rate profile
+} // End of parseRateProfile method
+
+// Switches set lines to profile N of the list, creating it on first use
+func (state *parser) selectProfile(profiles *[]*Profile) { // Method to select a profile
+	if !state.arguments(1, 1) { // profile N
+		return // Reported
+	}
+	values, ok := state.integers(0, 1) // Profile number
+	if !ok {                           // Not a number
+		return // Reported
+	}
+	for _, profile := range *profiles { // Selected before (e.g. restored at the end of a dump)
+		if profile.Index == values[0] { // Same profile
+			state.settings = &profile.Settings // Switch to it
+			return                             // Done
+		}
+	}
+	profile := &Profile{Index: values[0], Line: state.command.Line} // New profile
+	*profiles = append(*profiles, profile)                          // Record it
+	state.settings = &profile.Settings                              // Switch to it
+} // End of selectProfile method
+
+// board_name NAME (older boards may have spaces in the name)
+func (state *parser) parseBoardName() { // Method to parse board_name
+	state.dump.BoardName = strings.Join(state.command.Args, " ") // Whole rest of the line
+} // End of parseBoardName method
+
+// manufacturer_id ID (may be empty)
+func (state *parser) parseManufacturerID() { // Method to parse manufacturer_id
+	state.dump.ManufacturerID = strings.Join(state.command.Args, " ") // Whole rest of the line
+} // End of parseManufacturerID method
+
+// name CRAFT, the craft name command before 4.0
+func (state *parser) parseName() { // Method to parse name
+	state.dump.CraftName = strings.Join(state.command.Args, " ") // Whole rest of the line
+} // End of parseName method
+
+// resource FUNCTION INDEX PIN
+func (state *parser) parseResource() { // Method to parse resource
+	if !state.arguments(3, 3) { // Fixed form
+		return // Reported
+	}
+	values, ok := state.integers(1, 1) // Instance number
+	if !ok {                           // Not a number
+		return // Reported
+	}
+	state.dump.Resources = append(state.dump.Resources, Resource{Function: strings.ToUpper(state.command.Args[0]), Index: values[0], Pin: normalizePin(state.command.Args[2]), Line: state.command.Line}) // Record it
+} // End of parseResource method
+
+// timer PIN AFn
+func (state *parser) parseTimer() { // Method to parse timer
+	if !state.arguments(2, 2) { // Fixed form
+		return // Reported
+	}
+	state.dump.Timers = append(state.dump.Timers, Timer{Pin: normalizePin(state.command.Args[0]), Alternate: normalizePin(state.command.Args[1]), Line: state.command.Line}) // Record it
+} // End of parseTimer method
+
+// dma pin PIN OPTION or dma PERIPHERAL INDEX OPTION
+func (state *parser) parseDMA() { // Method to parse dma
+	if !state.arguments(3, 3) { // Both forms have three arguments
+		return // Reported
+	}
+	dma := DMA{Peripheral: strings.ToUpper(state.command.Args[0]), Option: -1, Line: state.command.Line} // Common fields
+	if strings.EqualFold(dma.Peripheral, "pin") {                                                        // Pin DMA
+		dma.Peripheral = "pin"                        // Keep the CLI spelling
+		dma.Pin = normalizePin(state.command.Args[1]) // Pin
+	} else { // Peripheral DMA
+		values, ok := state.integers(1, 1) // Instance number
+		if !ok {                           // Not a number
+			return // Reported
+		}
+		dma.Index = values[0] // Instance
+	}
+	if !strings.EqualFold(state.command.Args[2], "NONE") { // Option assigned
+		option, err := strconv.Atoi(state.command.Args[2]) // Option index
+		if err != nil {                                    // Not a number
+			state.fail("invalid number %q", state.command.Args[2]) // Report it
+			return                                                 // Nothing to record
+		}
+		dma.Option = option // Record it
+	}
+	state.dump.DMA = append(state.dump.DMA, dma) // Record the line
+} // End of parseDMA method
+
+// feature [-]NAME
+func (state *parser) parseFeature() { // Method to parse feature
+	state.parseToggle(&state.dump.Features) // Same form as beeper
+} // End of parseFeature method
+
+// beeper [-]NAME
+func (state *parser) parseBeeper() { // Method to parse beeper
+	state.parseToggle(&state.dump.Beepers) // Same form as feature
+} // End of parseBeeper method
+
+// beacon [-]NAME
+func (state *parser) parseBeacon() { // Method to parse beacon
+	state.parseToggle(&state.dump.Beacons) // Same form as feature
+} // End of parseBeacon method
+
+// Parses a "[-]NAME" argument into a toggle list
+func (state *parser) parseToggle(toggles *[]Toggle) { // Method to parse feature-style lines
+	if !state.arguments(1, 1) { // One name
+		return // Reported
+	}
+	name := strings.ToUpper(state.command.Args[0])                                                                                             // Names are uppercase
+	*toggles = append(*toggles, Toggle{Name: strings.TrimPrefix(name, "-"), Enabled: !strings.HasPrefix(name, "-"), Line: state.command.Line}) // Record it
+} // End of parseToggle method
+
+// serial ID FUNCTIONS MSP_BAUD GPS_BAUD TELEMETRY_BAUD BLACKBOX_BAUD
+func (state *parser) parseSerial() { // Method to parse serial
+	if !state.arguments(6, 6) { // Fixed form
+		return // Reported
+	}
+	functions, err := strconv.ParseUint(state.command.Args[1], 10, 32) // Function bitmask
+	if err != nil {                                                    // Not a number
+		state.fail("invalid function mask %q", state.command.Args[1]) // Report it
+		return                                                        // Nothing to record
+	}
+	values, ok := state.integers(0, 6) // Identifier and baud rates
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.Serial = append(state.dump.Serial, SerialPort{Identifier: values[0], Functions: uint32(functions), MSPBaud: values[2], GPSBaud: values[3], TelemetryBaud: values[4], BlackboxBaud: values[5], Line: state.command.Line}) // Record it
+} // End of parseSerial method
+
+// map AETR1234
+func (state *parser) parseMap() { // Method to parse map
+	if state.arguments(1, 1) { // One channel order
+		state.dump.Map = strings.ToUpper(state.command.Args[0]) // Record it
+	}
+} // End of parseMap method
+
+// mixer TYPE
+func (state *parser) parseMixer() { // Method to parse mixer
+	if state.arguments(1, 1) { // One mixer type
+		state.dump.Mixer = strings.ToUpper(state.command.Args[0]) // Record it
+	}
+} // End of parseMixer method
+
+// led N DEFINITION
+func (state *parser) parseLED() { // Method to parse led
+	if !state.arguments(2, 2) { // Fixed form
+		return // Reported
+	}
+	values, ok := state.integers(0, 1) // LED number
+	if !ok {                           // Not a number
+		return // Reported
+	}
+	state.dump.LEDs = append(state.dump.LEDs, LED{Index: values[0], Definition: state.command.Args[1], Line: state.command.Line}) // Record it
+} // End of parseLED method
+
+// color N H,S,V
+func (state *parser) parseColor() { // Method to parse color
+	if !state.arguments(2, 2) { // Fixed form
+		return // Reported
+	}
+	components := strings.Split(state.command.Args[1], ",") // Hue, saturation and value
+	if len(components) != 3 {                               // Malformed color
+		state.fail("color needs HUE,SATURATION,VALUE") // Report it
+		return                                         // Nothing to record
+	}
+	state.command.Args = append([]string{state.command.Args[0]}, components...) // Parse all four numbers together
+	values, ok := state.integers(0, 4)                                          // Index and components
+	if !ok {                                                                    // Not numbers
+		return // Reported
+	}
+	state.dump.Colors = append(state.dump.Colors, Color{Index: values[0], Hue: values[1], Saturation: values[2], Value: values[3], Line: state.command.Line}) // Record it
+} // End of parseColor method
+
+// mode_color MODE FUNCTION COLOR
+func (state *parser) parseModeColor() { // Method to parse mode_color
+	if !state.arguments(3, 3) { // Fixed form
+		return // Reported
+	}
+	values, ok := state.integers(0, 3) // Mode, function and color
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.ModeColors = append(state.dump.ModeColors, ModeColor{Mode: values[0], Function: values[1], Color: values[2], Line: state.command.Line}) // Record it
+} // End of parseModeColor method
+
+// aux N MODE CHANNEL LOW HIGH [LOGIC [LINKED]]
+func (state *parser) parseAux() { // Method to parse aux
+	if !state.arguments(5, 7) { // Logic and linked mode were added in 3.x and 4.0
+		return // Reported
+	}
+	values, ok := state.integers(0, 7) // Every field
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.Aux = append(state.dump.Aux, AuxRange{Index: values[0], ModeID: values[1], Channel: values[2], Low: values[3], High: values[4], Logic: values[5], Linked: values[6], Line: state.command.Line}) // Record it
+} // End of parseAux method
+
+// adjrange N SLOT CHANNEL LOW HIGH FUNCTION SWITCH [CENTER SCALE]
+func (state *parser) parseAdjRange() { // Method to parse adjrange
+	if !state.arguments(7, 9) { // Center and scale were added in 4.1
+		return // Reported
+	}
+	values, ok := state.integers(0, 9) // Every field
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.AdjRanges = append(state.dump.AdjRanges, AdjRange{Index: values[0], Slot: values[1], Channel: values[2], Low: values[3], High: values[4], Function: values[5], SwitchChannel: values[6], Center: values[7], Scale: values[8], Line: state.command.Line}) // Record it
+} // End of parseAdjRange method
+
+// rxrange N MIN MAX
+func (state *parser) parseRxRange() { // Method to parse rxrange
+	if !state.arguments(3, 3) { // Fixed form
+		return // Reported
+	}
+	values, ok := state.integers(0, 3) // Channel and range
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.RxRanges = append(state.dump.RxRanges, RxRange{Channel: values[0], Min: values[1], Max: values[2], Line: state.command.Line}) // Record it
+} // End of parseRxRange method
+
+// vtx N CHANNEL BAND VTX_CHANNEL POWER LOW HIGH
+func (state *parser) parseVTX() { // Method to parse vtx
+	if !state.arguments(7, 7) { // Fixed form
+		return // Reported
+	}
+	values, ok := state.integers(0, 7) // Every field
+	if !ok {                           // Not numbers
+		return // Reported
+	}
+	state.dump.VTX = append(state.dump.VTX, VTXChannel{Index: values[0], AuxChannel: values[1], Band: values[2], Channel: values[3], Power: values[4], Low: values[5], High: values[6], Line: state.command.Line}) // Record it
+} // End of parseVTX method
+
+// vtxtable bands N | channels N | band N NAME LETTER FACTORY|CUSTOM FREQ... | powerlevels N | powervalues V... | powerlabels L...
+func (state *parser) parseVTXTable() { // Method to parse vtxtable
+	if len(state.command.Args) == 0 { // Sub-command required
+		state.fail("vtxtable needs a sub-command") // Report it
+		return                                     // Nothing to record
+	}
+	if state.dump.VTXTable == nil { // First vtxtable line
+		state.dump.VTXTable = &VTXTable{Line: state.command.Line} // Start the table
+	}
+	table := state.dump.VTXTable                    // Table being built
+	switch strings.ToLower(state.command.Args[0]) { // Pick the sub-command
+	case "bands": // Band count
+		if values, ok := state.integers(1, 1); ok && state.arguments(2, 2) { // vtxtable bands N
+			table.Bands = values[0] // Record it
+		}
+	case "channels": // Channels per band
+		if values, ok := state.integers(1, 1); ok && state.arguments(2, 2) { // vtxtable channels N
+			table.Channels = values[0] // Record it
+		}
+	case "powerlevels": // Power level count
+		if values, ok := state.integers(1, 1); ok && state.arguments(2, 2) { // vtxtable powerlevels N
+			table.PowerLevels = values[0] // Record it
+		}
+	case "powervalues": // Values sent to the VTX
+		if values, ok := state.integers(1, len(state.command.Args)-1); ok { // Any number of values
+			table.PowerValues = values // Record them
+		}
+	case "powerlabels": // Labels shown in the OSD
+		table.PowerLabels = append([]string{}, state.command.Args[1:]...) // Record them
+	case "band": // One band
+		state.parseVTXBand(table) // Parse the band
+	default: // Unknown sub-command
+		state.fail("unknown vtxtable sub-command %q", state.command.Args[0]) // Report it
+	}
+} // End of parseVTXTable method
+
+// vtxtable band N NAME LETTER FACTORY|CUSTOM FREQ...
+func (state *parser) parseVTXBand(table *VTXTable) { // Method to parse a vtxtable band
+	if len(state.command.Args) < 5 { // band, number, name, letter and type at least
+		state.fail("vtxtable band needs NUMBER NAME LETTER FACTORY|CUSTOM FREQUENCIES") // Report it
+		return                                                                          // Nothing to record
+	}
+	values, ok := state.integers(1, 1) // Band number
+	if !ok {                           // Not a number
+		return // Reported
+	}
+	bandType := strings.ToUpper(state.command.Args[4]) // FACTORY or CUSTOM
+	if bandType != "FACTORY" && bandType != "CUSTOM" { // Neither
+		state.fail("vtxtable band type must be FACTORY or CUSTOM, got %q", state.command.Args[4]) // Report it
+		return                                                                                    // Nothing to record
+	}
+	frequencies, ok := state.integers(5, len(state.command.Args)-5) // Frequencies in MHz
+	if !ok {                                                        // Not numbers
+		return // Reported
+	}
+	table.BandList = append(table.BandList, VTXBand{Number: values[0], Name: state.command.Args[2], Letter: state.command.Args[3], Factory: bandType == "FACTORY", Frequencies: frequencies, Line: state.command.Line}) // Record the band
+} // End of parseVTXBand method
@@ -0,0 +1,189 @@
+package betaflight
+
+import (
+	"errors"  // Unwraps parse error lists
+	"slices"  // Compares parsed values
+	"strings" // Builds inline dumps
+	"testing" // Test framework
+)
+
+// Parses a dump written inline, one argument per line, failing the test on any line error
+func mustParse(t *testing.T, lines ...string) *Dump { // Helper to build a dump
+	t.Helper()                                                              // Report the caller's line
+	dump, err := Parse(strings.NewReader(strings.Join(lines, "\n") + "\n")) // Parse the lines
+	if err != nil {                                                         // Every line should parse
+		t.Fatalf("Parse: %v", err) // Stop the test
+	}
+	return dump // Return the model
+} // End of mustParse function
+
+// Fails the test unless the findings contain the code exactly count times
+func expectCode(t *testing.T, findings []Finding, code string, count int) { // Helper to assert a check result
+	t.Helper()                         // Report the caller's line
+	got := 0                           // Matching findings
+	for _, finding := range findings { // Count the matches
+		if finding.Code == code { // Same code
+			got++ // Count it
+		}
+	}
+	if got != count { // Wrong number of findings
+		t.Errorf("%d %s findings, want %d; got %v", got, code, count, findings) // Report it
+	}
+} // End of expectCode function
+
+// Checks that every section of a dump lands in its typed field with its line number
+func TestParseSections(t *testing.T) { // Test of the section parsers
+	dump := mustParse(t,
+		"# version",
+		"# Betaflight / STM32F7X2 (S7X2) 4.5.1 Jul 30 2024 / 07:54:46 (77d01ba3b) MSP API: 1.46",
+		"# config rev: bc5da0e",
+		"board_name GEPRCF722",
+		"manufacturer_id GEPR",
+		"# name: MARK5",
+		"resource MOTOR 1 b04",
+		"resource LED_STRIP 1 NONE",
+		"timer B04 AF2",
+		"# pin B04: TIM3 CH1 (AF2)",
+		"dma pin B04 0",
+		"# pin B04: DMA1 Stream 4 Channel 5",
+		"dma ADC 1 NONE",
+		"feature -RX_PARALLEL_PWM",
+		"feature TELEMETRY",
+		"serial 0 64 115200 57600 0 115200",
+		"map TAER1234",
+		"mixer quadx",
+		"led 0 7,7::C:2",
+		"color 0 0,0,0",
+		"mode_color 6 0 2",
+		"aux 0 0 0 1700 2100 0 0",
+		"rxrange 0 1000 2000",
+		"vtx 0 3 1 1 1 900 1100",
+		"set gyro_lpf1_static_hz = 250",
+		"profile 0",
+		"set p_pitch=47",
+		"rateprofile 0",
+		"set roll_rc_rate = 7",
+		"save",
+	) // End of dump
+
+	if dump.Build == nil || dump.Build.Version != "4.5.1" || dump.Build.MCU != "STM32F7X2" { // Firmware header
+		t.Errorf("Build = %+v", dump.Build) // Report it
+	}
+	if dump.VersionLineNo != 2 || dump.ConfigRev != "bc5da0e" || dump.BoardName != "GEPRCF722" || dump.ManufacturerID != "GEPR" || dump.CraftName != "MARK5" { // Identity
+		t.Errorf("identity = %d %q %q %q %q", dump.VersionLineNo, dump.ConfigRev, dump.BoardName, dump.ManufacturerID, dump.CraftName) // Report it
+	}
+	if want := []Resource{{Function: "MOTOR", Index: 1, Pin: "B04", Line: 7}, {Function: "LED_STRIP", Index: 1, Line: 8}}; !slices.Equal(dump.Resources, want) { // Pins are normalized
+		t.Errorf("Resources = %+v, want %+v", dump.Resources, want) // Report it
+	}
+	if want := []Timer{{Pin: "B04", Alternate: "AF2", Timer: "TIM3", Channel: "CH1", Line: 9}}; !slices.Equal(dump.Timers, want) { // The comment names the timer
+		t.Errorf("Timers = %+v, want %+v", dump.Timers, want) // Report it
+	}
+	if len(dump.DMA) != 2 || dump.DMA[0].Stream == nil || *dump.DMA[0].Stream != (DMAStream{Controller: 1, Stream: 4, Channel: 5, Request: -1}) || dump.DMA[1].Option != -1 || dump.DMA[1].Stream != nil { // Stream from the comment; NONE is -1
+		t.Errorf("DMA = %+v", dump.DMA) // Report it
+	}
+	if want := []Toggle{{Name: "RX_PARALLEL_PWM", Line: 14}, {Name: "TELEMETRY", Enabled: true, Line: 15}}; !slices.Equal(dump.Features, want) { // "-" disables
+		t.Errorf("Features = %+v, want %+v", dump.Features, want) // Report it
+	}
+	if want := []SerialPort{{Identifier: 0, Functions: 64, MSPBaud: 115200, GPSBaud: 57600, BlackboxBaud: 115200, Line: 16}}; !slices.Equal(dump.Serial, want) { // Serial port
+		t.Errorf("Serial = %+v, want %+v", dump.Serial, want) // Report it
+	}
+	if dump.Map != "TAER1234" || dump.Mixer != "QUADX" { // Uppercased values
+		t.Errorf("Map, Mixer = %q, %q", dump.Map, dump.Mixer) // Report it
+	}
+	if len(dump.LEDs) != 1 || len(dump.Colors) != 1 || len(dump.ModeColors) != 1 || len(dump.Aux) != 1 || len(dump.RxRanges) != 1 || len(dump.VTX) != 1 { // One line each
+		t.Errorf("LEDs %d, Colors %d, ModeColors %d, Aux %d, RxRanges %d, VTX %d", len(dump.LEDs), len(dump.Colors), len(dump.ModeColors), len(dump.Aux), len(dump.RxRanges), len(dump.VTX)) // Report it
+	}
+	if dump.Setting("gyro_lpf1_static_hz") != "250" || len(dump.Master) != 1 { // Master settings only
+		t.Errorf("Master = %+v", dump.Master) // Report it
+	}
+	if len(dump.Profiles) != 1 || dump.Profiles[0].Settings[0] != (Setting{Name: "p_pitch", Value: "47", Line: 27}) { // Spaces around "=" are optional
+		t.Errorf("Profiles = %+v", dump.Profiles) // Report it
+	}
+	if len(dump.RateProfiles) != 1 || dump.RateProfiles[0].Settings[0].Name != "roll_rc_rate" { // Rate profile settings
+		t.Errorf("RateProfiles = %+v", dump.RateProfiles) // Report it
+	}
+	if len(dump.Commands) != 24 || dump.Commands[len(dump.Commands)-1].Name != "save" { // Every command in order
+		t.Errorf("%d commands, last %+v", len(dump.Commands), dump.Commands[len(dump.Commands)-1]) // Report it
+	}
+} // End of TestParseSections function
+
+// Checks that selecting a profile a second time continues it instead of adding another
+func TestParseProfileReselection(t *testing.T) { // Test of profile selection
+	dump := mustParse(t, "profile 0", "set p_roll = 40", "profile 1", "set p_roll = 50", "profile 0", "set i_roll = 80") // Dumps end by restoring the active profile
+	if len(dump.Profiles) != 2 || len(dump.Profiles[0].Settings) != 2 || len(dump.Profiles[1].Settings) != 1 {           // Two profiles, the first with both settings
+		t.Errorf("Profiles = %+v", dump.Profiles) // Report it
+	}
+} // End of TestParseProfileReselection function
+
+// Checks that rejected lines are reported in order while the good lines are still parsed
+func TestParseErrors(t *testing.T) { // Test of line errors
+	dump, err := Parse(strings.NewReader("resource MOTOR x B04\nfrobnicate 1\nset\nserial 0 64 115200\nmixer QUADX\n")) // Four bad lines before a good one
+	var lineErrors ErrorList                                                                                            // Expected error type
+	if !errors.As(err, &lineErrors) {                                                                                   // Not a line error list
+		t.Fatalf("Parse error = %v, want an ErrorList", err) // Stop the test
+	}
+	lines := []int{}                       // Lines reported
+	for _, lineError := range lineErrors { // Collect them
+		lines = append(lines, lineError.Line) // Record it
+	}
+	if want := []int{1, 2, 3, 4}; !slices.Equal(lines, want) { // Every bad line, in order
+		t.Errorf("error lines = %v, want %v (%v)", lines, want, err) // Report it
+	}
+	if dump.Mixer != "QUADX" { // Good lines are still parsed
+		t.Errorf("Mixer = %q, want QUADX", dump.Mixer) // Report it
+	}
+	if len(dump.Commands) != 4 { // The unknown command is not recorded
+		t.Errorf("%d commands, want 4", len(dump.Commands)) // Report it
+	}
+	if !strings.Contains(err.Error(), "and 3 more errors") { // Summary of the list
+		t.Errorf("Error() = %q", err.Error()) // Report it
+	}
+} // End of TestParseErrors function
+
+// Checks that a byte order mark and CRLF line endings are recorded and stripped
+func TestParseEncoding(t *testing.T) { // Test of encoding detection
+	dump, err := Parse(strings.NewReader("\ufeffmixer QUADX\r\nmap TAER1234\r\nsave")) // Windows editor output
+	if err != nil {                                                                    // The CLI accepts all of it
+		t.Fatalf("Parse: %v", err) // Stop the test
+	}
+	if want := (Encoding{Lines: 3, BOM: true, CRLFLines: 2}); dump.Encoding != want { // No final newline
+		t.Errorf("Encoding = %+v, want %+v", dump.Encoding, want) // Report it
+	}
+	if dump.Mixer != "QUADX" || dump.Map != "TAER1234" { // Values without the BOM and CR
+		t.Errorf("Mixer, Map = %q, %q", dump.Mixer, dump.Map) // Report it
+	}
+} // End of TestParseEncoding function
+
+// Checks that commands after save or batch end are listed as ignored and build no typed form
+func TestParseIgnoresCommandsAfterEnd(t *testing.T) { // Test of the end-of-input gate
+	dump := mustParse(t,
+		"batch start",
+		"mixer QUADX",
+		"batch end",
+		"save",
+		"mixer HEX6X",
+		"vtxtable bands 1",
+		"feature GPS",
+	) // End of dump
+	if dump.Mixer != "QUADX" || dump.VTXTable != nil || len(dump.Features) != 0 { // The model stops at the end
+		t.Errorf("Mixer %q, VTXTable %+v, Features %+v", dump.Mixer, dump.VTXTable, dump.Features) // Report it
+	}
+	lines := []int{}                       // Ignored lines
+	for _, command := range dump.Ignored { // Collect them
+		lines = append(lines, command.Line) // Record it
+	}
+	if want := []int{5, 6, 7}; !slices.Equal(lines, want) { // The save after batch end is not ignored
+		t.Errorf("Ignored lines = %v, want %v", lines, want) // Report it
+	}
+	if len(dump.Commands) != 7 { // Every command is still listed
+		t.Errorf("%d commands, want 7", len(dump.Commands)) // Report it
+	}
+} // End of TestParseIgnoresCommandsAfterEnd function
+
+// Checks that command names are matched case-insensitively
+func TestParseCaseInsensitiveCommands(t *testing.T) { // Test of command name matching
+	dump := mustParse(t, "MIXER quadx", "Feature gps", "SAVE")                                                                 // Hand-typed snippet
+	if dump.Mixer != "QUADX" || len(dump.Features) != 1 || dump.Features[0].Name != "GPS" || dump.Commands[2].Name != "save" { // Names are lowercased, values uppercased
+		t.Errorf("Mixer %q, Features %+v, Commands %+v", dump.Mixer, dump.Features, dump.Commands) // Report it
+	}
+} // End of TestParseCaseInsensitiveCommands function
@@ -0,0 +1,198 @@
+package betaflight
+
+import (
+	"fmt"     // Formats finding messages
+	"regexp"  // Matches bus settings
+	"strconv" // Parses bus numbers
+	"strings" // Joins claim descriptions
+)
+
+// How serious a validator finding is
+type Severity string
+
+// Finding severities
+const (
+	SeverityError   Severity = "error"   // Both sides are in use; one of them cannot work
+	SeverityWarning Severity = "warning" // A conflict that bites as soon as both sides are enabled
+	SeverityInfo    Severity = "info"    // A deliberate alternative (e.g. PPM on a UART RX pin) that is currently unused
+) // End of severities
+
+// One problem found by a validator, with the lines it comes from
+type Finding struct {
+	Severity Severity `json:"severity"` // How serious it is
+	Code     string   `json:"code"`     // Kind of problem (e.g. "pin_conflict")
+	Message  string   `json:"message"`  // Description
+	Lines    []int    `json:"lines"`    // Lines involved
+} // End of Finding struct
+
+// Whether a resource is actually used by the configuration in the dump
+type usage int
+
+// Usage states, from least to most active
+const (
+	usageUnused  usage = iota // Configured off (feature disabled, no serial function, motor beyond the mixer)
+	usageUnknown              // The dump does not say
+	usageUsed                 // Configured on
+) // End of usage states
+
+// Motors driven by common mixers; other mixers leave motor usage unknown
+var mixerMotorCounts = map[string]int{"TRI": 3, "QUADP": 4, "QUADX": 4, "BICOPTER": 2, "HEX6": 6, "HEX6X": 6, "HEX6H": 6, "Y6": 6, "Y4": 4, "VTAIL4": 4, "ATAIL4": 4, "OCTOX8": 8, "OCTOFLATP": 8, "OCTOFLATX": 8} // Mixer → motors
+
+// Features that gate a resource function
+var resourceFeatures = map[string]string{"LED_STRIP": "LED_STRIP", "PPM": "RX_PPM", "PWM": "RX_PARALLEL_PWM", "SONAR_TRIGGER": "RANGEFINDER", "SONAR_ECHO": "RANGEFINDER", "ESCSERIAL": "ESC_SENSOR", "TRANSPONDER": "TRANSPONDER"} // Function → feature
+
+// "baro_i2c_device", "gyro_1_spibus", "max7456_spi_bus", ...: the device and which bus type it selects
+var busSettingPattern = regexp.MustCompile(`^(\w+?)_(i2c_device|i2cbus|i2c_bus|spi_device|spibus|spi_bus)$`)
+
+// Devices whose bus setting only matters when something else enables them
+var busDeviceGates = map[string]func(dump *Dump, features map[string]bool) bool{ // Device → whether it is active
+	"dashboard": func(dump *Dump, features map[string]bool) bool { return features["DISPLAY"] }, // OLED dashboard (FEATURE_DASHBOARD is named DISPLAY in the CLI)
+	"rx":        func(dump *Dump, features map[string]bool) bool { return features["RX_SPI"] },  // SPI receiver
+	"sdcard": func(dump *Dump, features map[string]bool) bool {
+		return strings.EqualFold(dump.Setting("sdcard_mode"), "SPI")
+	}, // SPI SD card
+} // End of busDeviceGates map
+
+const softSerialResourceIndex = 11 // resource SERIAL_TX 11 is SOFTSERIAL1
+const softSerialIdentifier = 30    // serial 30 is SOFTSERIAL1
+
+// Groups the assigned resources by pin
+func (dump *Dump) PinMap() map[string][]Resource { // Method to build the pin → functions map
+	pins := map[string][]Resource{}           // Claims per pin
+	for _, resource := range dump.Resources { // Check every resource line
+		if resource.Pin != "" { // Assigned
+			pins[resource.Pin] = append(pins[resource.Pin], resource) // Record the claim
+		}
+	}
+	return pins // Return the map
+} // End of PinMap method
+
+// Flags pins claimed by several resources. Both claimants in use is an error, a claimant known to be unused makes it
+// informational (a designed alternative), and anything else is a warning.
+func CheckPins(dump *Dump) []Finding { // Function to validate resource pins
+	pins := dump.PinMap()                  // Claims per pin
+	features := dump.FeatureStates()       // Effective feature set
+	var findings []Finding                 // Results
+	for _, pin := range SortedKeys(pins) { // Check every pin
+		claims := pins[pin]  // Resources on the pin
+		if len(claims) < 2 { // Only one claimant
+			continue // No conflict
+		}
+		used, unused := 0, 0                        // Claimants by usage
+		descriptions := make([]string, len(claims)) // "SERIAL_TX 3 (in use)"
+		lines := make([]int, len(claims))           // Lines of the claims
+		for index, claim := range claims {          // Describe every claimant
+			state, reason := dump.resourceUsage(claim, features) // Whether it is in use
+			switch state {                                       // Count it
+			case usageUsed: // Configured on
+				used++ // Count it
+			case usageUnused: // Configured off
+				unused++ // Count it
+			}
+			descriptions[index] = fmt.Sprintf("%s %d (%s)", claim.Function, claim.Index, reason) // Describe it
+			lines[index] = claim.Line                                                            // Reference its line
+		}
+		findings = append(findings, Finding{Severity: severityFor(used, unused, len(claims)), Code: "pin_conflict", Message: fmt.Sprintf("pin %s is claimed by %s", pin, strings.Join(descriptions, ", ")), Lines: lines}) // Record it
+	}
+	return findings // Return the findings
+} // End of CheckPins function
+
+// Decides whether a resource is in use, with a short reason
+func (dump *Dump) resourceUsage(resource Resource, features map[string]bool) (usage, string) { // Method to judge a resource
+	if feature, gated := resourceFeatures[resource.Function]; gated { // Feature-gated function
+		if features[feature] { // Feature on
+			return usageUsed, "feature " + feature + " on" // In use
+		}
+		return usageUnused, "feature " + feature + " off" // Unused
+	}
+	switch resource.Function { // Functions with other evidence
+	case "MOTOR": // Motor output
+		motors, known := mixerMotorCounts[dump.Mixer] // Motors the mixer drives
+		switch {                                      // Compare with the motor index
+		case dump.Mixer == "": // No mixer line
+			return usageUnknown, "no mixer line" // Cannot tell
+		case !known: // Unknown or custom mixer
+			return usageUnknown, "mixer " + dump.Mixer // Cannot tell
+		case resource.Index <= motors: // Driven by the mixer
+			return usageUsed, "motor of " + dump.Mixer // In use
+		}
+		return usageUnused, "unused by " + dump.Mixer // Beyond the mixer's motors
+	case "SERIAL_TX", "SERIAL_RX": // UART or soft serial pin
+		identifier := resource.Index - 1               // serial 0 is UART1
+		if resource.Index >= softSerialResourceIndex { // Soft serial
+			identifier = softSerialIdentifier + resource.Index - softSerialResourceIndex // serial 30 is SOFTSERIAL1
+			if !features["SOFTSERIAL"] {                                                 // Soft serial disabled
+				return usageUnused, "feature SOFTSERIAL off" // Unused
+			}
+		}
+		for _, port := range dump.Serial { // Find the port's serial line
+			if port.Identifier != identifier { // Another port
+				continue // Keep looking
+			}
+			if port.Functions == 0 { // Nothing assigned to the port
+				return usageUnused, "no serial function" // Unused
+			}
+			return usageUsed, fmt.Sprintf("serial functions %d", port.Functions) // In use
+		}
+		return usageUnknown, "no serial line" // The dump does not say
+	case "ADC_RSSI": // Analog RSSI input
+		return dump.settingUsage("rssi_source", "ADC") // Used when RSSI is read from the ADC
+	case "SDCARD_CS": // SD card chip select
+		return dump.settingUsage("sdcard_mode", "SPI") // Used for SPI SD cards
+	case "I2C_SCL", "I2C_SDA": // I2C bus pin
+		return dump.busUsage("i2c", resource.Index, features) // Used when a device is on the bus
+	case "SPI_SCK", "SPI_SDI", "SPI_SDO", "SPI_MISO", "SPI_MOSI": // SPI bus pin
+		return dump.busUsage("spi", resource.Index, features) // Used when a device is on the bus
+	}
+	return usageUnknown, "usage unknown" // No evidence either way
+} // End of resourceUsage method
+
+// Decides whether I2C or SPI bus n has a device on it, from the device bus settings
+func (dump *Dump) busUsage(busType string, bus int, features map[string]bool) (usage, string) { // Method to judge a bus
+	found := false                        // Whether the dump has bus settings at all
+	for _, setting := range dump.Master { // Check every master setting
+		match := busSettingPattern.FindStringSubmatch(setting.Name) // Device bus setting
+		if match == nil || !strings.HasPrefix(match[2], busType) {  // Another setting or bus type
+			continue // Next setting
+		}
+		found = true                              // The dump names bus devices
+		value, err := strconv.Atoi(setting.Value) // Selected bus (0 = none)
+		if err != nil || value != bus {           // Another bus
+			continue // Next setting
+		}
+		device := match[1]                                                         // Device name (e.g. "baro")
+		if gate, gated := busDeviceGates[device]; gated && !gate(dump, features) { // Device not enabled
+			continue // Next setting
+		}
+		if strings.EqualFold(dump.Setting(device+"_hardware"), "NONE") { // Device disabled
+			continue // Next setting
+		}
+		return usageUsed, fmt.Sprintf("%s on %s%d", device, strings.ToUpper(busType), bus) // In use
+	}
+	if !found { // A diff or snippet without bus settings
+		return usageUnknown, "no bus settings" // Cannot tell
+	}
+	return usageUnused, fmt.Sprintf("no device on %s%d", strings.ToUpper(busType), bus) // Unused
+} // End of busUsage method
+
+// Decides usage from a master setting that must have a given value
+func (dump *Dump) settingUsage(name, value string) (usage, string) { // Method to judge a setting-gated resource
+	current := dump.Setting(name) // Current value
+	switch {                      // Compare it
+	case current == "": // Not in the dump
+		return usageUnknown, "no " + name + " setting" // Cannot tell
+	case strings.EqualFold(current, value): // Enabled
+		return usageUsed, name + " = " + current // In use
+	}
+	return usageUnused, name + " = " + current // Unused
+} // End of settingUsage method
+
+// Returns the value of a master setting, or "" when the dump does not set it
+func (dump *Dump) Setting(name string) string { // Method to look up a setting
+	for _, setting := range dump.Master { // Check every master setting
+		if setting.Name == name { // Found it
+			return setting.Value // Return its value
+		}
+	}
+	return "" // Not set
+} // End of Setting method
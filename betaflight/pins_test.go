@@ -0,0 +1,41 @@
+package betaflight
+
+import "testing" // Test framework
+
+// Checks that a pin claimed twice is reported, graded by whether the claimants are in use, and distinct pins are not
+func TestCheckPins(t *testing.T) { // Test of pin_conflict
+	tests := []struct {
+		name     string   // Case name
+		lines    []string // Dump lines
+		count    int      // Expected pin_conflict findings
+		severity Severity // Severity of the finding, if any
+	}{
+		{name: "distinct pins", lines: []string{"resource MOTOR 1 B04", "resource LED_STRIP 1 A08"}, count: 0},                                                                     // No conflict
+		{name: "both in use", lines: []string{"mixer QUADX", "resource MOTOR 1 B04", "resource LED_STRIP 1 B04", "feature LED_STRIP"}, count: 1, severity: SeverityError},          // Motor and enabled LED strip
+		{name: "one unused", lines: []string{"mixer QUADX", "resource MOTOR 1 B04", "resource LED_STRIP 1 B04", "feature -LED_STRIP"}, count: 1, severity: SeverityInfo},           // A designed alternative
+		{name: "usage unknown", lines: []string{"resource MOTOR 1 B04", "resource LED_STRIP 1 B04", "feature LED_STRIP"}, count: 1, severity: SeverityWarning},                     // No mixer line
+		{name: "unassigned pins", lines: []string{"resource MOTOR 1 NONE", "resource LED_STRIP 1 NONE"}, count: 0},                                                                 // NONE is not a pin
+		{name: "soft serial off", lines: []string{"resource SERIAL_TX 11 A02", "resource SERIAL_TX 2 A02", "serial 1 64 115200 57600 0 115200"}, count: 1, severity: SeverityInfo}, // SOFTSERIAL1 is unused without the feature
+	} // End of cases
+	for _, test := range tests { // Run every case
+		t.Run(test.name, func(t *testing.T) { // One subtest per case
+			findings := CheckPins(mustParse(t, test.lines...))                                // Run the check
+			expectCode(t, findings, "pin_conflict", test.count)                               // Count the findings
+			if test.count > 0 && len(findings) > 0 && findings[0].Severity != test.severity { // Check the grading
+				t.Errorf("severity = %s, want %s", findings[0].Severity, test.severity) // Report it
+			}
+		}) // End of subtest
+	}
+} // End of TestCheckPins function
+
+// Checks that bus pins are judged by the devices configured on the bus
+func TestCheckPinsBusUsage(t *testing.T) { // Test of bus usage
+	lines := []string{"resource I2C_SCL 1 B08", "resource MOTOR 5 B08", "mixer QUADX", "set baro_i2c_device = 1"} // Barometer on I2C1, motor 5 unused by QUADX
+	findings := CheckPins(mustParse(t, lines...))                                                                 // Run the check
+	if len(findings) != 1 || findings[0].Severity != SeverityInfo {                                               // Only one side is in use
+		t.Fatalf("findings = %+v", findings) // Stop the test
+	}
+	if want := "pin B08 is claimed by I2C_SCL 1 (baro on I2C1), MOTOR 5 (unused by QUADX)"; findings[0].Message != want { // Reasons in the message
+		t.Errorf("message = %q, want %q", findings[0].Message, want) // Report it
+	}
+} // End of TestCheckPinsBusUsage function
@@ -0,0 +1,125 @@
+package betaflight
+
+import (
+	"fmt"     // Formats unknown port names
+	"strconv" // Formats baud rates
+)
+
+// One bit of the serial function mask and the first release that has it
+type serialFunction struct {
+	Bit   uint   // Bit position in the mask
+	Name  string // Function name (FUNCTION_ prefix dropped)
+	Major int    // First major version with the bit
+	Minor int    // First minor version with the bit
+} // End of serialFunction struct
+
+// Serial function bits (serialPortFunction_e in src/main/io/serial.h); bit 8 has been unused since 4.0
+var serialFunctions = []serialFunction{
+	{Bit: 0, Name: "MSP", Major: 4, Minor: 0},                 // MSP
+	{Bit: 1, Name: "GPS", Major: 4, Minor: 0},                 // GPS
+	{Bit: 2, Name: "TELEMETRY_FRSKY_HUB", Major: 4, Minor: 0}, // FrSky hub telemetry
+	{Bit: 3, Name: "TELEMETRY_HOTT", Major: 4, Minor: 0},      // HoTT telemetry
+	{Bit: 4, Name: "TELEMETRY_LTM", Major: 4, Minor: 0},       // LTM telemetry
+	{Bit: 5, Name: "TELEMETRY_SMARTPORT", Major: 4, Minor: 0}, // SmartPort telemetry
+	{Bit: 6, Name: "RX_SERIAL", Major: 4, Minor: 0},           // Serial receiver
+	{Bit: 7, Name: "BLACKBOX", Major: 4, Minor: 0},            // Blackbox logging
+	{Bit: 9, Name: "TELEMETRY_MAVLINK", Major: 4, Minor: 0},   // MAVLink telemetry
+	{Bit: 10, Name: "ESC_SENSOR", Major: 4, Minor: 0},         // ESC telemetry
+	{Bit: 11, Name: "VTX_SMARTAUDIO", Major: 4, Minor: 0},     // TBS SmartAudio
+	{Bit: 12, Name: "TELEMETRY_IBUS", Major: 4, Minor: 0},     // iBUS telemetry
+	{Bit: 13, Name: "VTX_TRAMP", Major: 4, Minor: 0},          // IRC Tramp
+	{Bit: 14, Name: "RCDEVICE", Major: 4, Minor: 0},           // RunCam device protocol
+	{Bit: 15, Name: "LIDAR_TF", Major: 4, Minor: 0},           // Benewake TF lidar
+	{Bit: 16, Name: "FRSKY_OSD", Major: 4, Minor: 1},          // FrSky OSD
+	{Bit: 17, Name: "MSP_DISPLAYPORT", Major: 4, Minor: 3},    // FUNCTION_VTX_MSP: MSP VTX control and DisplayPort OSD (DJI, HDZero, Walksnail)
+} // End of serialFunctions
+
+// Baud rates the CLI prints, indexed like baudRates[] in the firmware; 0 is AUTO
+var baudRates = []int{0, 9600, 19200, 38400, 57600, 115200, 230400, 250000, 400000, 460800, 500000, 921600, 1000000, 1500000, 2000000, 2470000} // Index → rate
+
+// A baud rate setting of a serial port
+type Baud struct {
+	Index int    `json:"index"` // Index in the firmware baud table (-1 when the rate is not in it)
+	Rate  int    `json:"rate"`  // Rate in bit/s (0 = AUTO)
+	Label string `json:"label"` // "AUTO" or the rate
+} // End of Baud struct
+
+// A decoded serial line
+type UART struct {
+	Identifier       int      `json:"identifier"`                  // Port identifier from the serial line
+	Port             string   `json:"port"`                        // Port name (e.g. "UART1", "USB VCP", "SOFTSERIAL1")
+	Functions        []string `json:"functions"`                   // Function names, lowest bit first
+	UnknownFunctions uint32   `json:"unknown_functions,omitempty"` // Bits the dump's firmware release does not define
+	MSPBaud          Baud     `json:"msp_baud"`                    // MSP baud rate
+	GPSBaud          Baud     `json:"gps_baud"`                    // GPS baud rate
+	TelemetryBaud    Baud     `json:"telemetry_baud"`              // Telemetry baud rate
+	BlackboxBaud     Baud     `json:"blackbox_baud"`               // Blackbox baud rate
+	Line             int      `json:"line"`                        // Line number
+} // End of UART struct
+
+// Names a serial port identifier (serialPortIdentifier_e)
+func PortName(identifier int) string { // Function to name a serial port
+	switch { // Identifier ranges
+	case identifier >= 0 && identifier < 10: // Hardware UARTs
+		return fmt.Sprintf("UART%d", identifier+1) // serial 0 is UART1
+	case identifier == 20: // USB
+		return "USB VCP" // Virtual COM port
+	case identifier >= softSerialIdentifier && identifier < softSerialIdentifier+10: // Soft serial
+		return fmt.Sprintf("SOFTSERIAL%d", identifier-softSerialIdentifier+1) // serial 30 is SOFTSERIAL1
+	case identifier >= 40 && identifier < 50: // Low-power UARTs (4.5 and later)
+		return fmt.Sprintf("LPUART%d", identifier-39) // serial 40 is LPUART1
+	}
+	return fmt.Sprintf("PORT%d", identifier) // Unknown identifier
+} // End of PortName function
+
+// Names the bits of a function mask for a firmware release (nil build = the latest release); returns the bits the
+// release does not define separately
+func SerialFunctionNames(build *Build, mask uint32) ([]string, uint32) { // Function to decode a function mask
+	names := []string{}                        // Known functions
+	for _, function := range serialFunctions { // Check every defined bit
+		bit := uint32(1) << function.Bit // Mask of the bit
+		if mask&bit == 0 {               // Not set
+			continue // Next bit
+		}
+		if build != nil && !build.AtLeast(function.Major, function.Minor) { // Newer than the firmware
+			continue // Left in the unknown bits
+		}
+		names = append(names, function.Name) // Record it
+		mask &^= bit                         // Clear it
+	}
+	return names, mask // Names and leftover bits
+} // End of SerialFunctionNames function
+
+// Resolves a baud rate printed by the CLI to its index in the firmware baud table
+func ResolveBaud(rate int) Baud { // Function to resolve a baud rate
+	label := strconv.Itoa(rate) // Printed rate
+	if rate == 0 {              // Automatic
+		label = "AUTO" // Firmware name
+	}
+	for index, known := range baudRates { // Find the rate
+		if known == rate { // Found it
+			return Baud{Index: index, Rate: rate, Label: label} // Return it
+		}
+	}
+	return Baud{Index: -1, Rate: rate, Label: label} // Not a firmware baud rate
+} // End of ResolveBaud function
+
+// Decodes the serial lines of the dump into named ports, functions and baud rates
+func (dump *Dump) UARTs() []UART { // Method to build the UART map
+	uarts := make([]UART, 0, len(dump.Serial)) // Decoded ports
+	for _, port := range dump.Serial {         // Decode every serial line
+		names, unknown := SerialFunctionNames(dump.Build, port.Functions) // Function names for this release
+		uarts = append(uarts, UART{
+			Identifier:       port.Identifier,                 // Identifier
+			Port:             PortName(port.Identifier),       // Port name
+			Functions:        names,                           // Functions
+			UnknownFunctions: unknown,                         // Undefined bits
+			MSPBaud:          ResolveBaud(port.MSPBaud),       // MSP baud rate
+			GPSBaud:          ResolveBaud(port.GPSBaud),       // GPS baud rate
+			TelemetryBaud:    ResolveBaud(port.TelemetryBaud), // Telemetry baud rate
+			BlackboxBaud:     ResolveBaud(port.BlackboxBaud),  // Blackbox baud rate
+			Line:             port.Line,                       // Line number
+		}) // End of UART
+	}
+	return uarts // Return the ports
+} // End of UARTs method
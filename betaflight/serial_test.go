@@ -0,0 +1,65 @@
+package betaflight
+
+import (
+	"slices"  // Compares function lists
+	"testing" // Test framework
+)
+
+// Checks the port names of every identifier range
+func TestPortName(t *testing.T) { // Test of port naming
+	for identifier, want := range map[int]string{0: "UART1", 7: "UART8", 20: "USB VCP", 30: "SOFTSERIAL1", 31: "SOFTSERIAL2", 40: "LPUART1", 12: "PORT12"} { // Every range and an unknown identifier
+		if got := PortName(identifier); got != want { // Wrong name
+			t.Errorf("PortName(%d) = %q, want %q", identifier, got, want) // Report it
+		}
+	}
+} // End of TestPortName function
+
+// Checks that function bits are named for the release that printed them, leaving newer bits unknown
+func TestSerialFunctionNames(t *testing.T) { // Test of function mask decoding
+	mask := uint32(1<<0 | 1<<6 | 1<<17)                                                                      // MSP, RX_SERIAL and MSP_DISPLAYPORT
+	names, unknown := SerialFunctionNames(nil, mask)                                                         // Latest release
+	if want := []string{"MSP", "RX_SERIAL", "MSP_DISPLAYPORT"}; !slices.Equal(names, want) || unknown != 0 { // Every bit is known
+		t.Errorf("latest = %v, %#x; want %v, 0", names, unknown, want) // Report it
+	}
+	build := &Build{Major: 4, Minor: 2}                                                       // DisplayPort over MSP arrived in 4.3
+	names, unknown = SerialFunctionNames(build, mask)                                         // 4.2 release
+	if want := []string{"MSP", "RX_SERIAL"}; !slices.Equal(names, want) || unknown != 1<<17 { // Bit 17 is left over
+		t.Errorf("4.2 = %v, %#x; want %v, %#x", names, unknown, want, 1<<17) // Report it
+	}
+	if names, unknown := SerialFunctionNames(nil, 1<<8); len(names) != 0 || unknown != 1<<8 { // Bit 8 has been unused since 4.0
+		t.Errorf("bit 8 = %v, %#x", names, unknown) // Report it
+	}
+} // End of TestSerialFunctionNames function
+
+// Checks that baud rates resolve to their firmware table index, AUTO included, and others to -1
+func TestResolveBaud(t *testing.T) { // Test of baud rate resolution
+	tests := []struct {
+		rate int  // Printed rate
+		want Baud // Resolved rate
+	}{
+		{rate: 0, want: Baud{Index: 0, Rate: 0, Label: "AUTO"}},                 // Automatic
+		{rate: 115200, want: Baud{Index: 5, Rate: 115200, Label: "115200"}},     // Common rate
+		{rate: 2470000, want: Baud{Index: 15, Rate: 2470000, Label: "2470000"}}, // Last entry
+		{rate: 76800, want: Baud{Index: -1, Rate: 76800, Label: "76800"}},       // Not in the table
+	} // End of cases
+	for _, test := range tests { // Run every case
+		if got := ResolveBaud(test.rate); got != test.want { // Wrong resolution
+			t.Errorf("ResolveBaud(%d) = %+v, want %+v", test.rate, got, test.want) // Report it
+		}
+	}
+} // End of TestResolveBaud function
+
+// Checks that serial lines decode into named ports with their functions and baud rates
+func TestUARTs(t *testing.T) { // Test of the UART map
+	dump := mustParse(t, "# Betaflight / STM32F405 (S405) 4.2.11 Nov  9 2021 / 20:29:32 (948ba6339) MSP API: 1.43", "serial 20 1 115200 57600 0 115200", "serial 2 131072 115200 57600 0 115200") // USB and a DisplayPort UART on 4.2
+	uarts := dump.UARTs()                                                                                                                                                                         // Decode the ports
+	if len(uarts) != 2 {                                                                                                                                                                          // One per serial line
+		t.Fatalf("UARTs = %+v", uarts) // Stop the test
+	}
+	if uarts[0].Port != "USB VCP" || !slices.Equal(uarts[0].Functions, []string{"MSP"}) || uarts[0].MSPBaud.Index != 5 || uarts[0].Line != 2 { // USB with MSP
+		t.Errorf("USB = %+v", uarts[0]) // Report it
+	}
+	if uarts[1].Port != "UART3" || len(uarts[1].Functions) != 0 || uarts[1].UnknownFunctions != 1<<17 { // Bit 17 is unknown to 4.2
+		t.Errorf("UART3 = %+v", uarts[1]) // Report it
+	}
+} // End of TestUARTs function
@@ -0,0 +1,382 @@
+package betaflight
+
+import (
+	"fmt"     // Formats finding messages
+	"slices"  // Finds aliased DMA options
+	"sort"    // Orders timers and streams
+	"strconv" // Parses adc_device
+	"strings" // Matches protocols and channels
+)
+
+// Resource functions driven by a timer channel (soft serial pins are too)
+var timerFunctions = map[string]bool{"MOTOR": true, "SERVO": true, "LED_STRIP": true, "BEEPER": true, "PPM": true, "PWM": true, "CAMERA_CONTROL": true, "TRANSPONDER": true, "ESCSERIAL": true} // Function → timer-driven
+
+// Older names of the SPI DMA options, printed next to the current ones for the same setting
+var spiDMAAliases = map[string]string{"SPI_MOSI": "SPI_SDO", "SPI_TX": "SPI_SDO", "SPI_MISO": "SPI_SDI", "SPI_RX": "SPI_SDI"} // Alias → current name
+
+// How the motor outputs are generated
+type dshotMode int
+
+// DShot modes
+const (
+	dshotNone    dshotMode = iota // Not DShot (PWM, OneShot, ...): no DMA at all
+	dshotUnknown                  // The dump does not say
+	dshotBitbang                  // GPIO bitbanging driven by the firmware's own timers
+	dshotBurst                    // Timer DMA burst: one update DMA per timer
+	dshotTimer                    // One timer channel DMA per motor
+) // End of DShot modes
+
+// One timer channel routed to a pin, with the resources using that pin
+type TimerChannel struct {
+	Timer     string     `json:"timer"`     // Timer (e.g. "TIM3")
+	Channel   string     `json:"channel"`   // Channel (e.g. "CH1", "CH2N")
+	Pin       string     `json:"pin"`       // Pin (e.g. "B04")
+	Resources []Resource `json:"resources"` // Resources assigned to the pin
+	Line      int        `json:"line"`      // Line of the timer command
+} // End of TimerChannel struct
+
+// One user of a DMA stream
+type DMAUser struct {
+	Description string `json:"description"` // "pin B04", "ADC 1", "UART_TX 3"
+	Line        int    `json:"line"`        // Line of the dma command
+	usage       usage  // Whether the user is active
+	reason      string // Why it is judged active or not
+} // End of DMAUser struct
+
+// Groups the assigned timer channels by timer, ordered by channel, using the pin comments that follow each timer line
+func (dump *Dump) TimerMap() map[string][]TimerChannel { // Method to reconstruct timer usage
+	pins := dump.PinMap()                 // Resources per pin
+	timers := map[string][]TimerChannel{} // Channels per timer
+	for _, timer := range dump.Timers {   // Check every timer line
+		if timer.Alternate == "" || timer.Timer == "" { // Unassigned, or no comment to say which timer
+			continue // Nothing to map
+		}
+		timers[timer.Timer] = append(timers[timer.Timer], TimerChannel{Timer: timer.Timer, Channel: timer.Channel, Pin: timer.Pin, Resources: pins[timer.Pin], Line: timer.Line}) // Record the channel
+	}
+	for _, channels := range timers { // Resource order says nothing about the timer
+		sort.SliceStable(channels, func(i, j int) bool { // Order by channel ("CH1" < "CH1N" < "CH2")
+			return channels[i].Channel < channels[j].Channel // Lower channel first
+		}) // End of sort
+	}
+	return timers // Return the map
+} // End of TimerMap method
+
+// Groups the assigned DMA options by stream ("DMA1 Stream 4"; on G4 the DMAMUX channel plays the stream's role)
+func (dump *Dump) DMAMap() map[string][]DMAUser { // Method to reconstruct DMA stream usage
+	pins := dump.PinMap()             // Resources per pin
+	features := dump.FeatureStates()  // Effective feature set
+	streams := map[string][]DMAUser{} // Users per stream
+	for _, dma := range dump.DMA {    // Check every dma line
+		if dma.Option < 0 || dma.Stream == nil { // Unassigned, or no comment to say which stream
+			continue // Nothing to map
+		}
+		if alias, ok := spiDMAAliases[dma.Peripheral]; ok { // Older name of an SPI DMA option
+			dma.Peripheral = alias // Use the current name
+		}
+		key := fmt.Sprintf("DMA%d Stream %d", dma.Stream.Controller, dma.Stream.Stream)                          // Stream identity
+		if slices.ContainsFunc(streams[key], func(user DMAUser) bool { return user.Description == dma.key() }) { // The same option under its other name
+			continue // Already recorded
+		}
+		user := DMAUser{Description: dma.key(), Line: dma.Line} // Describe the user
+		switch {                                                // Decide whether it is active
+		case dma.Peripheral == "pin": // Timer output DMA
+			user.usage, user.reason = usageUnused, "no timer output on the pin" // Hardware UART pins, spare pads, ...
+			for _, claim := range pins[dma.Pin] {                               // Resources on the pin
+				if !timerFunctions[claim.Function] && !(strings.HasPrefix(claim.Function, "SERIAL_") && claim.Index >= softSerialResourceIndex) { // Not driven by the timer
+					continue // Its DMA is never used
+				}
+				state, reason := dump.timerOutputUsage(claim, features)                // Whether it is active
+				if user.reason == "no timer output on the pin" || state > user.usage { // Keep the most active claim
+					user.usage, user.reason = state, fmt.Sprintf("%s %d, %s", claim.Function, claim.Index, reason) // Record it
+				}
+			}
+		case dma.Peripheral == "ADC": // Analog inputs
+			user.usage, user.reason = dump.adcUsage(dma.Index) // Whether the ADC is the one in use
+		case strings.HasPrefix(dma.Peripheral, "UART_"): // UART DMA
+			user.usage, user.reason = dump.resourceUsage(Resource{Function: "SERIAL" + strings.TrimPrefix(dma.Peripheral, "UART"), Index: dma.Index}, features) // Same as the UART's pins
+		case strings.HasPrefix(dma.Peripheral, "SPI_"): // SPI DMA
+			user.usage, user.reason = dump.busUsage("spi", dma.Index, features) // Same as the bus
+		default: // TIMUP and others
+			user.usage, user.reason = usageUnknown, "usage unknown" // No evidence
+		}
+		streams[key] = append(streams[key], user) // Record the user
+	}
+	return streams // Return the map
+} // End of DMAMap method
+
+// Decides how the motors are driven: dshot_bitbang ON always bitbangs, AUTO bitbangs when bidirectional DShot is on,
+// and otherwise dshot_burst ON uses timer update DMA while OFF and AUTO use channel DMA where the pin has one
+func (dump *Dump) dshotMode() dshotMode { // Method to find the DShot mode
+	protocol := strings.ToUpper(dump.Setting("motor_pwm_protocol")) // Motor protocol
+	switch {                                                        // Check the protocol
+	case protocol == "": // Not in the dump
+		return dshotUnknown // Cannot tell
+	case !strings.HasPrefix(protocol, "DSHOT"): // PWM, OneShot, MultiShot, ProShot
+		return dshotNone // No DShot
+	}
+	bitbang := strings.ToUpper(dump.Setting("dshot_bitbang"))             // Bitbang setting
+	bidirectional := strings.EqualFold(dump.Setting("dshot_bidir"), "ON") // Bidirectional DShot
+	switch {                                                              // Check the bitbang setting
+	case bitbang == "ON", bitbang == "AUTO" && bidirectional: // Bitbanged (firmware before 4.2 has no dshot_bitbang)
+		return dshotBitbang // GPIO bitbang
+	case bitbang == "" && dump.Setting("dshot_burst") == "": // A diff or snippet that does not say
+		return dshotUnknown // Cannot tell
+	case strings.EqualFold(dump.Setting("dshot_burst"), "ON"): // Burst mode
+		return dshotBurst // Timer update DMA
+	}
+	return dshotTimer // Channel DMA
+} // End of dshotMode method
+
+// Decides whether a timer-driven output actually uses its channel's DMA: motors only do with channel-DMA DShot
+func (dump *Dump) timerOutputUsage(resource Resource, features map[string]bool) (usage, string) { // Method to judge a timer output's DMA
+	if resource.Function != "MOTOR" { // LED strip, soft serial, ...
+		return dump.resourceUsage(resource, features) // Same as the output
+	}
+	switch dump.dshotMode() { // How the motors are driven
+	case dshotNone: // No DMA
+		return usageUnused, "motor protocol without DMA" // Unused
+	case dshotBitbang: // Bitbanged
+		return usageUnused, "DShot bitbang" // Unused
+	case dshotBurst: // Timer update DMA
+		return usageUnused, "DShot burst" // Unused
+	case dshotUnknown: // Cannot tell
+		return usageUnknown, "DShot mode unknown" // Unknown
+	}
+	return dump.resourceUsage(resource, features) // Channel DMA is used while the motor is
+} // End of timerOutputUsage method
+
+// Decides whether ADC n is the one reading battery voltage, current and RSSI
+func (dump *Dump) adcUsage(index int) (usage, string) { // Method to judge an ADC
+	device, err := strconv.Atoi(dump.Setting("adc_device")) // ADC in use
+	if err != nil {                                         // Not in the dump
+		return usageUnknown, "no adc_device setting" // Cannot tell
+	}
+	if device == index { // The ADC in use
+		return usageUsed, fmt.Sprintf("adc_device = %d", device) // In use
+	}
+	return usageUnused, fmt.Sprintf("adc_device = %d", device) // Another ADC
+} // End of adcUsage method
+
+// Flags motors sharing a timer with LED_STRIP or BEEPER outputs, DMA streams with several users, and, when DShot runs
+// on timers rather than bitbanged GPIO, motors without the timer or DMA resources it needs or, for bidirectional
+// DShot, spread over incompatible timers
+func CheckTimers(dump *Dump) []Finding { // Function to validate timers and DMA
+	features := dump.FeatureStates() // Effective feature set
+	var findings []Finding           // Results
+
+	timers := dump.TimerMap()                  // Channels per timer
+	for _, timer := range SortedKeys(timers) { // Check every timer
+		var motors, others []TimerChannel       // Motor channels and LED/beeper channels
+		for _, channel := range timers[timer] { // Sort the channels
+			for _, resource := range channel.Resources { // Resources on the channel's pin
+				switch resource.Function { // Which kind of output
+				case "MOTOR": // Motor
+					motors = append(motors, channel) // Count it
+				case "LED_STRIP", "BEEPER": // Outputs that reprogram the timer
+					others = append(others, channel) // Count it
+				}
+			}
+		}
+		onOtherPin := func(channel TimerChannel, candidates []TimerChannel) bool { // Pairs on one pin are CheckPins' business
+			return slices.ContainsFunc(candidates, func(candidate TimerChannel) bool { return candidate.Pin != channel.Pin }) // A different pin
+		}
+		sharedMotors := slices.DeleteFunc(slices.Clone(motors), func(motor TimerChannel) bool { return !onOtherPin(motor, others) }) // Motors beside another output
+		others = slices.DeleteFunc(others, func(other TimerChannel) bool { return !onOtherPin(other, motors) })                      // Outputs beside a motor
+		motors = sharedMotors                                                                                                        // Keep the conflicting motors
+		if len(motors) > 0 && len(others) > 0 {                                                                                      // Motors share the timer with another output
+			findings = append(findings, dump.sharedTimerFinding(timer, motors, others, features)) // One finding per timer
+		}
+	}
+
+	streams := dump.DMAMap()                     // Users per stream
+	for _, stream := range SortedKeys(streams) { // Check every stream
+		users := streams[stream] // Its users
+		if len(users) < 2 {      // A single user
+			continue // No collision
+		}
+		used, unused := 0, 0                       // Users by usage
+		descriptions := make([]string, len(users)) // "pin B04 (MOTOR 1, motor of QUADX)"
+		lines := make([]int, len(users))           // Lines of the users
+		for index, user := range users {           // Describe every user
+			switch user.usage { // Count it
+			case usageUsed: // Active
+				used++ // Count it
+			case usageUnused: // Inactive
+				unused++ // Count it
+			}
+			descriptions[index] = fmt.Sprintf("%s (%s)", user.Description, user.reason) // Describe it
+			lines[index] = user.Line                                                    // Reference its line
+		}
+		findings = append(findings, Finding{Severity: severityFor(used, unused, len(users)), Code: "dma_collision", Message: fmt.Sprintf("%s is assigned to %s", stream, strings.Join(descriptions, ", ")), Lines: lines}) // Record it
+	}
+
+	return append(findings, dump.checkDShotTimers(features)...) // Add the DShot checks
+} // End of CheckTimers function
+
+// Describes the motors of a timer sharing it with LED strip or beeper outputs
+func (dump *Dump) sharedTimerFinding(timer string, motors, others []TimerChannel, features map[string]bool) Finding { // Method to build a shared-timer finding
+	used, unused := 0, 0                                                      // Outputs by usage
+	var lines []int                                                           // Resource and timer lines
+	describe := func(channels []TimerChannel, functions ...string) []string { // "MOTOR 1 on B04 CH1 (motor of QUADX)"
+		descriptions := make([]string, len(channels)) // One per channel
+		for index, channel := range channels {        // Describe every channel
+			resource := channelResource(channel, functions...)      // The output on the channel
+			state, reason := dump.resourceUsage(resource, features) // Whether it is active
+			switch state {                                          // Count it
+			case usageUsed: // Active
+				used++ // Count it
+			case usageUnused: // Inactive
+				unused++ // Count it
+			}
+			descriptions[index] = fmt.Sprintf("%s %d on %s %s (%s)", resource.Function, resource.Index, channel.Pin, channel.Channel, reason) // Describe it
+			lines = append(lines, resource.Line, channel.Line)                                                                                // Reference both lines
+		}
+		return descriptions // Return them
+	} // End of describe closure
+	motorDescriptions, otherDescriptions := describe(motors, "MOTOR"), describe(others, "LED_STRIP", "BEEPER") // Both groups
+	slices.Sort(lines)                                                                                         // In dump order
+
+	message := fmt.Sprintf("%s drives %s and %s; the timer runs at one rate, so the outputs disturb each other", timer, strings.Join(motorDescriptions, ", "), strings.Join(otherDescriptions, ", ")) // Describe the conflict
+	return Finding{Severity: severityFor(used, unused, len(motors)+len(others)), Code: "motor_timer_shared", Message: message, Lines: lines}                                                          // One finding for the timer
+} // End of sharedTimerFinding method
+
+// Returns the first resource on a timer channel with one of the given functions
+func channelResource(channel TimerChannel, functions ...string) Resource { // Function to pick a channel's resource
+	for _, resource := range channel.Resources { // Check every resource on the pin
+		for _, function := range functions { // Against every wanted function
+			if resource.Function == function { // Match
+				return resource // Return it
+			}
+		}
+	}
+	return Resource{} // Not found (callers only ask for functions they saw)
+} // End of channelResource function
+
+// With timer-based DShot, every motor needs a timer channel, and a DMA stream of its own for bidirectional DShot or
+// the timer's update DMA for burst mode; bitbanged DShot drives GPIO ports instead
+func (dump *Dump) checkDShotTimers(features map[string]bool) []Finding { // Method to validate DShot timer resources
+	mode := dump.dshotMode()                      // How the motors are driven
+	if mode != dshotTimer && mode != dshotBurst { // Not timer-based DShot
+		return nil // Nothing to check
+	}
+	bidirectional := strings.EqualFold(dump.Setting("dshot_bidir"), "ON") // Bidirectional DShot
+	burst := mode == dshotBurst                                           // Burst (DMAR) mode
+
+	timerOf := map[string]Timer{}       // Timer line per assigned pin
+	for _, timer := range dump.Timers { // Index the timer lines
+		if timer.Alternate != "" { // Assigned
+			timerOf[timer.Pin] = timer // Record it
+		}
+	}
+	dmaOf := map[string]DMA{}       // dma pin line per pin
+	timerUpdate := map[string]DMA{} // dma TIMUP line per timer ("TIM3")
+	for _, dma := range dump.DMA {  // Index the dma lines
+		switch dma.Peripheral { // Which kind
+		case "pin": // Timer channel DMA
+			dmaOf[dma.Pin] = dma // Record it
+		case "TIMUP": // Timer update DMA
+			timerUpdate[fmt.Sprintf("TIM%d", dma.Index)] = dma // Record it
+		}
+	}
+
+	var findings []Finding                    // Results
+	motorTimers := map[string][]int{}         // Resource and timer lines of the driven motors per timer
+	for _, resource := range dump.Resources { // Check every motor
+		if resource.Function != "MOTOR" || resource.Pin == "" { // Not an assigned motor
+			continue // Next resource
+		}
+		if state, _ := dump.resourceUsage(resource, features); state == usageUnused { // Beyond the mixer's motors
+			continue // Not driven
+		}
+		timer, hasTimer := timerOf[resource.Pin] // The motor's timer channel
+		if !hasTimer {                           // No timer on the pin
+			findings = append(findings, Finding{Severity: SeverityError, Code: "motor_without_timer", Message: fmt.Sprintf("MOTOR %d on %s has no timer; timer-based DShot cannot drive it", resource.Index, resource.Pin), Lines: []int{resource.Line}}) // Record it
+			continue                                                                                                                                                                                                                                      // Next motor
+		}
+		lines := []int{resource.Line, timer.Line}                             // Resource and timer lines
+		motorTimers[timer.Timer] = append(motorTimers[timer.Timer], lines...) // Remember the motor's timer
+		switch {                                                              // Check what the mode needs
+		case bidirectional && strings.HasSuffix(timer.Channel, "N"): // Complementary output
+			findings = append(findings, Finding{Severity: SeverityWarning, Code: "dshot_bidir_timer", Message: fmt.Sprintf("MOTOR %d on %s uses complementary channel %s %s; bidirectional DShot reads telemetry by input capture, which complementary outputs cannot do", resource.Index, resource.Pin, timer.Timer, timer.Channel), Lines: lines}) // Record it
+		case burst && len(timerUpdate) > 0: // One update DMA per timer, configurable on this MCU (fixed on F4/F7)
+			if update, ok := timerUpdate[timer.Timer]; !ok || update.Option < 0 { // No update DMA for the motor's timer
+				if ok { // The TIMUP line exists but is NONE
+					lines = append(lines, update.Line) // Reference it
+				}
+				findings = append(findings, Finding{Severity: SeverityError, Code: "dshot_burst_timer", Message: fmt.Sprintf("MOTOR %d on %s uses %s, which has no TIMUP DMA; dshot_burst needs one for every motor timer", resource.Index, resource.Pin, timer.Timer), Lines: lines}) // Record it
+			}
+		case bidirectional && !burst: // One channel DMA per motor
+			if dma, ok := dmaOf[resource.Pin]; !ok || dma.Option < 0 { // No DMA for the motor's channel
+				if ok { // The dma pin line exists but is NONE
+					lines = append(lines, dma.Line) // Reference it
+				}
+				findings = append(findings, Finding{Severity: SeverityError, Code: "dshot_bidir_timer", Message: fmt.Sprintf("MOTOR %d on %s (%s %s) has no DMA stream; timer-based bidirectional DShot needs one per motor", resource.Index, resource.Pin, timer.Timer, timer.Channel), Lines: lines}) // Record it
+			}
+		}
+	}
+	if bidirectional { // The motor set must share one timer clock
+		findings = append(findings, checkMotorTimerMix(motorTimers)...) // Check the set as a whole
+	}
+	return findings // Return the findings
+} // End of checkDShotTimers method
+
+// Timers on the APB2 bus of STM32 and AT32 targets; the others run from APB1, whose timer clock can differ
+var apb2Timers = map[string]bool{"TIM1": true, "TIM8": true, "TIM9": true, "TIM10": true, "TIM11": true, "TIM15": true, "TIM16": true, "TIM17": true, "TIM20": true}
+
+// Advanced-control timers, whose outputs and update events behave differently from general-purpose timers
+var advancedTimers = map[string]bool{"TIM1": true, "TIM8": true, "TIM20": true}
+
+// Bidirectional DShot times every motor's telemetry reply with the same bit period, so a motor set split across
+// timers on different buses, or across advanced and general-purpose timers, decodes badly on some of its motors
+func checkMotorTimerMix(motorTimers map[string][]int) []Finding { // Function to validate the motor set's timers
+	var lines []int                                 // Every motor line
+	var apb1, apb2, advanced, general []string      // Timers by bus and kind
+	for _, timer := range SortedKeys(motorTimers) { // Check every motor timer
+		if apb2Timers[timer] { // Timer bus
+			apb2 = append(apb2, timer) // APB2
+		} else {
+			apb1 = append(apb1, timer) // APB1
+		}
+		if advancedTimers[timer] { // Timer kind
+			advanced = append(advanced, timer) // Advanced-control
+		} else {
+			general = append(general, timer) // General-purpose
+		}
+		lines = append(lines, motorTimers[timer]...) // Reference the motors
+	}
+
+	var reasons []string                // Why the set is mixed
+	if len(apb1) > 0 && len(apb2) > 0 { // Two clock domains
+		reasons = append(reasons, fmt.Sprintf("%s run from APB1 and %s from APB2", strings.Join(apb1, ", "), strings.Join(apb2, ", "))) // Describe it
+	}
+	if len(advanced) > 0 && len(general) > 0 { // Two kinds of timer
+		reasons = append(reasons, fmt.Sprintf("%s are advanced-control and %s general-purpose timers", strings.Join(advanced, ", "), strings.Join(general, ", "))) // Describe it
+	}
+	if len(reasons) == 0 { // A compatible set
+		return nil // Nothing to report
+	}
+	slices.Sort(lines)                                                                                                                                                                                                                                              // In dump order
+	return []Finding{{Severity: SeverityWarning, Code: "dshot_bidir_timer_mix", Message: fmt.Sprintf("The motors span incompatible timers for bidirectional DShot: %s; keep all motors on timers of one bus and kind", strings.Join(reasons, "; ")), Lines: lines}} // Record it
+} // End of checkMotorTimerMix function
+
+// Picks a severity from how many of the parties are in use: two or more is an error, at most one possibly active
+// party is informational, anything else is a warning
+func severityFor(used, unused, total int) Severity { // Function to grade a conflict
+	switch { // Compare the counts
+	case used >= 2: // Two active parties
+		return SeverityError // One of them cannot work
+	case unused >= total-1: // At most one party can be active
+		return SeverityInfo // A latent conflict
+	}
+	return SeverityWarning // Bites once both are enabled
+} // End of severityFor function
+
+// Returns the keys of a map in sorted order
+func SortedKeys[V any](values map[string]V) []string { // Function to iterate maps deterministically
+	keys := make([]string, 0, len(values)) // Collected keys
+	for key := range values {              // Collect every key
+		keys = append(keys, key) // Collect it
+	}
+	sort.Strings(keys) // Stable order
+	return keys        // Return them
+} // End of SortedKeys function
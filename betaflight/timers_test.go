@@ -0,0 +1,124 @@
+package betaflight
+
+import (
+	"slices"  // Compares line lists
+	"strings" // Builds dump variants
+	"testing" // Test framework
+)
+
+// Four motors on TIM3 and TIM4 with channel DMA, driven by timer-based DShot
+var timerDShotLines = []string{
+	"mixer QUADX",
+	"resource MOTOR 1 B00",
+	"resource MOTOR 2 B01",
+	"resource MOTOR 3 B06",
+	"resource MOTOR 4 B07",
+	"timer B00 AF2",
+	"# pin B00: TIM3 CH3 (AF2)",
+	"timer B01 AF2",
+	"# pin B01: TIM3 CH4 (AF2)",
+	"timer B06 AF2",
+	"# pin B06: TIM4 CH1 (AF2)",
+	"timer B07 AF2",
+	"# pin B07: TIM4 CH2 (AF2)",
+	"dma pin B00 0",
+	"# pin B00: DMA1 Stream 7 Channel 5",
+	"dma pin B01 0",
+	"# pin B01: DMA1 Stream 2 Channel 5",
+	"dma pin B06 0",
+	"# pin B06: DMA1 Stream 0 Channel 2",
+	"dma pin B07 0",
+	"# pin B07: DMA1 Stream 3 Channel 2",
+	"set motor_pwm_protocol = DSHOT600",
+	"set dshot_bitbang = OFF",
+	"set dshot_burst = OFF",
+	"set dshot_bidir = OFF",
+} // End of timerDShotLines
+
+// Returns the timer-based DShot dump with replacements (old, new pairs) applied and extra lines appended
+func timerDShotDump(t *testing.T, replacements []string, extra ...string) *Dump { // Helper to build a DShot dump variant
+	t.Helper()                                                                                // Report the caller's line
+	text := strings.NewReplacer(replacements...).Replace(strings.Join(timerDShotLines, "\n")) // Apply the replacements
+	return mustParse(t, append(strings.Split(text, "\n"), extra...)...)                       // Parse the variant
+} // End of timerDShotDump function
+
+// Checks that the base dump is clean, so every other case isolates one problem
+func TestCheckTimersClean(t *testing.T) { // Negative case for every timer code
+	if findings := CheckTimers(timerDShotDump(t, nil)); len(findings) != 0 { // Nothing to report
+		t.Errorf("findings = %+v", findings) // Report it
+	}
+} // End of TestCheckTimersClean function
+
+// Checks that two users of one DMA stream are reported and graded by usage, and users of distinct streams are not
+func TestCheckTimersDMACollision(t *testing.T) { // Test of dma_collision
+	ledStrip := []string{"resource LED_STRIP 1 A08", "feature LED_STRIP", "timer A08 AF1", "# pin A08: TIM1 CH1 (AF1)", "dma pin A08 0", "# pin A08: DMA1 Stream 7 Channel 6"} // LED strip on motor 1's stream
+	findings := CheckTimers(timerDShotDump(t, nil, ledStrip...))                                                                                                               // Run the check
+	expectCode(t, findings, "dma_collision", 1)                                                                                                                                // One stream, one finding
+	if len(findings) == 1 && findings[0].Severity != SeverityError {                                                                                                           // Both users are active
+		t.Errorf("severity = %s, want error", findings[0].Severity) // Report it
+	}
+	findings = CheckTimers(timerDShotDump(t, []string{"DSHOT600", "PWM"}, ledStrip...)) // Without DShot the motors use no DMA
+	if len(findings) != 1 || findings[0].Severity != SeverityInfo {                     // A latent conflict
+		t.Errorf("findings = %+v, want one info", findings) // Report it
+	}
+	ledStrip[5] = "# pin A08: DMA2 Stream 6 Channel 0"          // Move the LED strip to a free stream
+	findings = CheckTimers(timerDShotDump(t, nil, ledStrip...)) // Run the check
+	expectCode(t, findings, "dma_collision", 0)                 // Distinct streams are never reported
+} // End of TestCheckTimersDMACollision function
+
+// Checks that motors sharing a timer with LED strip or beeper outputs give one finding per timer
+func TestCheckTimersMotorTimerShared(t *testing.T) { // Test of motor_timer_shared
+	findings := CheckTimers(timerDShotDump(t, nil, "resource LED_STRIP 1 B08", "resource BEEPER 1 B09", "feature LED_STRIP", "timer B08 AF2", "# pin B08: TIM4 CH3 (AF2)", "timer B09 AF2", "# pin B09: TIM4 CH4 (AF2)")) // Two motors and two outputs on TIM4
+	expectCode(t, findings, "motor_timer_shared", 1)                                                                                                                                                                      // One finding for TIM4
+	if want := []int{4, 5, 10, 12, 26, 27, 29, 31}; len(findings) == 1 && !slices.Equal(findings[0].Lines, want) {                                                                                                        // Every resource and timer line, in order
+		t.Errorf("lines = %v, want %v", findings[0].Lines, want) // Report it
+	}
+
+	findings = CheckTimers(timerDShotDump(t, nil, "resource LED_STRIP 1 A08", "feature LED_STRIP", "timer A08 AF1", "# pin A08: TIM1 CH1 (AF1)")) // LED strip on its own timer
+	expectCode(t, findings, "motor_timer_shared", 0)                                                                                              // No shared timer
+} // End of TestCheckTimersMotorTimerShared function
+
+// Checks that a driven motor without a timer is reported under timer-based DShot only
+func TestCheckTimersMotorWithoutTimer(t *testing.T) { // Test of motor_without_timer
+	noTimer := []string{"resource MOTOR 4 B07", "resource MOTOR 4 C09"}                                  // Motor 4 on a pin without a timer line
+	expectCode(t, CheckTimers(timerDShotDump(t, noTimer)), "motor_without_timer", 1)                     // Reported
+	expectCode(t, CheckTimers(timerDShotDump(t, nil, "resource MOTOR 5 C09")), "motor_without_timer", 0) // Motor 5 is beyond QUADX
+	bitbang := append([]string{"dshot_bitbang = OFF", "dshot_bitbang = ON"}, noTimer...)                 // Bitbanged DShot drives GPIO ports
+	expectCode(t, CheckTimers(timerDShotDump(t, bitbang)), "motor_without_timer", 0)                     // Not timer-based
+} // End of TestCheckTimersMotorWithoutTimer function
+
+// Checks that bidirectional DShot needs a DMA stream per motor and no complementary channels
+func TestCheckTimersDShotBidir(t *testing.T) { // Test of dshot_bidir_timer
+	bidir := []string{"dshot_bidir = OFF", "dshot_bidir = ON"}                                                                // Bidirectional DShot
+	expectCode(t, CheckTimers(timerDShotDump(t, append(bidir, "dma pin B07 0", "dma pin B07 NONE"))), "dshot_bidir_timer", 1) // Motor 4 without DMA
+	expectCode(t, CheckTimers(timerDShotDump(t, append(bidir, "TIM4 CH2 (AF2)", "TIM4 CH2N (AF2)"))), "dshot_bidir_timer", 1) // Complementary output
+	expectCode(t, CheckTimers(timerDShotDump(t, bidir)), "dshot_bidir_timer", 0)                                              // Every motor has a DMA stream
+	expectCode(t, CheckTimers(timerDShotDump(t, []string{"dma pin B07 0", "dma pin B07 NONE"})), "dshot_bidir_timer", 0)      // Without bidir the DMA is not checked per motor
+} // End of TestCheckTimersDShotBidir function
+
+// Checks that burst mode needs a TIMUP DMA for every motor timer where the MCU makes it configurable
+func TestCheckTimersDShotBurst(t *testing.T) { // Test of dshot_burst_timer
+	burst := []string{"dshot_burst = OFF", "dshot_burst = ON"}                                                        // Timer update DMA
+	expectCode(t, CheckTimers(timerDShotDump(t, burst, "dma TIMUP 3 0", "dma TIMUP 4 NONE")), "dshot_burst_timer", 2) // Motors 3 and 4 on TIM4
+	expectCode(t, CheckTimers(timerDShotDump(t, burst, "dma TIMUP 3 0", "dma TIMUP 4 0")), "dshot_burst_timer", 0)    // Both timers have one
+} // End of TestCheckTimersDShotBurst function
+
+// Checks that a bidirectional DShot motor set spread over timers of different buses or kinds is reported once
+func TestCheckTimersDShotBidirMix(t *testing.T) { // Test of dshot_bidir_timer_mix
+	toTIM1 := []string{"timer B06 AF2", "timer B06 AF1", "TIM4 CH1 (AF2)", "TIM1 CH1 (AF1)"}            // Motor 3 on an advanced APB2 timer
+	bidir := []string{"dshot_bidir = OFF", "dshot_bidir = ON"}                                          // Bidirectional DShot
+	expectCode(t, CheckTimers(timerDShotDump(t, append(toTIM1, bidir...))), "dshot_bidir_timer_mix", 1) // TIM1 with TIM3 and TIM4
+	expectCode(t, CheckTimers(timerDShotDump(t, toTIM1)), "dshot_bidir_timer_mix", 0)                   // No telemetry to decode without bidir
+	expectCode(t, CheckTimers(timerDShotDump(t, bidir)), "dshot_bidir_timer_mix", 0)                    // TIM3 and TIM4 are both general-purpose on APB1
+} // End of TestCheckTimersDShotBidirMix function
+
+// Checks that timer channels are grouped by timer and ordered by channel
+func TestTimerMap(t *testing.T) { // Test of the timer map
+	timers := timerDShotDump(t, nil).TimerMap()                                  // Map the base dump
+	if got := SortedKeys(timers); !slices.Equal(got, []string{"TIM3", "TIM4"}) { // Two timers
+		t.Fatalf("timers = %v", got) // Stop the test
+	}
+	if channels := timers["TIM4"]; len(channels) != 2 || channels[0].Channel != "CH1" || channels[0].Resources[0].Index != 3 { // Ordered channels with their resources
+		t.Errorf("TIM4 = %+v", channels) // Report it
+	}
+} // End of TestTimerMap function
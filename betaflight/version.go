@@ -0,0 +1,88 @@
+package betaflight
+
+import (
+	"fmt"     // Formats header errors
+	"regexp"  // Splits the firmware header
+	"strconv" // Parses version numbers
+	"strings" // Matches version patterns
+	"time"    // Parses build dates
+)
+
+// "Betaflight / STM32F7X2 (S7X2) 4.5.1 Jul 30 2024 / 07:54:46 (77d01ba3b) MSP API: 1.46"; everything after the
+// version is missing from some early 4.0 dumps
+var buildHeaderPattern = regexp.MustCompile(`^(\S+) / (\S+) \((\w+)\) (\d+)\.(\d+)\.(\d+)(?: (\w{3} +\d+ \d{4} / \d\d:\d\d:\d\d) \(([0-9a-f]+)\) MSP API: (\d+\.\d+))?$`)
+
+var configRevisionPattern = regexp.MustCompile(`^# config rev: ([0-9a-f]+)$`) // "# config rev: bc5da0e"
+
+// Build metadata from the firmware header of a dump
+type Build struct {
+	Firmware  string    `json:"firmware"`            // Firmware name (e.g. "Betaflight")
+	MCU       string    `json:"mcu"`                 // MCU family for unified targets (e.g. "STM32F7X2"); legacy targets print their board name (e.g. "OMNIBUSF4SD")
+	MCUCode   string    `json:"mcu_code"`            // Short code (e.g. "S7X2")
+	Unified   bool      `json:"unified"`             // Whether this is a unified target (MCU family rather than a board name)
+	Version   string    `json:"version"`             // Semantic version (e.g. "4.5.1")
+	Major     int       `json:"major"`               // Major version
+	Minor     int       `json:"minor"`               // Minor version
+	Patch     int       `json:"patch"`               // Patch version
+	BuildDate time.Time `json:"build_date,omitzero"` // Build date and time (as printed, no time zone)
+	GitHash   string    `json:"git_hash,omitempty"`  // Short firmware commit hash
+	MSPAPI    string    `json:"msp_api,omitempty"`   // MSP API version (e.g. "1.46")
+} // End of Build struct
+
+// Parses a firmware header, with or without the leading "# "
+func ParseBuild(header string) (*Build, error) { // Function to decode the firmware header
+	header = strings.TrimSpace(strings.TrimPrefix(header, "#")) // Accept the comment form
+	match := buildHeaderPattern.FindStringSubmatch(header)      // Split the header
+	if match == nil {                                           // Not a firmware header
+		return nil, fmt.Errorf("unrecognized firmware header %q", header) // Report it
+	}
+	build := &Build{Firmware: match[1], MCU: match[2], MCUCode: match[3], GitHash: match[8], MSPAPI: match[9]} // Text fields
+
+	build.Unified = strings.HasPrefix(build.MCU, "STM32") || strings.HasPrefix(build.MCU, "AT32") || strings.HasPrefix(build.MCU, "APM32") // MCU families start with the vendor prefix
+
+	build.Major, _ = strconv.Atoi(match[4])                                        // Major version
+	build.Minor, _ = strconv.Atoi(match[5])                                        // Minor version
+	build.Patch, _ = strconv.Atoi(match[6])                                        // Patch version
+	build.Version = fmt.Sprintf("%d.%d.%d", build.Major, build.Minor, build.Patch) // Normalized version
+	if match[7] != "" {                                                            // Build date printed
+		date, err := time.Parse("Jan _2 2006 / 15:04:05", match[7]) // Single-digit days are space-padded
+		if err != nil {                                             // Malformed date
+			return nil, fmt.Errorf("invalid build date %q: %w", match[7], err) // Report it
+		}
+		build.BuildDate = date // Record it
+	}
+	return build, nil // Return the build
+} // End of ParseBuild function
+
+// Reports whether the build matches a version pattern: "4.5.1", "4.5", "4.5.x", "4" or "4.x"
+func (build *Build) MatchesVersion(pattern string) bool { // Method to filter builds by version
+	numbers := []int{build.Major, build.Minor, build.Patch}       // Version components
+	parts := strings.Split(strings.TrimPrefix(pattern, "v"), ".") // Pattern components
+	if len(parts) > len(numbers) {                                // Too many components
+		return false // Cannot match
+	}
+	for index, part := range parts { // Compare component by component
+		if part == "x" || part == "*" { // Wildcard
+			continue // Anything matches
+		}
+		value, err := strconv.Atoi(part)           // Expected component
+		if err != nil || value != numbers[index] { // Different (or not a number)
+			return false // No match
+		}
+	}
+	return true // Every given component matched
+} // End of MatchesVersion method
+
+// Reports whether the build is the given release or a later one
+func (build *Build) AtLeast(major, minor int) bool { // Method to gate version-specific tables
+	return build.Major > major || build.Major == major && build.Minor >= minor // Compare major, then minor
+} // End of AtLeast method
+
+// Reports whether a table entry added in major.minor and removed in removedMajor.removedMinor (0 = never) exists in the
+// build; a nil build (no header) accepts every entry
+func (build *Build) Defines(major, minor, removedMajor, removedMinor int) bool { // Method to gate version-specific table entries
+	if build == nil { // Release unknown
+		return true // Accept anything any release defines
+	}
+	return build.AtLeast(major, minor) && (removedMajor == 0 || !build.AtLeast(removedMajor, removedMinor)) // Added and not yet removed
+} // End of Defines method
@@ -0,0 +1,151 @@
+package betaflight
+
+import (
+	"encoding/json" // Encodes configurator files
+	"fmt"           // Formats finding messages
+)
+
+// Firmware limits of a vtxtable (VTX_TABLE_MAX_* in src/main/drivers/vtx_table.h)
+const (
+	vtxTableMaxBands       = 8 // Bands
+	vtxTableMaxChannels    = 8 // Channels per band
+	vtxTableMaxPowerLevels = 8 // Power levels
+	vtxTablePowerLabelSize = 3 // Characters per power label
+) // End of vtxtable limits
+
+// Frequencies a band may use, in MHz; 0 marks an unused channel. The firmware accepts up to 5999 MHz and nothing the
+// shop sells transmits below 1 GHz (1.2 GHz, 3.3 GHz, 4.9 GHz and 5.8 GHz transmitters)
+const (
+	vtxMinFrequency = 1000 // Lowest accepted frequency
+	vtxMaxFrequency = 5999 // VTX_SETTINGS_MAX_FREQUENCY_MHZ
+) // End of frequency range
+
+// Canonical 5.8 GHz bands by letter, with the names tables give them
+var standardVTXBands = map[string]struct {
+	Name        string // Band name in the firmware defaults
+	Frequencies []int  // MHz per channel
+}{
+	"A": {Name: "BOSCAM_A", Frequencies: []int{5865, 5845, 5825, 5805, 5785, 5765, 5745, 5725}}, // Band A
+	"B": {Name: "BOSCAM_B", Frequencies: []int{5733, 5752, 5771, 5790, 5809, 5828, 5847, 5866}}, // Band B
+	"E": {Name: "BOSCAM_E", Frequencies: []int{5705, 5685, 5665, 5645, 5885, 5905, 5925, 5945}}, // Band E
+	"F": {Name: "FATSHARK", Frequencies: []int{5740, 5760, 5780, 5800, 5820, 5840, 5860, 5880}}, // Band F (ImmersionRC / Fat Shark)
+	"R": {Name: "RACEBAND", Frequencies: []int{5658, 5695, 5732, 5769, 5806, 5843, 5880, 5917}}, // Raceband
+} // End of standardVTXBands map
+
+// The VTX config file Betaflight Configurator loads and saves on its Video Transmitter tab
+type ConfiguratorVTXConfig struct {
+	Description string               `json:"description"` // Free text shown nowhere, kept for people
+	Version     string               `json:"version"`     // File format version ("1.0")
+	VTXTable    ConfiguratorVTXTable `json:"vtx_table"`   // The table
+} // End of ConfiguratorVTXConfig struct
+
+// The vtx_table object of a configurator VTX config file
+type ConfiguratorVTXTable struct {
+	BandsList       []ConfiguratorVTXBand       `json:"bands_list"`       // Bands in order
+	PowerLevelsList []ConfiguratorVTXPowerLevel `json:"powerlevels_list"` // Power levels in order
+} // End of ConfiguratorVTXTable struct
+
+// One band of a configurator VTX config file
+type ConfiguratorVTXBand struct {
+	Name          string `json:"name"`            // Band name
+	Letter        string `json:"letter"`          // Band letter
+	IsFactoryBand bool   `json:"is_factory_band"` // FACTORY band
+	Frequencies   []int  `json:"frequencies"`     // MHz per channel
+} // End of ConfiguratorVTXBand struct
+
+// One power level of a configurator VTX config file
+type ConfiguratorVTXPowerLevel struct {
+	Value int    `json:"value"` // Value sent to the VTX
+	Label string `json:"label"` // OSD label
+} // End of ConfiguratorVTXPowerLevel struct
+
+// Converts the table to the configurator's VTX config file format
+func (table *VTXTable) Configurator(description string) *ConfiguratorVTXConfig { // Method to export the table
+	config := &ConfiguratorVTXConfig{Description: description, Version: "1.0"} // File header
+	config.VTXTable.BandsList = []ConfiguratorVTXBand{}                        // Empty lists are written as []
+	config.VTXTable.PowerLevelsList = []ConfiguratorVTXPowerLevel{}            // Empty lists are written as []
+	bands := map[int]VTXBand{}                                                 // Bands by number; like the firmware, a repeated number overwrites
+	highest := 0                                                               // Highest band number
+	for _, band := range table.BandList {                                      // Collect every band
+		bands[band.Number] = band           // Later lines win
+		highest = max(highest, band.Number) // Track the last band
+	}
+	for number := 1; number <= highest; number++ { // Export bands 1 to the highest
+		band, found := bands[number] // Band with this number
+		if !found {                  // Gap in the numbering; the firmware leaves the band empty
+			band = VTXBand{Frequencies: make([]int, table.Channels)} // Unused band
+		}
+		config.VTXTable.BandsList = append(config.VTXTable.BandsList, ConfiguratorVTXBand{Name: band.Name, Letter: band.Letter, IsFactoryBand: band.Factory, Frequencies: band.Frequencies}) // Record it
+	}
+	for index := 0; index < table.PowerLevels; index++ { // Export every declared level
+		level := ConfiguratorVTXPowerLevel{} // Missing values and labels stay empty
+		if index < len(table.PowerValues) {  // Value given
+			level.Value = table.PowerValues[index] // Record it
+		}
+		if index < len(table.PowerLabels) { // Label given
+			level.Label = table.PowerLabels[index] // Record it
+		}
+		config.VTXTable.PowerLevelsList = append(config.VTXTable.PowerLevelsList, level) // Record it
+	}
+	return config // Return the file
+} // End of Configurator method
+
+// Encodes the table as a configurator VTX config file
+func (table *VTXTable) ConfiguratorJSON(description string) ([]byte, error) { // Method to write the export
+	return json.MarshalIndent(table.Configurator(description), "", "    ") // Four-space indent like the configurator
+} // End of ConfiguratorJSON method
+
+// Validates the vtxtable block: declared counts against the lines, frequency range, power values against labels, and
+// standard bands against the canonical frequencies
+func CheckVTXTable(dump *Dump) []Finding { // Function to validate the vtxtable
+	table := dump.VTXTable // Table to check
+	if table == nil {      // No vtxtable lines
+		return nil // Nothing to check
+	}
+	var findings []Finding                                                              // Results
+	add := func(severity Severity, code string, line int, format string, args ...any) { // Records a finding
+		findings = append(findings, Finding{Severity: severity, Code: code, Message: fmt.Sprintf(format, args...), Lines: []int{line}}) // Append it
+	} // End of add function
+
+	if table.Bands != len(table.BandList) { // Declared count differs
+		add(SeverityError, "vtxtable_band_count", table.Line, "vtxtable declares %d bands but has %d band lines", table.Bands, len(table.BandList)) // Report it
+	}
+	if table.Bands > vtxTableMaxBands || table.Channels > vtxTableMaxChannels || table.PowerLevels > vtxTableMaxPowerLevels { // Beyond the firmware
+		add(SeverityError, "vtxtable_limits", table.Line, "vtxtable has %d bands, %d channels and %d power levels; the firmware allows %d, %d and %d", table.Bands, table.Channels, table.PowerLevels, vtxTableMaxBands, vtxTableMaxChannels, vtxTableMaxPowerLevels) // Report it
+	}
+	for index, band := range table.BandList { // Check every band
+		if band.Number != index+1 { // Out of order or duplicated
+			add(SeverityError, "vtxtable_band_number", band.Line, "band line %d sets band %d (%s); bands must be numbered 1 to N in order and a repeated number overwrites the earlier band", index+1, band.Number, band.Name) // Report it
+		}
+		if len(band.Frequencies) != table.Channels { // Channel count differs
+			add(SeverityError, "vtxtable_channel_count", band.Line, "band %s has %d frequencies but the table declares %d channels", band.Name, len(band.Frequencies), table.Channels) // Report it
+		}
+		for channel, frequency := range band.Frequencies { // Check every frequency
+			if frequency != 0 && (frequency < vtxMinFrequency || frequency > vtxMaxFrequency) { // Out of range
+				add(SeverityError, "vtxtable_frequency_range", band.Line, "band %s channel %d is %d MHz, outside %d-%d MHz", band.Name, channel+1, frequency, vtxMinFrequency, vtxMaxFrequency) // Report it
+			}
+		}
+		standard, known := standardVTXBands[band.Letter]                      // Canonical band for the letter
+		if !known || band.Name != standard.Name && band.Name != band.Letter { // Not a standard band (e.g. "BAND_A A" on a 1.2 GHz table)
+			continue // Nothing to compare
+		}
+		for channel, frequency := range band.Frequencies { // Compare every used channel
+			if channel < len(standard.Frequencies) && frequency != 0 && frequency != standard.Frequencies[channel] { // Different frequency
+				add(SeverityWarning, "vtxtable_canonical_band", band.Line, "band %s is %v; the standard %s band is %v", band.Name, band.Frequencies, band.Letter, standard.Frequencies) // Report it
+				break                                                                                                                                                                   // One finding per band
+			}
+		}
+	}
+	if len(table.PowerValues) != table.PowerLevels { // Value count differs
+		add(SeverityError, "vtxtable_power_count", table.Line, "vtxtable declares %d power levels but has %d power values", table.PowerLevels, len(table.PowerValues)) // Report it
+	}
+	if len(table.PowerLabels) != table.PowerLevels { // Label count differs
+		add(SeverityError, "vtxtable_power_count", table.Line, "vtxtable declares %d power levels but has %d power labels", table.PowerLevels, len(table.PowerLabels)) // Report it
+	}
+	for _, label := range table.PowerLabels { // Check every label
+		if len(label) > vtxTablePowerLabelSize { // Too long for the OSD
+			add(SeverityError, "vtxtable_power_label", table.Line, "power label %q is longer than %d characters", label, vtxTablePowerLabelSize) // Report it
+		}
+	}
+	return findings // Return the findings
+} // End of CheckVTXTable function
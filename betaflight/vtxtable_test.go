@@ -0,0 +1,68 @@
+package betaflight
+
+import (
+	"slices"  // Compares band lists
+	"strings" // Builds table variants
+	"testing" // Test framework
+)
+
+// A valid two-band 5.8 GHz table
+var vtxTableLines = []string{
+	"vtxtable bands 2",
+	"vtxtable channels 8",
+	"vtxtable band 1 BOSCAM_A A FACTORY 5865 5845 5825 5805 5785 5765 5745 5725",
+	"vtxtable band 2 RACEBAND R CUSTOM 5658 5695 5732 5769 5806 5843 5880 5917",
+	"vtxtable powerlevels 3",
+	"vtxtable powervalues 25 200 600",
+	"vtxtable powerlabels 25 200 600",
+} // End of vtxTableLines
+
+// Checks every vtxtable code on a variant of the valid table, with a variant that must not be reported where the rule
+// has an edge; the valid table itself is the negative case of every code
+func TestCheckVTXTable(t *testing.T) { // Test of the vtxtable checks
+	tests := []struct {
+		name  string // Case name
+		old   string // Text of the valid table to replace ("" for none)
+		new   string // Replacement
+		code  string // Code under test
+		count int    // Expected findings
+	}{
+		{name: "band count", old: "bands 2", new: "bands 3", code: "vtxtable_band_count", count: 1},                             // One band line missing
+		{name: "limits", old: "powerlevels 3", new: "powerlevels 9", code: "vtxtable_limits", count: 1},                         // Beyond the firmware
+		{name: "band number", old: "band 2 RACEBAND", new: "band 3 RACEBAND", code: "vtxtable_band_number", count: 1},           // Skips band 2
+		{name: "channel count", old: " 5880 5917", new: " 5880", code: "vtxtable_channel_count", count: 1},                      // Raceband channel 8 missing
+		{name: "frequency range", old: "5658 5695", new: "6100 5695", code: "vtxtable_frequency_range", count: 1},               // Above 5999 MHz
+		{name: "unused channel", old: "5658 5695", new: "0 5695", code: "vtxtable_frequency_range", count: 0},                   // 0 marks an unused channel
+		{name: "canonical band", old: "5658 5695", new: "5660 5695", code: "vtxtable_canonical_band", count: 1},                 // Raceband channel 1 is 5658 MHz
+		{name: "custom band", old: "RACEBAND R CUSTOM 5658", new: "LOWRACE L CUSTOM 5362", code: "vtxtable_canonical_band"},     // Not a standard letter
+		{name: "power count", old: "powervalues 25 200 600", new: "powervalues 25 200", code: "vtxtable_power_count", count: 1}, // One value missing
+		{name: "power label", old: "labels 25 200 600", new: "labels 25 200 1W0", code: "vtxtable_power_label", count: 0},       // Three characters fit
+		{name: "long power label", old: "labels 25 200 600", new: "labels 25 200 1000", code: "vtxtable_power_label", count: 1}, // Four do not
+	} // End of cases
+	for _, test := range tests { // Run every case
+		t.Run(test.name, func(t *testing.T) { // One subtest per case
+			text := strings.Replace(strings.Join(vtxTableLines, "\n"), test.old, test.new, 1) // Build the variant
+			findings := CheckVTXTable(mustParse(t, text))                                     // Run the check
+			expectCode(t, findings, test.code, test.count)                                    // Count the findings
+		}) // End of subtest
+	}
+	if findings := CheckVTXTable(mustParse(t, vtxTableLines...)); len(findings) != 0 { // The valid table is clean
+		t.Errorf("valid table findings = %+v", findings) // Report it
+	}
+} // End of TestCheckVTXTable function
+
+// Checks that the configurator export fills numbering gaps with empty bands and keeps declared power levels
+func TestConfigurator(t *testing.T) { // Test of the configurator export
+	text := strings.Replace(strings.Join(vtxTableLines, "\n"), "band 2 RACEBAND", "band 3 RACEBAND", 1)      // Band 2 missing
+	config := mustParse(t, text).VTXTable.Configurator("test")                                               // Export the table
+	bands := config.VTXTable.BandsList                                                                       // Exported bands
+	if len(bands) != 3 || bands[0].Letter != "A" || !bands[0].IsFactoryBand || bands[2].Name != "RACEBAND" { // Bands 1 to 3
+		t.Fatalf("bands = %+v", bands) // Stop the test
+	}
+	if bands[1].Name != "" || !slices.Equal(bands[1].Frequencies, make([]int, 8)) { // The gap is an empty band
+		t.Errorf("band 2 = %+v", bands[1]) // Report it
+	}
+	if levels := config.VTXTable.PowerLevelsList; len(levels) != 3 || levels[2] != (ConfiguratorVTXPowerLevel{Value: 600, Label: "600"}) { // Values with labels
+		t.Errorf("power levels = %+v", levels) // Report it
+	}
+} // End of TestConfigurator function
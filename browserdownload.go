@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"       // Manages the Chrome download session lifetime
+	"errors"        // Creates download errors
+	"fmt"           // Formats download errors
+	"log"           // Logs browser downloads
+	"os"            // Reads the downloaded file and cleans up
+	"path/filepath" // Locates the downloaded file
+	"time"          // Bounds how long a browser download may take
+
+	"github.com/chromedp/cdproto/browser" // Controls Chrome's download behavior and events
+	"github.com/chromedp/chromedp"        // Drives the Chrome browser
+)
+
+var browserFallbackEnabled = true // Whether blocked HTTP downloads are retried through Chrome
+
+var errBlockedByServer = errors.New("blocked by the server") // HTTP failures a real browser may get past (403, 429, 503, challenge pages)
+
+const browserDownloadStartTimeout = time.Minute         // How long Chrome may take to start the download after the click
+const browserDownloadTransferTimeout = 15 * time.Minute // How long the whole browser session may take (matches the HTTP client)
+
+// Outcome of a Chrome download as reported by its progress events
+type browserDownloadResult struct {
+	guid          string // Identifier Chrome used as the file name
+	limitExceeded bool   // Whether the download grew past its size limit
+	err           error  // Failure reported by Chrome, if any
+} // End of browserDownloadResult struct
+
+// Downloads a file with Chrome itself by opening the linking page and clicking a download link,
+// then returns the file contents so the caller can validate and store them like any other download
+func downloadWithBrowser(fileURL, sourcePage, fileType string) (*fetchResult, error) { // Function to download a file through Chrome
+	log.Println("Downloading with Chrome:", fileURL) // Log which file is being fetched
+
+	downloadDirectory, err := os.MkdirTemp("", "geprc-browser-download-") // Chrome saves into a private temporary directory
+	if err != nil {                                                       // Handle temp directory errors
+		return nil, err // Propagate the error
+	}
+	defer os.RemoveAll(downloadDirectory) // Remove the temporary directory when done
+
+	execAllocatorContext, cancelAllocator := chromedp.NewExecAllocator(context.Background(), chromeAllocatorOptions()...) // Start a Chrome process with the shared options
+	timeoutContext, cancelTimeout := context.WithTimeout(execAllocatorContext, browserDownloadTransferTimeout)            // Bound the whole session
+	browserContext, cancelBrowser := chromedp.NewContext(timeoutContext)                                                  // Create the browser context
+	defer func() {                                                                                                        // Clean up every context on exit
+		cancelBrowser()   // Stops the browser context
+		cancelTimeout()   // Stops the timeout context
+		cancelAllocator() // Stops the Chrome process allocator
+	}() // End of deferred cleanup function
+
+	sizeLimit, ok := downloadSizeLimits[fileType] // Enforce the same per-type limit as HTTP downloads
+	if !ok {                                      // Unknown types only get the run budget
+		sizeLimit = runBudget.limit // Fall back to the whole budget
+	}
+	sizeLimit = min(sizeLimit, runBudget.limit-runBudget.used) // Never exceed the remaining run budget
+
+	downloadStarted := make(chan struct{})                  // Closed once Chrome accepts the download
+	downloadDone := make(chan browserDownloadResult, 1)     // Receives the outcome of the download
+	var downloadGUID string                                 // Identifier Chrome assigns to the download (only touched by the listener)
+	chromedp.ListenTarget(browserContext, func(event any) { // Watch download events
+		switch downloadEvent := event.(type) { // Only download events are interesting
+		case *browser.EventDownloadWillBegin: // Chrome accepted the download
+			if downloadGUID == "" { // Track the first download started by the click
+				downloadGUID = downloadEvent.GUID // Remember its identifier
+				close(downloadStarted)            // Stop the start timeout
+			}
+		case *browser.EventDownloadProgress: // Chrome reports progress
+			if downloadEvent.GUID != downloadGUID { // Ignore unrelated downloads
+				return // Nothing to do
+			}
+			switch { // React to the download state
+			case downloadEvent.ReceivedBytes > float64(sizeLimit): // The file is larger than allowed
+				sendOnce(downloadDone, browserDownloadResult{guid: downloadGUID, limitExceeded: true}) // Stop waiting
+			case downloadEvent.State == browser.DownloadProgressStateCompleted: // Download finished
+				sendOnce(downloadDone, browserDownloadResult{guid: downloadGUID}) // Signal success
+			case downloadEvent.State == browser.DownloadProgressStateCanceled: // Download was cancelled
+				sendOnce(downloadDone, browserDownloadResult{guid: downloadGUID, err: errors.New("browser download was canceled")}) // Signal failure
+			}
+		}
+	}) // End of download event listener
+
+	downloadBehavior := browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName) // Save downloads under their GUID
+	downloadBehavior = downloadBehavior.WithDownloadPath(downloadDirectory).WithEventsEnabled(true)  // Into the temporary directory, reporting progress events
+	clickScript := fmt.Sprintf(`(() => {
+		const link = document.createElement("a");
+		link.href = %q;
+		link.download = "";
+		document.body.appendChild(link);
+		link.click();
+	})()`, fileURL) // Script that clicks a temporary download link for the file
+
+	runError := chromedp.Run(browserContext, // Prepare the browser and start the download
+		downloadBehavior,                    // Apply the download behavior
+		chromedp.Navigate(sourcePage),       // Open the linking page so cookies, challenge and Referer match a real visit
+		chromedp.Sleep(3*time.Second),       // Wait for Cloudflare JS checks and page scripts to finish
+		chromedp.Evaluate(clickScript, nil), // Start the download
+	) // End of chromedp.Run
+	if runError != nil { // Check for navigation or script errors
+		return nil, runError // Propagate the error
+	}
+
+	select { // Wait for the download to start; a dead link never starts one
+	case <-downloadStarted: // Chrome is downloading
+	case <-time.After(browserDownloadStartTimeout): // Nothing happened after the click
+		return nil, fmt.Errorf("browser download did not start within %s", browserDownloadStartTimeout) // Give up early
+	case <-browserContext.Done(): // Timeout or cancellation
+		return nil, fmt.Errorf("browser download did not start: %w", browserContext.Err()) // Report the timeout
+	}
+
+	var result browserDownloadResult // Outcome reported by the listener
+	select {                         // Wait for the download to finish or the session to time out
+	case result = <-downloadDone: // Download finished one way or another
+	case <-browserContext.Done(): // Timeout or cancellation
+		return nil, fmt.Errorf("browser download did not complete: %w", browserContext.Err()) // Report the timeout
+	}
+	if result.limitExceeded { // The file grew past its limit
+		return nil, runBudget.stop(fileURL, fileType, fmt.Sprintf("browser download exceeded %d bytes", sizeLimit)) // Record and report the stop
+	}
+	if result.err != nil { // Handle failed downloads
+		return nil, result.err // Propagate the error
+	}
+
+	downloadedData, err := os.ReadFile(filepath.Join(downloadDirectory, result.guid)) // Chrome names the file after its GUID
+	if err != nil {                                                                   // Handle missing files
+		return nil, err // Propagate the error
+	}
+	runBudget.used += int64(len(downloadedData)) // Browser downloads count against the run budget too
+	if len(downloadedData) == 0 {                // Handle empty downloads
+		return nil, errors.New("browser downloaded 0 bytes") // Report the empty download
+	}
+	return &fetchResult{data: downloadedData, finalURL: fileURL}, nil // Return the file contents for validation and storage
+} // End of downloadWithBrowser function
+
+// Sends a value on a buffered channel unless one is already waiting
+func sendOnce(channel chan browserDownloadResult, value browserDownloadResult) { // Function to report a single outcome
+	select { // Never block the event listener
+	case channel <- value: // First outcome wins
+	default: // An outcome was already reported
+	}
+} // End of sendOnce function
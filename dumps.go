@@ -0,0 +1,420 @@
+package main
+
+import (
+	"encoding/csv"  // Writes the feature matrix
+	"encoding/json" // Writes dumps.json
+	"errors"        // Unwraps parse error lists
+	"fmt"           // Prints parse errors and query results
+	"log"           // Logs unreadable files
+	"os"            // Writes the feature matrix to standard output
+	"path/filepath" // Lists archived CLI dumps
+	"sort"          // Orders the files
+	"strings"       // Parses catalog queries
+
+	"github.com/Strong-Foundation/geprc-com-documentation/betaflight" // CLI dump parser
+)
+
+const dumpErrorsShown = 10           // Errors printed per file before the rest are only counted
+const dumpCatalogPath = "dumps.json" // Catalog of every archived CLI dump with its build metadata
+const dumpDirectory = "TXTs/"        // Where CLI dumps are archived
+
+// What the catalog records about one archived CLI dump
+type dumpCatalogEntry struct {
+	Path           string                     `json:"path"`                      // Archived file (e.g. "TXTs/mark5_analog_4_2_11_crsf.txt")
+	BoardName      string                     `json:"board_name,omitempty"`      // board_name
+	ManufacturerID string                     `json:"manufacturer_id,omitempty"` // manufacturer_id
+	CraftName      string                     `json:"craft_name,omitempty"`      // Craft name
+	Build          *betaflight.Build          `json:"build,omitempty"`           // Firmware header metadata (absent for snippets)
+	ConfigRev      string                     `json:"config_rev,omitempty"`      // "# config rev" commit
+	Features       []string                   `json:"features"`                  // Features on after every feature line is applied
+	ParseErrors    int                        `json:"parse_errors"`              // Lines that are not CLI commands
+	Classification *betaflight.Classification `json:"classification"`            // Kind, completeness and encoding problems
+} // End of dumpCatalogEntry struct
+
+// Lists the archived CLI dumps in a directory, sorted
+func dumpPaths(directory string) []string { // Function to find archived dumps
+	paths, err := filepath.Glob(filepath.Join(directory, "*.txt")) // Every archived text file
+	if err != nil {                                                // Malformed pattern
+		log.Println(err) // Log the error
+		return nil       // Nothing found
+	}
+	sort.Strings(paths) // Stable output
+	return paths        // Return the files
+} // End of dumpPaths function
+
+// Parses every archived CLI dump in the directory and prints the lines that could not be parsed
+func checkDumps(directory string) int { // Function to validate archived CLI dumps
+	paths := dumpPaths(directory) // Every archived dump
+	failed := 0                   // Files with parse errors
+	for _, path := range paths {  // Parse every file
+		_, err := betaflight.ParseFile(path) // Parse the dump
+		if err == nil {                      // Clean parse
+			continue // Nothing to report
+		}
+		failed++                            // Count the file
+		var lineErrors betaflight.ErrorList // Per-line errors
+		if !errors.As(err, &lineErrors) {   // Read failure rather than bad lines
+			fmt.Printf("%s: %v\n", path, err) // Report it
+			continue                          // Next file
+		}
+		for index, lineError := range lineErrors { // Print the first errors
+			if index == dumpErrorsShown { // Enough for one file
+				fmt.Printf("%s: %d more errors\n", path, len(lineErrors)-index) // Count the rest
+				break                                                           // Next file
+			}
+			fmt.Printf("%s:%d: %s: %q\n", path, lineError.Line, lineError.Message, lineError.Text) // file:line: problem
+		}
+	}
+	fmt.Printf("%d dumps checked, %d with errors\n", len(paths), failed) // Summary line
+	return failed                                                        // Files with errors
+} // End of checkDumps function
+
+// Parses every archived CLI dump in the directory into catalog entries
+func catalogDumps(directory string) []*dumpCatalogEntry { // Function to build the dump catalog
+	var catalog []*dumpCatalogEntry             // Entries in path order
+	for _, path := range dumpPaths(directory) { // Parse every file
+		dump, err := betaflight.ParseFile(path)         // Parse the dump
+		var lineErrors betaflight.ErrorList             // Per-line errors
+		if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+			log.Println(err) // Log the error
+			continue         // Leave it out
+		}
+		catalog = append(catalog, &dumpCatalogEntry{ // Record the dump
+			Path:           filepath.ToSlash(path),         // Archived file
+			BoardName:      dump.BoardName,                 // Board
+			ManufacturerID: dump.ManufacturerID,            // Manufacturer
+			CraftName:      dump.CraftName,                 // Craft name
+			Build:          dump.Build,                     // Firmware metadata
+			ConfigRev:      dump.ConfigRev,                 // Config revision
+			Features:       dump.EnabledFeatures(),         // Effective features
+			ParseErrors:    len(lineErrors),                // Unparsable lines
+			Classification: betaflight.Classify(dump, err), // What the file is and what it lacks
+		}) // End of catalog entry
+	}
+	return catalog // Return the entries
+} // End of catalogDumps function
+
+// Regenerates dumps.json from the archived CLI dumps
+func rebuildDumpCatalog() error { // Function to write the dump catalog
+	catalog := catalogDumps(dumpDirectory)             // Parse every dump
+	data, err := json.MarshalIndent(catalog, "", "  ") // Encode the catalog
+	if err != nil {                                    // Handle encoding errors
+		return err // Propagate the error
+	}
+	if err := writeFileAtomically(dumpCatalogPath, append(data, '\n')); err != nil { // Replace the catalog in one step
+		return fmt.Errorf("write %s: %w", dumpCatalogPath, err) // Report which file failed
+	}
+	log.Printf("Cataloged %d CLI dumps in %s", len(catalog), dumpCatalogPath) // Log the result
+	return nil                                                                // Catalog rebuilt
+} // End of rebuildDumpCatalog function
+
+// Prints the archived dumps matching a query of space-separated key=value terms, e.g. "mcu=STM32G47X version=4.5.x";
+// keys are firmware, mcu (family or short code), version, msp, board, manufacturer, kind (dump, diff, snippet, vtxtable,
+// not_cli) and complete (true or false)
+func findDumps(query string) error { // Function to query the dump catalog
+	terms := map[string]string{}                 // Query terms by key
+	for _, term := range strings.Fields(query) { // Parse every term
+		key, value, found := strings.Cut(term, "=") // Split the term
+		switch key {                                // Check the key
+		case "firmware", "mcu", "version", "msp", "board", "manufacturer", "kind", "complete": // Known keys
+		default: // Unknown key
+			found = false // Reject it below
+		}
+		if !found || value == "" { // Malformed term
+			return fmt.Errorf("invalid query term %q (expected firmware=, mcu=, version=, msp=, board=, manufacturer=, kind= or complete=)", term) // Report it
+		}
+		terms[key] = value // Record it
+	}
+
+	matches := 0                                        // Dumps printed
+	for _, entry := range catalogDumps(dumpDirectory) { // Filter every dump
+		if !entry.matches(terms) { // Filtered out
+			continue // Next dump
+		}
+		matches++            // Count it
+		build := entry.Build // Firmware metadata
+		if build == nil {    // Snippet without a header
+			build = &betaflight.Build{} // Print empty fields
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", entry.Path, entry.BoardName, build.MCU, build.Version, build.MSPAPI) // One line per dump
+	}
+	fmt.Printf("%d dumps match\n", matches) // Summary line
+	return nil                              // Query answered
+} // End of findDumps function
+
+// Reports whether a catalog entry satisfies every query term
+func (entry *dumpCatalogEntry) matches(terms map[string]string) bool { // Method to filter catalog entries
+	build := entry.Build            // Firmware metadata
+	for key, value := range terms { // Check every term
+		if build == nil && key != "board" && key != "manufacturer" && key != "kind" && key != "complete" { // Build terms need a header
+			return false // Snippets never match them
+		}
+		switch key { // Compare the field
+		case "firmware": // Firmware name
+			if !strings.EqualFold(build.Firmware, value) { // Different firmware
+				return false // No match
+			}
+		case "mcu": // MCU family or short code
+			if !strings.EqualFold(build.MCU, value) && !strings.EqualFold(build.MCUCode, value) { // Different MCU
+				return false // No match
+			}
+		case "version": // Version pattern
+			if !build.MatchesVersion(value) { // Different version
+				return false // No match
+			}
+		case "msp": // MSP API version
+			if build.MSPAPI != value { // Different API
+				return false // No match
+			}
+		case "board": // Board name
+			if !strings.EqualFold(entry.BoardName, value) { // Different board
+				return false // No match
+			}
+		case "kind": // Dump kind
+			if !strings.EqualFold(string(entry.Classification.Kind), value) { // Different kind
+				return false // No match
+			}
+		case "complete": // Completeness
+			if fmt.Sprint(entry.Classification.Complete) != strings.ToLower(value) { // Different completeness
+				return false // No match
+			}
+		case "manufacturer": // Manufacturer ID
+			if !strings.EqualFold(entry.ManufacturerID, value) { // Different manufacturer
+				return false // No match
+			}
+		}
+	}
+	return true // Every term matched
+} // End of matches method
+
+// Runs a validator over every archived CLI dump and prints its findings as "file:lines: severity: message"
+func printDumpFindings(directory string, validate func(*betaflight.Dump) []betaflight.Finding) { // Function to report validator findings
+	counts := map[betaflight.Severity]int{}     // Findings per severity
+	for _, path := range dumpPaths(directory) { // Validate every dump
+		dump, err := betaflight.ParseFile(path)         // Parse the dump
+		var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+		if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+			log.Println(err) // Log the error
+			continue         // Next file
+		}
+		for _, finding := range validate(dump) { // Print every finding
+			lines := make([]string, len(finding.Lines)) // Line references
+			for index, line := range finding.Lines {    // Format them
+				lines[index] = fmt.Sprint(line) // Line number
+			}
+			fmt.Printf("%s:%s: %s: %s\n", path, strings.Join(lines, ","), finding.Severity, finding.Message) // One line per finding
+			counts[finding.Severity]++                                                                       // Count it
+		}
+	}
+	fmt.Printf("%d errors, %d warnings, %d notes\n", counts[betaflight.SeverityError], counts[betaflight.SeverityWarning], counts[betaflight.SeverityInfo]) // Summary line
+} // End of printDumpFindings function
+
+// Prints the timer channels and DMA streams of one CLI dump with the resources using them
+func printTimerMap(path string) error { // Function to show a board's timer and DMA usage
+	dump, err := betaflight.ParseFile(path)         // Parse the dump
+	var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+	if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+		return err // Propagate the error
+	}
+	timers := dump.TimerMap()                             // Channels per timer
+	for _, timer := range betaflight.SortedKeys(timers) { // Print every timer
+		for _, channel := range timers[timer] { // Print every channel
+			users := make([]string, len(channel.Resources))  // Resources on the pin
+			for index, resource := range channel.Resources { // Describe them
+				users[index] = fmt.Sprintf("%s %d", resource.Function, resource.Index) // e.g. "MOTOR 1"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", timer, channel.Channel, channel.Pin, strings.Join(users, ", ")) // One line per channel
+		}
+	}
+	streams := dump.DMAMap()                                // Users per stream
+	for _, stream := range betaflight.SortedKeys(streams) { // Print every stream
+		users := make([]string, len(streams[stream])) // Its users
+		for index, user := range streams[stream] {    // Describe them
+			users[index] = user.Description // e.g. "pin B04"
+		}
+		fmt.Printf("%s\t%s\n", stream, strings.Join(users, ", ")) // One line per stream
+	}
+	return nil // Printed
+} // End of printTimerMap function
+
+// Prints the decoded serial ports of every archived CLI dump, one block per product file; ports without functions are
+// left out and every function shows the baud rate it runs at
+func printUARTMaps(directory string) { // Function to show every product's UART map
+	for _, path := range dumpPaths(directory) { // Decode every dump
+		dump, err := betaflight.ParseFile(path)         // Parse the dump
+		var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+		if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+			log.Println(err) // Log the error
+			continue         // Next file
+		}
+		if len(dump.Serial) == 0 { // No serial lines (vtxtable files, snippets, ...)
+			continue // Nothing to print
+		}
+		version := "unknown version" // Firmware release
+		if dump.Build != nil {       // Header present
+			version = dump.Build.Firmware + " " + dump.Build.Version // e.g. "Betaflight 4.5.1"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", path, dump.CraftName, dump.BoardName, version) // Product line
+		for _, uart := range dump.UARTs() {                                           // Print every port
+			if len(uart.Functions) == 0 && uart.UnknownFunctions == 0 { // Unused port
+				continue // Leave it out
+			}
+			functions := make([]string, 0, len(uart.Functions)+1) // Functions with their baud rates
+			for _, function := range uart.Functions {             // Describe every function
+				functions = append(functions, function+uartBaudSuffix(uart, function)) // e.g. "GPS@57600"
+			}
+			if uart.UnknownFunctions != 0 { // Bits the release does not define
+				functions = append(functions, fmt.Sprintf("UNKNOWN(%d)", uart.UnknownFunctions)) // Show the raw bits
+			}
+			fmt.Printf("\t%s\t%s\n", uart.Port, strings.Join(functions, " ")) // One line per port
+		}
+	}
+} // End of printUARTMaps function
+
+// Prints a CSV matrix of every archived CLI dump against every feature named in the corpus: 1 when the feature ends up
+// on, 0 when it ends up off, empty when the dump has no line for it (diffs and snippets only list changes)
+func printFeatureMatrix(directory string) error { // Function to compare features across the corpus
+	type row struct { // One dump
+		path    string          // Archived file
+		version string          // Firmware version
+		states  map[string]bool // Effective state of every feature the dump names
+	} // End of row struct
+	var rows []row                              // Dumps in path order
+	names := map[string]bool{}                  // Every feature named anywhere
+	for _, path := range dumpPaths(directory) { // Parse every dump
+		dump, err := betaflight.ParseFile(path)         // Parse the dump
+		var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+		if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+			log.Println(err) // Log the error
+			continue         // Next file
+		}
+		if len(dump.Features) == 0 { // No feature lines
+			continue // Nothing to compare
+		}
+		current := row{path: filepath.ToSlash(path), states: dump.FeatureStates()} // Start the row
+		if dump.Build != nil {                                                     // Header present
+			current.version = dump.Build.Version // Firmware version
+		}
+		for name := range current.states { // Every feature the dump names
+			names[name] = true // Add the column
+		}
+		rows = append(rows, current) // Record it
+	}
+
+	columns := betaflight.SortedKeys(names)                   // Feature columns
+	writer := csv.NewWriter(os.Stdout)                        // CSV on standard output
+	header := append([]string{"path", "version"}, columns...) // Header row
+	if err := writer.Write(header); err != nil {              // Write the header
+		return err // Propagate the error
+	}
+	for _, current := range rows { // Write every dump
+		record := []string{current.path, current.version} // Leading columns
+		for _, name := range columns {                    // One cell per feature
+			on, named := current.states[name] // Effective state
+			switch {                          // Encode it
+			case !named: // No line for it
+				record = append(record, "") // Unknown
+			case on: // Enabled
+				record = append(record, "1") // On
+			default: // Disabled
+				record = append(record, "0") // Off
+			}
+		}
+		if err := writer.Write(record); err != nil { // Write the row
+			return err // Propagate the error
+		}
+	}
+	writer.Flush()        // Write buffered rows
+	return writer.Error() // Report any write error
+} // End of printFeatureMatrix function
+
+// Prints the LED strip layout of one CLI dump as a text grid, or as SVG when asked
+func printLEDLayout(path string, svg bool) error { // Function to draw a board's LED strip
+	dump, err := betaflight.ParseFile(path)         // Parse the dump
+	var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+	if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+		return err // Propagate the error
+	}
+	layout, err := dump.LEDLayout() // Decode the led lines
+	if err != nil {                 // Some definitions are malformed
+		log.Println(err) // Log them and draw the rest
+	}
+	if len(layout) == 0 { // Every slot is empty
+		return fmt.Errorf("%s has no LED strip layout", path) // Nothing to draw
+	}
+	if svg { // SVG requested
+		fmt.Print(betaflight.RenderLEDSVG(layout)) // Print the image
+		return nil                                 // Drawn
+	}
+	fmt.Print(betaflight.RenderLEDGrid(layout)) // Print the grid
+	return nil                                  // Drawn
+} // End of printLEDLayout function
+
+// Prints the vtxtable of one CLI dump or vtxtable file as a Betaflight Configurator VTX config file
+func printVTXTableJSON(path string) error { // Function to export a vtxtable
+	dump, err := betaflight.ParseFile(path)         // Parse the file
+	var lineErrors betaflight.ErrorList             // Per-line errors are fine here (vtxtable files carry banners)
+	if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+		return err // Propagate the error
+	}
+	if dump.VTXTable == nil { // No vtxtable lines
+		return fmt.Errorf("%s has no vtxtable", path) // Nothing to export
+	}
+	for _, finding := range betaflight.CheckVTXTable(dump) { // Refuse tables the firmware would not hold as written
+		if finding.Severity == betaflight.SeverityError { // Broken table
+			return fmt.Errorf("%s:%d: %s; not exporting", path, finding.Lines[0], finding.Message) // Report the first error
+		}
+	}
+	data, err := dump.VTXTable.ConfiguratorJSON("VTX table from " + filepath.Base(path)) // Encode the table
+	if err != nil {                                                                      // Handle encoding errors
+		return err // Propagate the error
+	}
+	fmt.Println(string(data)) // Print the file
+	return nil                // Exported
+} // End of printVTXTableJSON function
+
+// Prints the mode switch table of every archived CLI dump, one block per factory config, followed by its mode warnings
+func printSwitchTables(directory string) { // Function to show every factory config's switches
+	for _, path := range dumpPaths(directory) { // Decode every dump
+		dump, err := betaflight.ParseFile(path)         // Parse the dump
+		var lineErrors betaflight.ErrorList             // Per-line errors are fine here
+		if err != nil && !errors.As(err, &lineErrors) { // Unreadable file
+			log.Println(err) // Log the error
+			continue         // Next file
+		}
+		switches := dump.Switches() // Active mode ranges
+		if len(switches) == 0 {     // No aux lines (vtxtable files, snippets, ...)
+			continue // Nothing to print
+		}
+		version := "unknown version" // Firmware release
+		if dump.Build != nil {       // Header present
+			version = dump.Build.Firmware + " " + dump.Build.Version // e.g. "Betaflight 4.5.1"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", path, dump.CraftName, dump.BoardName, version) // Config line
+		for _, entry := range switches {                                              // Print every range
+			if entry.Linked != "" { // Follows another mode
+				fmt.Printf("\t%s\tlinked to %s\n", entry.Mode, entry.Linked) // One line per link
+				continue                                                     // Next range
+			}
+			fmt.Printf("\t%s\t%d-%d\t%s\t%s\n", entry.AUX, entry.Low, entry.High, entry.Mode, entry.Logic) // One line per range
+		}
+		for _, finding := range betaflight.CheckModes(dump) { // Print the warnings
+			fmt.Printf("\t%s: %s\n", finding.Severity, finding.Message) // One line per finding
+		}
+	}
+} // End of printSwitchTables function
+
+// Returns "@rate" for functions that run at one of the port's baud rates, or "" for the others
+func uartBaudSuffix(uart betaflight.UART, function string) string { // Function to attach a baud rate
+	switch { // Baud rate used by the function
+	case function == "MSP" || function == "MSP_DISPLAYPORT": // MSP and MSP DisplayPort
+		return "@" + uart.MSPBaud.Label // MSP baud rate
+	case function == "GPS": // GPS
+		return "@" + uart.GPSBaud.Label // GPS baud rate
+	case strings.HasPrefix(function, "TELEMETRY_"): // Telemetry protocols
+		return "@" + uart.TelemetryBaud.Label // Telemetry baud rate
+	case function == "BLACKBOX": // Blackbox logging
+		return "@" + uart.BlackboxBaud.Label // Blackbox baud rate
+	}
+	return "" // Receiver, VTX control and ESC telemetry pick their own rate
+} // End of uartBaudSuffix function
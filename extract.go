@@ -0,0 +1,398 @@
+package main
+
+import (
+	"archive/tar"   // Reads tar and tar.gz archives
+	"archive/zip"   // Reads downloaded ZIP archives
+	"bytes"         // Wraps archive data for the readers
+	"compress/gzip" // Decompresses tar.gz archives
+	"errors"        // Creates extraction errors
+	"fmt"           // Formats extraction errors
+	"io"            // Reads entries under the size limit
+	"log"           // Logs extraction results
+	"os"            // Removes stale extraction directories
+	"path"          // Cleans entry names
+	"path/filepath" // Builds extraction paths
+	"strings"       // Inspects entry names
+)
+
+const maxExtractedBytes = 1 << 30 // Total uncompressed bytes one archive may expand to (1 GiB), against zip bombs
+const maxExtractedEntries = 10000 // Number of entries one archive may contain
+
+const archiveExtracted = "extracted" // Every regular entry was written under the extraction directory
+const archiveListed = "listed"       // Entries were read from the headers but not extracted (no pure-Go decoder)
+const archiveStored = "stored"       // Kept as downloaded; its entries could not be read (e.g. encrypted headers)
+const archiveRejected = "rejected"   // The archive was unsafe or too large; nothing was kept
+
+// Archive formats recognized on vendor pages, keyed by lowercase extension, with the file type used for
+// size limits and validation. Longer extensions come first so ".tar.gz" wins over ".gz".
+var archiveFormats = []struct {
+	extension string // Lowercase file extension
+	fileType  string // File type passed to downloadFile
+}{
+	{".tar.gz", "TAR"}, // Gzip-compressed tar
+	{".tgz", "TAR"},    // Gzip-compressed tar, short form
+	{".tar", "TAR"},    // Plain tar
+	{".zip", "ZIP"},    // ZIP
+	{".rar", "RAR"},    // RAR 4 and 5 (listed only)
+	{".7z", "7Z"},      // 7-Zip (listed only)
+} // End of archiveFormats list
+
+// What an archive contains and where it was extracted, as recorded in the manifest
+type archiveListing struct {
+	Status    string         `json:"status"`              // extracted, listed, stored or rejected
+	Directory string         `json:"directory,omitempty"` // Extraction directory (e.g. "ZIPs/geprc_nano_2g4rx_3_0_0")
+	SHA256    string         `json:"sha256,omitempty"`    // SHA-256 of the archive the listing was made from
+	Entries   []archiveEntry `json:"entries,omitempty"`   // Files inside the archive
+	Reason    string         `json:"reason,omitempty"`    // Why the archive was not extracted
+} // End of archiveListing struct
+
+// One file inside an archive
+type archiveEntry struct {
+	Name   string `json:"name"`             // Path inside the archive, as published
+	Path   string `json:"path,omitempty"`   // Normalized extracted path, empty when the entry was not extracted
+	Size   int64  `json:"size"`             // Uncompressed size in bytes
+	SHA256 string `json:"sha256,omitempty"` // SHA-256 of the extracted contents
+} // End of archiveEntry struct
+
+// One entry of an archive being extracted, independent of the archive format
+type archiveMember struct {
+	name    string                        // Path inside the archive, as published
+	dir     bool                          // Whether the entry is a directory
+	regular bool                          // Whether the entry is a regular file (not a link or device)
+	size    int64                         // Declared uncompressed size
+	open    func() (io.ReadCloser, error) // Opens the entry contents
+} // End of archiveMember struct
+
+// Returns the archive extension of a filename ("x.tar.gz" → ".tar.gz"), or "" when it is not an archive
+func archiveExtensionOf(name string) string { // Function to recognize archive filenames
+	lower := strings.ToLower(name)          // Compare case-insensitively
+	for _, format := range archiveFormats { // Check every known format
+		if strings.HasSuffix(lower, format.extension) { // Found the format
+			return format.extension // Return the extension
+		}
+	}
+	return "" // Not an archive
+} // End of archiveExtensionOf function
+
+// Returns the file type of an archive filename ("x.rar" → "RAR"), or "" when it is not an archive
+func archiveFileTypeOf(name string) string { // Function to map an archive to its file type
+	extension := archiveExtensionOf(name)   // Find the extension
+	for _, format := range archiveFormats { // Look up its file type
+		if extension != "" && format.extension == extension { // Found the format
+			return format.fileType // Return the file type
+		}
+	}
+	return "" // Not an archive
+} // End of archiveFileTypeOf function
+
+// Returns the directory an archive is extracted into: the archive path without its extension
+// ("ZIPs/geprc_nano_2g4rx_3_0_0.zip" → "ZIPs/geprc_nano_2g4rx_3_0_0")
+func extractionDirectoryFor(archivePath string) string { // Function to name an extraction directory
+	extension := archiveExtensionOf(archivePath) // Archive extensions may have two parts
+	if extension == "" {                         // Not a known archive
+		extension = getFileExtension(archivePath) // Strip the last extension
+	}
+	return filepath.ToSlash(archivePath[:len(archivePath)-len(extension)]) // Strip the extension
+} // End of extractionDirectoryFor function
+
+// Extracts (or lists) a downloaded archive next to itself and records the listing in the manifest. Unless force is
+// set, an archive whose recorded listing was made from the same bytes is left as it is.
+func extractArchive(archivePath string, data []byte, force bool) { // Function to unpack an archived download
+	sum := sha256Hex(data)                                                  // Hash of the archive
+	if !force && downloadManifest.archiveListingMatches(archivePath, sum) { // Already extracted from these bytes
+		log.Printf("Archive unchanged, not extracting again: %s", archivePath) // Log the skip
+		return                                                                 // Nothing to do
+	}
+	directory := extractionDirectoryFor(archivePath) // Where the entries go
+	var listing *archiveListing                      // Result for the manifest
+	switch archiveFileTypeOf(archivePath) {          // Pick the reader for the format
+	case "ZIP": // ZIP archive
+		members, err := zipMembers(data)                  // Read the central directory
+		listing = extractMembers(directory, members, err) // Extract the entries
+	case "TAR": // tar or tar.gz archive
+		members, err := tarMembers(data)                  // Read the tar headers
+		listing = extractMembers(directory, members, err) // Extract the entries
+	case "RAR": // RAR archive
+		entries, err := listRAR(data)                                                                          // Read the file headers
+		listing = listedArchive(entries, err, "RAR decompression is not available in the Go standard library") // Record the listing
+	case "7Z": // 7-Zip archive
+		entries, err := list7z(data)                                                                          // Read the header
+		listing = listedArchive(entries, err, "7z decompression is not available in the Go standard library") // Record the listing
+	default: // Not an archive
+		return // Nothing to record
+	}
+
+	listing.SHA256 = sum    // Remember which bytes the listing describes
+	switch listing.Status { // Log the outcome
+	case archiveExtracted: // Extraction succeeded
+		log.Printf("Extracted %d entries: %s → %s", len(listing.Entries), archivePath, listing.Directory) // Log the result
+	case archiveListed: // Only the headers could be read
+		log.Printf("Listed %d entries without extracting: %s (%s)", len(listing.Entries), archivePath, listing.Reason) // Log the result
+	default: // Stored or rejected
+		log.Printf("Not extracting %s: %s", archivePath, listing.Reason) // Log the reason
+	}
+	downloadManifest.recordArchive(archivePath, listing) // Record the listing
+} // End of extractArchive function
+
+// Builds the listing of an archive whose entries can be read but not extracted
+func listedArchive(entries []archiveEntry, err error, reason string) *archiveListing { // Function to record a header-only listing
+	if err != nil { // The headers could not be read either
+		return &archiveListing{Status: archiveStored, Reason: err.Error()} // Keep the file as downloaded
+	}
+	return &archiveListing{Status: archiveListed, Entries: entries, Reason: reason} // Entries without extracted paths
+} // End of listedArchive function
+
+// Describes every entry of a ZIP archive
+func zipMembers(data []byte) ([]archiveMember, error) { // Function to read ZIP entries
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data))) // Open the archive in memory
+	if err != nil {                                                       // Handle corrupt archives
+		return nil, err // Propagate the error
+	}
+	members := make([]archiveMember, 0, len(reader.File)) // One member per entry
+	for _, file := range reader.File {                    // Describe every entry
+		members = append(members, archiveMember{ // Describe the entry
+			name:    file.Name,                      // Path inside the archive
+			dir:     file.FileInfo().IsDir(),        // Directory flag
+			regular: file.Mode().IsRegular(),        // Regular file flag
+			size:    int64(file.UncompressedSize64), // Declared size
+			open:    file.Open,                      // Decompresses and checks the CRC
+		}) // End of member
+	}
+	return members, nil // Return the members
+} // End of zipMembers function
+
+// Describes every entry of a tar or tar.gz archive. Compressed archives are inflated in memory first,
+// never beyond maxExtractedBytes.
+func tarMembers(data []byte) ([]archiveMember, error) { // Function to read tar entries
+	raw := data                                              // Uncompressed tar stream
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b { // Gzip magic number
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data)) // Open the gzip stream
+		if err != nil {                                          // Handle corrupt headers
+			return nil, err // Propagate the error
+		}
+		raw, err = io.ReadAll(io.LimitReader(gzipReader, maxExtractedBytes+1)) // Inflate one byte past the limit
+		if err != nil {                                                        // Handle corrupt data and checksum mismatches
+			return nil, err // Propagate the error
+		}
+		if len(raw) > maxExtractedBytes { // Inflated beyond the limit
+			return nil, fmt.Errorf("uncompressed size exceeds the limit of %d bytes", maxExtractedBytes) // Report the gzip bomb
+		}
+	}
+
+	rawReader := bytes.NewReader(raw)     // Positioned reader over the tar stream
+	tarReader := tar.NewReader(rawReader) // Read the tar headers
+	var members []archiveMember           // Collected members
+	for {                                 // Visit every header
+		header, err := tarReader.Next() // Advance to the next entry
+		if err == io.EOF {              // End of the archive
+			break // Done
+		}
+		if err != nil { // Handle corrupt headers
+			return nil, err // Propagate the error
+		}
+		offset := rawReader.Size() - int64(rawReader.Len())          // The contents start right after the header blocks
+		if header.Size < 0 || offset+header.Size > int64(len(raw)) { // Truncated archive
+			return nil, fmt.Errorf("tar entry %s is truncated", header.Name) // Report the truncation
+		}
+		members = append(members, archiveMember{ // Describe the entry
+			name:    header.Name,                             // Path inside the archive
+			dir:     header.Typeflag == tar.TypeDir,          // Directory flag
+			regular: header.Typeflag == tar.TypeReg,          // Regular file flag (links, devices and sparse files are skipped)
+			size:    header.Size,                             // Declared size
+			open:    sectionOpener(raw, offset, header.Size), // Reads the contents in place
+		}) // End of member
+		if len(members) > maxExtractedEntries { // Stop reading headers of oversized archives
+			break // extractMembers reports the limit
+		}
+	}
+	return members, nil // Return the members
+} // End of tarMembers function
+
+// Returns an opener for a byte range of an in-memory archive
+func sectionOpener(data []byte, offset, size int64) func() (io.ReadCloser, error) { // Function to open a tar entry
+	return func() (io.ReadCloser, error) { // Opener used by extractMembers
+		return io.NopCloser(io.NewSectionReader(bytes.NewReader(data), offset, size)), nil // Read the range
+	}
+} // End of sectionOpener function
+
+// Extracts archive members into directory, rejecting the whole archive if any entry would escape it or the
+// total uncompressed size exceeds maxExtractedBytes. Directory and file names are normalized, and a
+// single folder wrapping the whole archive is dropped.
+func extractMembers(directory string, members []archiveMember, readError error) *archiveListing { // Function to unpack an archive safely
+	rejected := func(reason string) *archiveListing { // Builds a rejected listing
+		return &archiveListing{Status: archiveRejected, Reason: reason} // Nothing is kept
+	}
+	if readError != nil { // The archive could not be read
+		return rejected(readError.Error()) // Report the error
+	}
+	if len(members) > maxExtractedEntries { // Too many entries
+		return rejected(fmt.Sprintf("more than %d entries", maxExtractedEntries)) // Report the limit
+	}
+
+	wrapper := sharedTopDirectory(members)                                     // Single folder wrapping every entry, if any
+	listing := &archiveListing{Status: archiveExtracted, Directory: directory} // Listing being built
+	var sources []*archiveMember                                               // Member behind each listed entry (nil when skipped)
+	usedPaths := map[string]bool{}                                             // Extracted paths already taken
+	var declaredSize int64                                                     // Sum of the declared uncompressed sizes
+	for index := range members {                                               // Check every entry before writing anything
+		member := &members[index]                          // Entry being checked
+		name := strings.ReplaceAll(member.name, "\\", "/") // Some tools write Windows separators
+		if !safeArchiveName(name) {                        // Absolute paths and ".." segments escape the directory
+			return rejected("unsafe entry path " + member.name) // Refuse the whole archive
+		}
+		if member.dir { // Directories are implied by file paths
+			continue // Nothing to extract
+		}
+		entry := archiveEntry{Name: member.name, Size: member.size} // Describe the entry
+		if !member.regular {                                        // Symlinks and devices are never extracted
+			log.Printf("Skipping non-regular archive entry %s", member.name) // Log the skip
+			listing.Entries = append(listing.Entries, entry)                 // List it without a path
+			sources = append(sources, nil)                                   // Nothing to read
+			continue                                                         // Move on
+		}
+		declaredSize += member.size                              // Count the declared size
+		if member.size < 0 || declaredSize > maxExtractedBytes { // Reject oversized archives up front
+			return rejected(fmt.Sprintf("declared size exceeds the limit of %d bytes", maxExtractedBytes)) // Report the limit
+		}
+		target := normalizedArchivePath(directory, strings.TrimPrefix(strings.TrimPrefix(name, "./"), wrapper)) // Normalized destination without the wrapping folder
+		normalized := target                                                                                    // Path before disambiguation
+		for attempt := 0; usedPaths[target]; attempt++ {                                                        // Two entries normalize to the same path, or the hashed name is taken too
+			seed := member.name // Hash the original name first
+			if attempt > 0 {    // That name is taken as well
+				seed = fmt.Sprintf("%s#%d", member.name, attempt) // Add the attempt number
+			}
+			target = path.Join(path.Dir(normalized), disambiguateFilename(path.Base(normalized), seed)) // Append the hash
+		}
+		usedPaths[target] = true                         // Reserve the path
+		entry.Path = target                              // Record the destination
+		listing.Entries = append(listing.Entries, entry) // List the entry
+		sources = append(sources, member)                // Remember where to read it from
+	}
+
+	if _, local := archiveStorage.(localStorage); local { // Replace earlier (possibly hand-made) extractions on disk
+		if err := os.RemoveAll(filepath.FromSlash(directory)); err != nil { // Start from a clean directory
+			return rejected(err.Error()) // Report the error
+		}
+	}
+	var extractedSize int64              // Bytes actually written
+	for index, member := range sources { // Write every regular entry
+		if member == nil { // Skipped entry
+			continue // Nothing to write
+		}
+		contents, err := readArchiveMember(member, maxExtractedBytes-extractedSize) // Read it under the remaining budget
+		if err != nil {                                                             // Corrupt entry or zip bomb
+			removeLocalExtraction(directory)                         // Drop what was written so far
+			return rejected(fmt.Sprintf("%s: %v", member.name, err)) // Report the error
+		}
+		extractedSize += int64(len(contents))                                             // Count the real size
+		if err := archiveStorage.Put(listing.Entries[index].Path, contents); err != nil { // Write the file
+			removeLocalExtraction(directory) // Drop what was written so far
+			return rejected(err.Error())     // Report the error
+		}
+		listing.Entries[index].Size = int64(len(contents))  // Record the real size
+		listing.Entries[index].SHA256 = sha256Hex(contents) // Record the contents hash
+	}
+	return listing // Return the listing
+} // End of extractMembers function
+
+// Reads an archive entry, failing once it expands beyond limit bytes whatever its header claims
+func readArchiveMember(member *archiveMember, limit int64) ([]byte, error) { // Function to read an entry under a limit
+	entryReader, err := member.open() // Open the entry
+	if err != nil {                   // Handle unsupported compression methods
+		return nil, err // Propagate the error
+	}
+	defer entryReader.Close()                                         // Close the entry when done
+	contents, err := io.ReadAll(io.LimitReader(entryReader, limit+1)) // Read one byte past the limit
+	if err != nil {                                                   // Handle corrupt data and CRC mismatches
+		return nil, err // Propagate the error
+	}
+	if int64(len(contents)) > limit { // Expanded beyond the limit
+		return nil, errors.New("uncompressed size exceeds the extraction limit") // Report the zip bomb
+	}
+	return contents, nil // Return the contents
+} // End of readArchiveMember function
+
+// Returns the top-level folder ("Name/") shared by every entry, or "" when entries sit at the root or in
+// different folders. Vendor archives usually wrap everything in one folder named like the archive itself,
+// which would otherwise nest a second copy of the name inside the extraction directory.
+func sharedTopDirectory(members []archiveMember) string { // Function to detect a wrapping folder
+	wrapper := ""                    // Folder shared so far
+	for _, member := range members { // Check every entry
+		name := strings.TrimPrefix(strings.ReplaceAll(member.name, "\\", "/"), "./") // Some tools write Windows separators or "./"
+		top, _, nested := strings.Cut(name, "/")                                     // Split off the first segment
+		if !nested || top == "" || top == "." || top == ".." {                       // Root-level entry
+			return "" // Nothing to strip
+		}
+		if wrapper != "" && wrapper != top+"/" { // Entries in different folders
+			return "" // Nothing to strip
+		}
+		wrapper = top + "/" // Remember the folder
+	}
+	return wrapper // Return the shared folder
+} // End of sharedTopDirectory function
+
+// Reports whether an archive entry name stays inside the extraction directory
+func safeArchiveName(name string) bool { // Function to detect path traversal
+	if name == "" || strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" || (len(name) > 1 && name[1] == ':') { // Absolute or drive-relative paths
+		return false // Escapes the directory
+	}
+	for _, segment := range strings.Split(name, "/") { // Check every path segment
+		if segment == ".." { // Parent directory reference
+			return false // Escapes the directory
+		}
+	}
+	return true // Safe name
+} // End of safeArchiveName function
+
+// Builds the normalized extracted path of an entry: every directory name goes through sanitizeNameStem and the
+// file name through sanitizeFilename ("GEPRC ELRS Nano 2.4G Firmware 3.2.0/GEPRC_Nano_2G4RX-3.2.0.bin" →
+// "<directory>/geprc_elrs_nano_2_4g_firmware_3_2_0/geprc_nano_2g4rx_3_2_0.bin")
+func normalizedArchivePath(directory, name string) string { // Function to normalize an entry path
+	var segments []string                         // Normalized path segments
+	parts := strings.Split(path.Clean(name), "/") // Split the cleaned entry path
+	for index, part := range parts {              // Normalize every segment
+		if part == "" || part == "." { // Empty or current-directory segments
+			continue // Drop them
+		}
+		if index == len(parts)-1 { // The file name itself
+			segments = append(segments, sanitizeFilename(part)) // Keep a clean extension
+		} else { // A directory name
+			segments = append(segments, sanitizeNameStem(part)) // Dots become underscores
+		}
+	}
+	return path.Join(append([]string{directory}, segments...)...) // Join under the extraction directory
+} // End of normalizedArchivePath function
+
+// Removes a partial extraction from the local disk
+func removeLocalExtraction(directory string) { // Function to clean up after a failed extraction
+	if _, local := archiveStorage.(localStorage); !local { // Other backends cannot delete
+		return // Nothing to do
+	}
+	if err := os.RemoveAll(filepath.FromSlash(directory)); err != nil { // Delete the directory
+		log.Println(err) // Log the error
+	}
+} // End of removeLocalExtraction function
+
+// Re-extracts every archive already downloaded (e.g. to replace hand-extracted folders with the normalized layout)
+func extractArchivedFiles(outputDirectory string) { // Function backing the -extract command-line mode
+	entries, err := os.ReadDir(outputDirectory) // List the archive directory
+	if err != nil {                             // Handle a missing directory
+		log.Println(err) // Log the error
+		return           // Nothing to extract
+	}
+	for _, directoryEntry := range entries { // Check every file
+		if directoryEntry.IsDir() || archiveExtensionOf(directoryEntry.Name()) == "" { // Only archive files
+			continue // Skip everything else
+		}
+		archivePath := filepath.ToSlash(filepath.Join(outputDirectory, directoryEntry.Name())) // Archive path of the file
+		data, err := os.ReadFile(archivePath)                                                  // Read the archive
+		if err != nil {                                                                        // Handle read errors
+			log.Println(err) // Log the error
+			continue         // Move on
+		}
+		extractArchive(archivePath, data, true) // Extract and record it, even when unchanged
+	}
+	if err := downloadManifest.save(); err != nil { // Persist the listings
+		log.Println(err) // Log the write error
+	}
+} // End of extractArchivedFiles function
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"archive/zip"   // Builds ZIP fixtures
+	"bytes"         // Holds the fixture archive
+	"os"            // Checks extracted files
+	"path/filepath" // Builds the test manifest path
+	"testing"       // Test framework
+)
+
+// Returns a ZIP archive with one small entry per name, in order
+func zipWithEntries(t *testing.T, names ...string) []byte { // Helper to build a ZIP fixture
+	var archive bytes.Buffer          // Archive
+	writer := zip.NewWriter(&archive) // ZIP writer
+	for _, name := range names {      // Add every entry
+		entry, err := writer.Create(name) // Deflated entry
+		if err != nil {                   // Should never happen
+			t.Fatal(err) // Stop the test
+		}
+		entry.Write([]byte(name)) // The entry holds its own name
+	}
+	if err := writer.Close(); err != nil { // Write the central directory
+		t.Fatal(err) // Stop the test
+	}
+	return archive.Bytes() // Return the archive
+} // End of zipWithEntries function
+
+// Runs the test in an empty directory with an empty manifest
+func useScratchArchive(t *testing.T) { // Helper to isolate extraction tests
+	directory := t.TempDir()                                                                                              // Scratch archive
+	t.Chdir(directory)                                                                                                    // Archive paths are relative
+	previous := downloadManifest                                                                                          // Manifest of the checkout
+	downloadManifest = &assetManifest{path: filepath.Join(directory, manifestPath), entries: map[string]*manifestEntry{}} // Empty manifest
+	t.Cleanup(func() { downloadManifest = previous })                                                                     // Restore it
+} // End of useScratchArchive function
+
+// Checks that a disambiguated name that is itself taken is hashed again instead of overwriting an entry
+func TestExtractArchiveCollisions(t *testing.T) { // Test of path disambiguation
+	useScratchArchive(t)                                                                           // Empty archive
+	hashed := disambiguateFilename("readme.txt", "README.TXT")                                     // Where the second readme would go
+	extractArchive("ZIPs/board.zip", zipWithEntries(t, "readme.txt", hashed, "README.TXT"), false) // The third entry collides twice
+	listing := downloadManifest.entries["ZIPs/board.zip"].Archive                                  // Recorded listing
+	if listing == nil || listing.Status != archiveExtracted || len(listing.Entries) != 3 {         // Every entry extracted
+		t.Fatalf("listing = %+v", listing) // Stop the test
+	}
+	paths := map[string]bool{}              // Distinct destinations
+	for _, entry := range listing.Entries { // Check every entry
+		paths[entry.Path] = true                          // Record the destination
+		contents, err := os.ReadFile(entry.Path)          // Read it back
+		if err != nil || string(contents) != entry.Name { // Overwritten by another entry
+			t.Errorf("%s → %s holds %q, %v", entry.Name, entry.Path, contents, err) // Report it
+		}
+	}
+	if len(paths) != 3 || !paths["ZIPs/board/readme.txt"] || !paths["ZIPs/board/"+hashed] { // The first two keep their names
+		t.Errorf("paths = %v", paths) // Report it
+	}
+} // End of TestExtractArchiveCollisions function
+
+// Checks that an archive is extracted again only when its bytes changed or extraction is forced
+func TestExtractArchiveUnchanged(t *testing.T) { // Test of the listing hash
+	useScratchArchive(t)                                       // Empty archive
+	data := zipWithEntries(t, "readme.txt")                    // Archive contents
+	extractArchive("ZIPs/board.zip", data, false)              // First extraction
+	if err := os.Remove("ZIPs/board/readme.txt"); err != nil { // Remove the extracted file
+		t.Fatal(err) // Stop the test
+	}
+	extractArchive("ZIPs/board.zip", data, false)                        // Same bytes
+	if _, err := os.Stat("ZIPs/board/readme.txt"); !os.IsNotExist(err) { // Not extracted again
+		t.Errorf("unchanged archive was extracted again: %v", err) // Report it
+	}
+	extractArchive("ZIPs/board.zip", zipWithEntries(t, "readme.txt", "notes.txt"), false) // New bytes
+	if _, err := os.Stat("ZIPs/board/readme.txt"); err != nil {                           // Extracted again
+		t.Errorf("changed archive was not extracted: %v", err) // Report it
+	}
+	os.Remove("ZIPs/board/readme.txt")                                                   // Remove it again
+	extractArchive("ZIPs/board.zip", zipWithEntries(t, "readme.txt", "notes.txt"), true) // Forced, as -extract does
+	if _, err := os.Stat("ZIPs/board/readme.txt"); err != nil {                          // Extracted again
+		t.Errorf("forced extraction was skipped: %v", err) // Report it
+	}
+} // End of TestExtractArchiveUnchanged function
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/sha256" // Hashes URLs to disambiguate colliding names
+	"encoding/hex"  // Encodes the URL hash
+	"encoding/json" // Reads and writes the filename map
+	"fmt"           // Formats log messages
+	"log"           // Logs collisions and migrations
+	"os"            // Provides file system access
+	"path/filepath" // Builds archive paths
+	"regexp"        // Sanitizes legacy filenames
+	"strings"       // Manipulates filenames
+)
+
+const filenameMapPath = "filenames.json" // Maps every archived filename back to the URL it came from
+
+// Tracks which URL owns each archived filename so names stay unique and reversible across runs
+type filenameRegistry struct {
+	path   string            // Where the map is persisted
+	byName map[string]string // Filename → source URL
+	byURL  map[string]string // Source URL → filename
+	dirty  bool              // Whether the map changed since it was loaded
+} // End of filenameRegistry struct
+
+var archiveFilenames = loadFilenameRegistry(filenameMapPath) // Registry shared by all downloaders
+
+// Loads the filename map, starting empty when it does not exist yet
+func loadFilenameRegistry(path string) *filenameRegistry { // Function to load the filename map
+	registry := &filenameRegistry{path: path, byName: map[string]string{}, byURL: map[string]string{}} // Start with an empty registry
+	data, err := os.ReadFile(path)                                                                     // Read the persisted map
+	if err != nil {                                                                                    // A missing map simply means nothing was recorded yet
+		if !os.IsNotExist(err) { // Other errors deserve a log line
+			log.Println(err) // Log the read error
+		}
+		return registry // Return the empty registry
+	}
+	if err := json.Unmarshal(data, &registry.byName); err != nil { // Decode filename → URL pairs
+		log.Printf("parse %s: %v", path, err) // Log the corrupt map
+		return registry                       // Continue with an empty registry
+	}
+	for name, sourceURL := range registry.byName { // Build the reverse index
+		registry.byURL[sourceURL] = name // URL → filename
+	}
+	return registry // Return the loaded registry
+} // End of loadFilenameRegistry function
+
+// Returns the archive filename for a URL: the recorded name if there is one, otherwise the
+// sanitized name, suffixed with a short URL hash when another URL already owns that name
+func (registry *filenameRegistry) filenameFor(fileURL string) string { // Method to choose a collision-free filename
+	if name, ok := registry.byURL[fileURL]; ok { // The URL was archived before
+		return name // Keep its existing name
+	}
+	name := urlToFilename(fileURL)                                        // Start from the sanitized name
+	if owner, taken := registry.byName[name]; taken && owner != fileURL { // Another URL already owns that name
+		disambiguated := disambiguateFilename(name, fileURL)                                                          // Append a deterministic URL hash
+		log.Printf("Filename collision: %s already maps to %s; using %s for %s", name, owner, disambiguated, fileURL) // Log the collision
+		return disambiguated                                                                                          // Use the hashed name
+	}
+	return name // The plain name is free
+} // End of filenameFor method
+
+// Records that a filename now belongs to a URL
+func (registry *filenameRegistry) claim(name, fileURL string) { // Method to register a filename
+	if registry.byName[name] == fileURL { // Already recorded
+		return // Nothing changed
+	}
+	if previousName, ok := registry.byURL[fileURL]; ok { // The URL moves to a new name (migration)
+		delete(registry.byName, previousName) // Forget the old name
+	}
+	registry.byName[name] = fileURL // Filename → URL
+	registry.byURL[fileURL] = name  // URL → filename
+	registry.dirty = true           // Remember to persist the change
+} // End of claim method
+
+// Frees a filename so it no longer maps to any URL
+func (registry *filenameRegistry) release(name string) { // Method to unregister a filename
+	sourceURL, ok := registry.byName[name] // Look up the owner
+	if !ok {                               // Not registered
+		return // Nothing to release
+	}
+	delete(registry.byName, name)     // Forget filename → URL
+	delete(registry.byURL, sourceURL) // Forget URL → filename
+	registry.dirty = true             // Remember to persist the change
+} // End of release method
+
+// Writes the filename map as sorted, indented JSON when it changed
+func (registry *filenameRegistry) save() error { // Method to persist the filename map
+	if !registry.dirty { // Nothing new to write
+		return nil // Skip the write
+	}
+	data, err := json.MarshalIndent(registry.byName, "", "  ") // Encode as indented JSON (map keys are sorted)
+	if err != nil {                                            // Handle encoding errors
+		return err // Propagate the error
+	}
+	if err := os.WriteFile(registry.path, append(data, '\n'), 0o644); err != nil { // Write the map
+		return err // Propagate the error
+	}
+	registry.dirty = false // The map is now in sync
+	return nil             // Save succeeded
+} // End of save method
+
+// Appends the first eight hex digits of the URL's SHA-256 to a filename (e.g. "manual_1a2b3c4d.pdf")
+func disambiguateFilename(name, fileURL string) string { // Function to build a deterministic unique filename
+	sum := sha256.Sum256([]byte(fileURL))                                       // Hash the full URL
+	extension := getFileExtension(name)                                         // Keep the extension last
+	stem := strings.TrimSuffix(name, extension)                                 // Everything before the extension
+	return fmt.Sprintf("%s_%s%s", stem, hex.EncodeToString(sum[:4]), extension) // Join stem, hash and extension
+} // End of disambiguateFilename function
+
+// Renames a file archived under the legacy naming scheme (and its history) to its collision-safe name
+func migrateArchivedFilename(fileURL, outputDirectory string) { // Function backing the -migrate-filenames mode
+	newName := archiveFilenames.filenameFor(fileURL)         // Name under the current scheme
+	legacyName := legacyURLToFilename(fileURL)               // Name the file was archived under before
+	newPath := filepath.Join(outputDirectory, newName)       // Target path
+	legacyPath := filepath.Join(outputDirectory, legacyName) // Source path
+
+	switch { // Decide what to do with this URL
+	case fileExists(newPath): // Already under the new name (or both schemes agree)
+		migrateFileHistory(newPath, newPath)     // Key its history by archive path
+		archiveFilenames.claim(newName, fileURL) // Record the mapping
+	case fileExists(legacyPath): // Archived under the legacy name only
+		if err := os.Rename(legacyPath, newPath); err != nil { // Move the file
+			log.Println(err) // Log the error
+			return           // Leave the registry untouched
+		}
+		migrateFileHistory(legacyPath, newPath)             // Move its history along with it
+		archiveFilenames.claim(newName, fileURL)            // Record the mapping
+		log.Printf("Migrated %s → %s", legacyPath, newPath) // Log the rename
+	default: // Never archived
+		log.Printf("Not archived yet, nothing to migrate: %s", fileURL) // Log the miss
+	}
+} // End of migrateArchivedFilename function
+
+// Moves history/<legacy name>, where history used to be keyed by filename alone, to the history directory of the
+// file's current archive path and rewrites the archived paths in its version log
+func migrateFileHistory(legacyPath, newPath string) { // Function to migrate a file's history directory
+	legacyHistory := filepath.Join(historyDirectory, filepath.Base(legacyPath)) // Old history directory
+	newHistory := fileHistoryDirectoryFor(newPath)                              // New history directory
+	if !directoryExists(legacyHistory) {                                        // Nothing was archived yet
+		return // Nothing to move
+	}
+	if err := os.MkdirAll(filepath.Dir(newHistory), 0o755); err != nil { // Ensure the archive directory exists under history/
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	if err := os.Rename(legacyHistory, newHistory); err != nil { // Move the directory
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	logPath := filepath.Join(newHistory, versionLogFilename) // Path of the moved version log
+	versions, err := readVersionLog(logPath)                 // Load the log
+	if err != nil {                                          // Handle unreadable logs
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	legacyPrefix := filepath.ToSlash(legacyHistory) + "/" // Prefix recorded in archived paths
+	newPrefix := filepath.ToSlash(newHistory) + "/"       // Prefix to record instead
+	for index := range versions {                         // Rewrite every archived path
+		versions[index].ArchivedAs = strings.Replace(versions[index].ArchivedAs, legacyPrefix, newPrefix, 1) // Point at the new directory
+	}
+	if err := writeVersionLog(logPath, versions); err != nil { // Persist the rewritten log
+		log.Println(err) // Log the error
+	}
+} // End of migrateFileHistory function
+
+// Reproduces the original urlToFilename scheme, which stripped every "_pdf", "_zip" and "_txt"
+// anywhere in the name; used only to find files archived before the collision-safe scheme
+func legacyURLToFilename(rawURL string) string { // Function to rebuild pre-migration filenames
+	lower := strings.ToLower(rawURL) // Convert the input URL to lowercase for consistency
+	lower = getFilename(lower)       // Extract just the filename part from the URL
+
+	// Get the file extension from the extracted filename
+	ext := getFileExtension(lower) // Get the original file extension (e.g., ".pdf" or ".zip")
+
+	reNonAlnum := regexp.MustCompile(`[^a-z0-9]`)   // Create a regex to match any non-alphanumeric characters
+	safe := reNonAlnum.ReplaceAllString(lower, "_") // Replace all non-alphanumeric characters with underscores
+
+	safe = regexp.MustCompile(`_+`).ReplaceAllString(safe, "_") // Replace multiple consecutive underscores with a single underscore
+	safe = strings.Trim(safe, "_")                              // Remove leading and trailing underscores from the filename
+
+	var invalidSubstrings = []string{ // Define a list of unwanted substrings to clean from the filename
+		"_pdf", // Common redundant suffix
+		"_zip", // Common redundant suffix
+		"_txt", // Common redundant suffix
+	} // End of invalid substrings slice
+
+	for _, invalidPre := range invalidSubstrings { // Iterate over the unwanted substrings
+		safe = strings.ReplaceAll(safe, invalidPre, "") // Remove each unwanted substring from the filename
+	} // End of substring removal loop
+
+	if getFileExtension(safe) == "" { // Check if the sanitized filename has no extension
+		safe = safe + ext // Append the original file extension (e.g., .pdf) to ensure completeness
+	}
+
+	return safe // Return the sanitized, safe filename
+} // End of legacyURLToFilename function
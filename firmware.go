@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"           // Searches firmware images for embedded strings
+	"encoding/binary" // Decodes image header fields
+	"encoding/json"   // Writes firmware.json
+	"errors"          // Creates inspection errors
+	"fmt"             // Formats chip names and errors
+	"io/fs"           // Walks extraction directories
+	"log"             // Logs catalog progress
+	"os"              // Reads extracted images
+	"path/filepath"   // Builds image paths
+	"regexp"          // Matches ELRS version strings
+	"sort"            // Orders catalog entries
+	"strconv"         // Compares version numbers
+	"strings"         // Normalizes names
+)
+
+const firmwareCatalogPath = "firmware.json" // Catalog of every firmware image found in extracted archives
+
+const espImageMagic = 0xe9                            // First byte of an ESP8266/ESP32 application image
+const espSegmentLimit = 16                            // Images never have more segments than this
+const elrsProductNameSize = 128                       // Size of the product name field appended to ELRS 3.x images
+const elrsDeviceNameSize = 16                         // Size of the device (Lua) name field that follows it (options and hardware JSON come next)
+var elrsTargetMarker = []byte{0xbe, 0xef, 0xca, 0xfe} // Precedes the ELRS build target name in every image
+
+var elrsVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+`)                                              // Release versions such as "3.2.0"
+var elrsVersionStringPattern = regexp.MustCompile(`(\d+\.\d+\.\d+(?:-[A-Za-z0-9.]+)?) \(([0-9a-f]{6,})\)`) // "3.2.0 (8d3388)" as shown in the web UI
+
+// Flash size codes in the high nibble of header byte 3, per chip family (from esptool)
+var esp8266FlashSizes = map[byte]string{0x0: "512KB", 0x1: "256KB", 0x2: "1MB", 0x3: "2MB", 0x4: "4MB", 0x5: "2MB", 0x6: "4MB", 0x8: "8MB", 0x9: "16MB"} // ESP8266 and ESP8285
+var esp32FlashSizes = map[byte]string{0x0: "1MB", 0x1: "2MB", 0x2: "4MB", 0x3: "8MB", 0x4: "16MB", 0x5: "32MB", 0x6: "64MB", 0x7: "128MB"}               // ESP32 family
+
+var espFlashModes = []string{"QIO", "QOUT", "DIO", "DOUT"} // Header byte 2
+
+// Chip IDs stored in the ESP32 extended image header
+var esp32ChipNames = map[uint16]string{0: "ESP32", 2: "ESP32-S2", 5: "ESP32-C3", 9: "ESP32-S3", 12: "ESP32-C2", 13: "ESP32-C6", 16: "ESP32-H2"} // Known chips
+
+// What an archived .bin image is, as read from the image itself
+type firmwareImage struct {
+	Path          string `json:"path"`                     // Extracted image (e.g. "ZIPs/x/x.bin")
+	SourceArchive string `json:"source_archive"`           // Archive it was extracted from
+	SourceURL     string `json:"source_url,omitempty"`     // Where that archive was downloaded from
+	Size          int64  `json:"size"`                     // Image size in bytes
+	SHA256        string `json:"sha256"`                   // SHA-256 of the image
+	Chip          string `json:"chip,omitempty"`           // ESP8266 or ESP32 variant
+	FlashSize     string `json:"flash_size,omitempty"`     // Flash size the image was built for
+	FlashMode     string `json:"flash_mode,omitempty"`     // SPI flash mode
+	ELRSVersion   string `json:"elrs_version,omitempty"`   // ExpressLRS release (e.g. "3.2.0")
+	ELRSCommit    string `json:"elrs_commit,omitempty"`    // ExpressLRS commit hash
+	ELRSTarget    string `json:"elrs_target,omitempty"`    // ExpressLRS build target (e.g. "DIY_2400_RX_ESP8285_SX1280")
+	ProductName   string `json:"product_name,omitempty"`   // Hardware product name configured into the image
+	DeviceName    string `json:"device_name,omitempty"`    // Name the receiver reports (ELRS Lua name)
+	HardwareNotes string `json:"hardware_notes,omitempty"` // Why hardware details are missing, if they are
+} // End of firmwareImage struct
+
+// Every image for one hardware target, newest first
+type firmwareTarget struct {
+	Latest string          `json:"latest,omitempty"` // Newest version found
+	Images []firmwareImage `json:"images"`           // Images sorted newest first
+} // End of firmwareTarget struct
+
+// Reads the ESP image header and the ELRS strings of a firmware image. Images that are neither ESP
+// application images nor ExpressLRS builds are rejected.
+func inspectFirmwareImage(data []byte) (*firmwareImage, error) { // Function to identify a firmware image
+	image := &firmwareImage{}                         // Collected details
+	imageEnd, espErr := inspectESPHeader(data, image) // Chip, flash size and where the image ends
+	inspectELRSStrings(data, image)                   // Version, commit and build target
+	if espErr != nil && image.ELRSTarget == "" {      // Nothing recognizable
+		return nil, espErr // Report why the header was rejected
+	}
+	if espErr != nil { // ELRS build for another MCU (e.g. STM32)
+		image.HardwareNotes = espErr.Error() // Explain the missing hardware details
+		return image, nil                    // The ELRS details still identify it
+	}
+	inspectELRSLayout(data[imageEnd:], image) // Product and device names appended after the image
+	return image, nil                         // Return the details
+} // End of inspectFirmwareImage function
+
+// Decodes the ESP image header and returns the offset just past the image. ESP8266 Arduino builds start with
+// the eboot loader image and carry the application at 0x1000; that second image is walked instead.
+func inspectESPHeader(data []byte, image *firmwareImage) (int, error) { // Function to read an ESP image header
+	if len(data) < 24 || data[0] != espImageMagic { // Not an ESP image
+		return 0, errors.New("no ESP8266/ESP32 image header") // Report the mismatch
+	}
+	entryPoint := binary.LittleEndian.Uint32(data[4:]) // Where execution starts
+	sizeCode := data[3] >> 4                           // Flash size code
+	if int(data[2]) < len(espFlashModes) {             // Known flash mode
+		image.FlashMode = espFlashModes[data[2]] // Record it
+	}
+
+	if entryPoint >= 0x40100000 && entryPoint < 0x40110000 { // ESP8266 instruction RAM
+		image.Chip = "ESP8266"                        // ESP8285 images are identical
+		image.FlashSize = esp8266FlashSizes[sizeCode] // Decode the size
+		imageEnd, err := walkESPSegments(data, 0, 8)  // The image at offset 0
+		if err != nil {                               // Corrupt header
+			return 0, err // Propagate the error
+		}
+		if imageEnd <= 0x1000 && len(data) > 0x1000 && data[0x1000] == espImageMagic { // eboot followed by the application
+			return walkESPSegments(data, 0x1000, 8) // The application image
+		}
+		return imageEnd, nil // Single image
+	}
+
+	chipID := binary.LittleEndian.Uint16(data[12:]) // Extended header chip ID
+	chip, ok := esp32ChipNames[chipID]              // Known ESP32 variant
+	if !ok {                                        // Unknown chip
+		return 0, fmt.Errorf("unknown ESP chip ID %d", chipID) // Report the mismatch
+	}
+	image.Chip = chip                             // Record the chip
+	image.FlashSize = esp32FlashSizes[sizeCode]   // Decode the size
+	imageEnd, err := walkESPSegments(data, 0, 24) // Header plus extended header
+	if err != nil {                               // Corrupt header
+		return 0, err // Propagate the error
+	}
+	if data[23] == 1 && imageEnd+32 <= len(data) { // SHA-256 of the image appended
+		imageEnd += 32 // Skip the digest
+	}
+	return imageEnd, nil // Return the end
+} // End of inspectESPHeader function
+
+// Walks the segments of the image at offset and returns the offset after its padded checksum
+func walkESPSegments(data []byte, offset, headerSize int) (int, error) { // Function to find where an image ends
+	segmentCount := int(data[offset+1])                      // Number of segments
+	if segmentCount == 0 || segmentCount > espSegmentLimit { // Implausible count
+		return 0, fmt.Errorf("ESP image has %d segments", segmentCount) // Report the corruption
+	}
+	position := offset + headerSize                       // First segment header
+	for segment := 0; segment < segmentCount; segment++ { // Skip every segment
+		if position+8 > len(data) { // Truncated segment header
+			return 0, errors.New("truncated ESP image") // Report the truncation
+		}
+		segmentSize := int(binary.LittleEndian.Uint32(data[position+4:])) // Segment length
+		if segmentSize > len(data)-position-8 {                           // Segment past the end
+			return 0, errors.New("truncated ESP image") // Report the truncation
+		}
+		position += 8 + segmentSize // Next segment header
+	}
+	position = (position + 16) &^ 15 // The checksum byte ends the next 16-byte boundary
+	if position > len(data) {        // Checksum missing
+		return 0, errors.New("truncated ESP image") // Report the truncation
+	}
+	return position, nil // Offset after the image
+} // End of walkESPSegments function
+
+// Finds the ELRS build target marker and the version and commit strings compiled in just before it
+func inspectELRSStrings(data []byte, image *firmwareImage) { // Function to read ELRS build strings
+	markerOffset := bytes.Index(data, elrsTargetMarker) // The target name follows the marker
+	if markerOffset >= 0 {                              // ExpressLRS build
+		target, _, _ := bytes.Cut(data[markerOffset+len(elrsTargetMarker):], []byte{0}) // NUL-terminated name
+		image.ELRSTarget = string(target)                                               // Record it
+		if markerOffset > 0 {                                                           // Preceded by "version\0commit\0" and a size byte
+			preceding := strings.Split(strings.TrimRight(string(data[max(0, markerOffset-64):markerOffset-1]), "\x00"), "\x00") // Strings before the size byte
+			if count := len(preceding); count >= 2 && elrsVersionPattern.MatchString(preceding[count-2]) {                      // version then commit
+				image.ELRSVersion = preceding[count-2] // Record the version
+				image.ELRSCommit = preceding[count-1]  // Record the commit
+				return                                 // Found both
+			}
+		}
+	}
+	if match := elrsVersionStringPattern.FindSubmatch(data); match != nil { // Fall back to the web UI version string
+		image.ELRSVersion = string(match[1]) // Record the version
+		image.ELRSCommit = string(match[2])  // Record the commit
+	}
+} // End of inspectELRSStrings function
+
+// Reads the product name, device name and JSON fields the ELRS configurator appends after the image
+func inspectELRSLayout(appended []byte, image *firmwareImage) { // Function to read appended ELRS names
+	if len(appended) < elrsProductNameSize+elrsDeviceNameSize { // Nothing appended
+		return // Leave the names empty
+	}
+	image.ProductName = fixedString(appended[:elrsProductNameSize])                                        // Hardware product name
+	image.DeviceName = fixedString(appended[elrsProductNameSize : elrsProductNameSize+elrsDeviceNameSize]) // Lua name, not NUL-terminated when 16 bytes long
+} // End of inspectELRSLayout function
+
+// Returns the printable text of a NUL-padded field, or "" when the field holds binary data
+func fixedString(field []byte) string { // Function to decode a fixed-size string
+	text, _, _ := bytes.Cut(field, []byte{0}) // Stop at the first NUL
+	for _, character := range text {          // Only printable ASCII is a name
+		if character < 0x20 || character > 0x7e { // Binary data
+			return "" // Not a string field
+		}
+	}
+	return strings.TrimSpace(string(text)) // Return the name
+} // End of fixedString function
+
+// Key of an image in the catalog: the name the hardware reports, falling back to the product and build target
+func firmwareTargetKey(image *firmwareImage) string { // Function to pick a catalog key
+	for _, name := range []string{image.DeviceName, image.ProductName, image.ELRSTarget} { // Most specific first
+		if name != "" { // Use the first name present
+			return name // Return it
+		}
+	}
+	return strings.TrimSuffix(getFilename(image.Path), getFileExtension(image.Path)) // Fall back to the filename
+} // End of firmwareTargetKey function
+
+// Compares version strings numerically ("3.10.0" > "3.2.0", "3.2.0.1" > "3.2.0"); a "-" suffix such as "-RC1" marks a
+// pre-release of the version before it. Returns -1, 0 or 1.
+func compareVersions(left, right string) int { // Function to order versions
+	leftBase, leftLabel, leftPre := strings.Cut(left, "-")                       // Split off a pre-release label
+	rightBase, rightLabel, rightPre := strings.Cut(right, "-")                   // Split off a pre-release label
+	leftParts := strings.Split(leftBase, ".")                                    // Dotted parts
+	rightParts := strings.Split(rightBase, ".")                                  // Dotted parts
+	for index := 0; index < len(leftParts) || index < len(rightParts); index++ { // Compare part by part
+		leftPart, rightPart := "0", "0" // Missing parts count as zero ("3.2" = "3.2.0")
+		if index < len(leftParts) {     // Left has this part
+			leftPart = leftParts[index] // Use it
+		}
+		if index < len(rightParts) { // Right has this part
+			rightPart = rightParts[index] // Use it
+		}
+		leftNumber, leftErr := strconv.Atoi(leftPart)    // Numeric value, if any
+		rightNumber, rightErr := strconv.Atoi(rightPart) // Numeric value, if any
+		switch {                                         // Compare numerically when both are numbers
+		case leftErr == nil && rightErr == nil && leftNumber != rightNumber: // Different numbers
+			if leftNumber < rightNumber { // Left is older
+				return -1 // Left first
+			}
+			return 1 // Right first
+		case (leftErr != nil || rightErr != nil) && leftPart != rightPart: // Different labels
+			return strings.Compare(leftPart, rightPart) // Compare as text
+		}
+	}
+	switch { // Same release: pre-releases sort before it
+	case leftPre && !rightPre: // Only left is a pre-release
+		return -1 // Left first
+	case !leftPre && rightPre: // Only right is a pre-release
+		return 1 // Right first
+	}
+	return strings.Compare(leftLabel, rightLabel) // Order pre-releases by label
+} // End of compareVersions function
+
+// Lists the extracted .bin files of every archive: from the manifest listing when there is one, otherwise by walking
+// the archive's extraction directory on disk (archives extracted before the manifest recorded them)
+func extractedFirmwarePaths(manifest *assetManifest) map[string][]string { // Function to find extracted images
+	images := map[string][]string{}                    // Archive path → extracted .bin paths
+	for archivePath, entry := range manifest.entries { // Archives with a listing
+		if entry.Archive == nil || entry.Archive.Status != archiveExtracted { // Only extracted images can be read
+			continue // Nothing to inspect
+		}
+		for _, archived := range entry.Archive.Entries { // Check every extracted file
+			if strings.EqualFold(getFileExtension(archived.Path), ".bin") { // Firmware images only
+				images[archivePath] = append(images[archivePath], archived.Path) // Record it
+			}
+		}
+	}
+	for _, directory := range archiveDirectories { // Archives without a listing
+		entries, err := os.ReadDir(directory) // List the directory
+		if err != nil {                       // Skip directories that do not exist
+			continue // Nothing to check
+		}
+		for _, directoryEntry := range entries { // Check every archive file
+			archivePath := filepath.ToSlash(filepath.Join(directory, directoryEntry.Name()))                               // Manifest key of the file
+			if directoryEntry.IsDir() || archiveFileTypeOf(archivePath) == "" || manifest.hasArchiveListing(archivePath) { // Not an archive, or already listed
+				continue // Nothing to walk
+			}
+			extracted := extractionDirectoryFor(archivePath)                                                            // Where it was unpacked
+			filepath.WalkDir(filepath.FromSlash(extracted), func(walkPath string, entry fs.DirEntry, err error) error { // Visit every file
+				if err == nil && !entry.IsDir() && strings.EqualFold(getFileExtension(walkPath), ".bin") { // Firmware image
+					images[archivePath] = append(images[archivePath], filepath.ToSlash(walkPath)) // Record it
+				}
+				return nil // Keep walking (a missing directory just yields nothing)
+			}) // End of directory walk
+		}
+	}
+	return images // Return the images
+} // End of extractedFirmwarePaths function
+
+// Rebuilds firmware.json from the .bin images of extracted archives, keyed by hardware target, so the newest image for
+// a receiver is the first entry of its target. An existing catalog is kept when no image is found at all.
+func rebuildFirmwareCatalog(manifest *assetManifest) error { // Function to regenerate the firmware catalog
+	images := extractedFirmwarePaths(manifest)     // Extracted images per archive
+	archivePaths := make([]string, 0, len(images)) // Archive paths in a stable order
+	for archivePath := range images {              // Collect every archive
+		archivePaths = append(archivePaths, archivePath) // Collect the path
+	}
+	sort.Strings(archivePaths) // Process archives in a deterministic order
+
+	catalog := map[string]*firmwareTarget{}    // Target → its images
+	for _, archivePath := range archivePaths { // Check every archive
+		originURL := ""                                           // Where the archive came from, if recorded
+		if entry := manifest.entries[archivePath]; entry != nil { // Provenance known
+			originURL = entry.OriginURL // Record it
+		}
+		for _, imagePath := range images[archivePath] { // Check every extracted image
+			data, err := os.ReadFile(filepath.FromSlash(imagePath)) // Read the image
+			if err != nil {                                         // Missing (e.g. stored remotely) or unreadable
+				log.Println(err) // Log the error
+				continue         // Move on
+			}
+			image, err := inspectFirmwareImage(data) // Identify the image
+			if err != nil {                          // Not a recognizable firmware image
+				log.Printf("Not cataloging %s: %v", imagePath, err) // Log the reason
+				continue                                            // Move on
+			}
+			image.Path = imagePath            // Where the image lives
+			image.SourceArchive = archivePath // Archive it came from
+			image.SourceURL = originURL       // Where the archive came from
+			image.Size = int64(len(data))     // Image size
+			image.SHA256 = sha256Hex(data)    // Image hash
+			key := firmwareTargetKey(image)   // Hardware target
+			if catalog[key] == nil {          // First image for the target
+				catalog[key] = &firmwareTarget{} // Start its listing
+			}
+			catalog[key].Images = append(catalog[key].Images, *image) // Add the image
+		}
+	}
+	if len(catalog) == 0 && fileExists(firmwareCatalogPath) { // Nothing found, e.g. before the archives are downloaded again
+		log.Printf("No firmware images found; keeping the existing %s", firmwareCatalogPath) // Log the skip
+		return nil                                                                           // Never replace a catalog with an empty one
+	}
+
+	for _, target := range catalog { // Order every target's images
+		sort.SliceStable(target.Images, func(left, right int) bool { // Newest first, unknown versions last
+			leftVersion, rightVersion := target.Images[left].ELRSVersion, target.Images[right].ELRSVersion // Versions to compare
+			if leftVersion == "" || rightVersion == "" {                                                   // An image without a version
+				return rightVersion == "" && leftVersion != "" // Known versions come first
+			}
+			return compareVersions(leftVersion, rightVersion) > 0 // Compare versions
+		})
+		target.Latest = target.Images[0].ELRSVersion // Newest version; empty only when no image has one
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ") // Encode with sorted target keys
+	if err != nil {                                    // Handle encoding errors
+		return err // Propagate the error
+	}
+	if err := writeFileAtomically(firmwareCatalogPath, append(data, '\n')); err != nil { // Replace the catalog in one step
+		return fmt.Errorf("write %s: %w", firmwareCatalogPath, err) // Report which file failed
+	}
+	log.Printf("Cataloged firmware for %d hardware targets in %s", len(catalog), firmwareCatalogPath) // Log the result
+	return nil                                                                                        // Catalog rebuilt
+} // End of rebuildFirmwareCatalog function
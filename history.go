@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"         // Compares downloaded data with the local copy
+	"crypto/sha256" // Computes content hashes for version tracking
+	"encoding/hex"  // Encodes hashes as hexadecimal strings
+	"encoding/json" // Reads and writes the per-file version log
+	"fmt"           // Formats archived file names
+	"log"           // Logs history lookups
+	"path/filepath" // Builds history paths
+	"time"          // Timestamps each version
+)
+
+const historyDirectory = "history/"               // Root directory holding superseded versions of upstream files
+const versionLogFilename = "versions.json"        // Name of the per-file version log inside each history directory
+const historyTimestampLayout = "20060102T150405Z" // Compact UTC timestamp used in archived file names
+
+// Describes one fetched version of an upstream file
+type fileVersion struct {
+	FetchedAt  time.Time `json:"fetched_at"`            // When this version was downloaded
+	SHA256     string    `json:"sha256"`                // SHA-256 of the file contents
+	Size       int64     `json:"size"`                  // Size of the file in bytes
+	SourceURL  string    `json:"source_url"`            // URL the file was downloaded from
+	SourcePage string    `json:"source_page,omitempty"` // Page that linked to the file
+	ArchivedAs string    `json:"archived_as,omitempty"` // Path of the archived copy once this version was superseded
+} // End of fileVersion struct
+
+// Writes downloaded data to filePath in the archive storage, moving any different previous version into history first.
+// Returns false when the local file already has identical contents.
+func storeFileVersion(filePath string, data []byte, sourceURL, sourcePage string) (bool, error) { // Function to store a new version of a file
+	newHash := sha256Hex(data)                                         // Hash of the freshly downloaded data
+	fileHistoryDirectory := fileHistoryDirectoryFor(filePath)          // Directory holding this file's history
+	logPath := filepath.Join(fileHistoryDirectory, versionLogFilename) // Path of this file's version log
+
+	versions, err := readVersionLog(logPath) // Load the existing version log, if any
+	if err != nil {                          // Handle unreadable logs
+		return false, err // Refuse to continue rather than lose history
+	}
+
+	storageName := filepath.ToSlash(filePath)            // Name of the file in the archive storage
+	existingData, err := archiveStorage.Get(storageName) // Read the current stored copy, if any
+	switch {                                             // Decide what to do with the previous version
+	case err == nil: // A previous version exists
+		if bytes.Equal(existingData, data) { // Contents have not changed upstream
+			return false, nil // Nothing to do
+		}
+		versions, err = archiveFileVersion(storageName, existingData, fileHistoryDirectory, versions) // Move the old version into history
+		if err != nil {                                                                               // Handle archive errors
+			return false, err // Propagate the error
+		}
+	case isNotExist(err) && latestVersionHash(versions) == newHash: // Write-only backends (bundles) cannot show the logged copy
+		return false, archiveStorage.Put(storageName, data) // Store it without logging a new version
+	case !isNotExist(err): // Storage failure other than a missing file
+		return false, err // Propagate the error
+	}
+
+	if err := archiveStorage.Put(storageName, data); err != nil { // Write the new version in place
+		return false, err // Propagate the error
+	}
+
+	versions = append(versions, fileVersion{ // Record the new version in the log
+		FetchedAt:  time.Now().UTC(), // Fetch time
+		SHA256:     newHash,          // Content hash
+		Size:       int64(len(data)), // Content size
+		SourceURL:  sourceURL,        // Origin URL
+		SourcePage: sourcePage,       // Linking page
+	}) // End of new log entry
+	return true, writeVersionLog(logPath, versions) // Persist the updated log
+} // End of storeFileVersion function
+
+// Returns the history directory of an archived file, keyed by its full archive path (e.g. "history/PDFs/manual.pdf")
+// so files with the same name in different archive directories keep separate histories
+func fileHistoryDirectoryFor(archivePath string) string { // Function to locate a file's history
+	return filepath.Join(historyDirectory, filepath.Clean(archivePath)) // Mirror the archive layout under history/
+} // End of fileHistoryDirectoryFor function
+
+// Returns the hash of the newest version in a log that has not been moved into history, or "" if there is none
+func latestVersionHash(versions []fileVersion) string { // Function to find the live version
+	if len(versions) == 0 || versions[len(versions)-1].ArchivedAs != "" { // Nothing live
+		return "" // No current version
+	}
+	return versions[len(versions)-1].SHA256 // Hash of the current version
+} // End of latestVersionHash function
+
+// Moves the current stored copy of a file into its history directory and marks it archived in the log
+func archiveFileVersion(storageName string, existingData []byte, fileHistoryDirectory string, versions []fileVersion) ([]fileVersion, error) { // Function to archive a superseded version
+	oldHash := sha256Hex(existingData) // Hash of the version being replaced
+
+	entryIndex := -1                                      // Index of the log entry describing the old version
+	for index := len(versions) - 1; index >= 0; index-- { // Search the log from newest to oldest
+		if versions[index].SHA256 == oldHash && versions[index].ArchivedAs == "" { // Find the live entry for this hash
+			entryIndex = index // Remember its position
+			break              // Stop at the newest match
+		}
+	}
+	if entryIndex == -1 { // The file predates the version log
+		info, err := archiveStorage.Stat(storageName) // Use the file's modification time as the best known fetch time
+		if err != nil {                               // Handle stat errors
+			return versions, err // Propagate the error
+		}
+		versions = append(versions, fileVersion{ // Backfill an entry for the untracked version
+			FetchedAt: info.ModTime.UTC(),       // Best known fetch time
+			SHA256:    oldHash,                  // Content hash
+			Size:      int64(len(existingData)), // Content size
+		}) // End of backfilled entry
+		entryIndex = len(versions) - 1 // Point at the backfilled entry
+	}
+
+	extension := getFileExtension(storageName)                                                                                     // Keep the original extension on the archived copy
+	archivedName := fmt.Sprintf("%s-%s%s", versions[entryIndex].FetchedAt.Format(historyTimestampLayout), oldHash[:12], extension) // Build the <timestamp>-<sha> name
+	archivedPath := filepath.Join(fileHistoryDirectory, archivedName)                                                              // Full path of the archived copy
+
+	if err := archiveStorage.Move(storageName, filepath.ToSlash(archivedPath)); err != nil { // Move the old version into history
+		return versions, err // Propagate the error
+	}
+	versions[entryIndex].ArchivedAs = filepath.ToSlash(archivedPath) // Record where the old version now lives
+	return versions, nil                                             // Return the updated log
+} // End of archiveFileVersion function
+
+// Reads a version log from the archive storage, returning an empty log when none exists yet
+func readVersionLog(logPath string) ([]fileVersion, error) { // Function to load a version log
+	data, err := archiveStorage.Get(filepath.ToSlash(logPath)) // Read the log next to the archived versions
+	if isNotExist(err) {                                       // No history recorded yet
+		return nil, nil // Start with an empty log
+	}
+	if err != nil { // Handle other read errors
+		return nil, err // Propagate the error
+	}
+	var versions []fileVersion                              // Decoded log entries
+	if err := json.Unmarshal(data, &versions); err != nil { // Decode the JSON log
+		return nil, fmt.Errorf("parse %s: %w", logPath, err) // Report which log is corrupt
+	}
+	return versions, nil // Return the decoded entries
+} // End of readVersionLog function
+
+// Writes a version log as indented JSON to the archive storage, so it lands next to the archived versions it
+// describes and changes diff cleanly in git
+func writeVersionLog(logPath string, versions []fileVersion) error { // Function to persist a version log
+	data, err := json.MarshalIndent(versions, "", "  ") // Encode the log as indented JSON
+	if err != nil {                                     // Handle encoding errors
+		return err // Propagate the error
+	}
+	return archiveStorage.Put(filepath.ToSlash(logPath), append(data, '\n')) // Write the log with a trailing newline
+} // End of writeVersionLog function
+
+// Finds the version of a file that was current at the given time, or nil if it had not been fetched yet
+func fileVersionAt(versions []fileVersion, at time.Time) *fileVersion { // Function to answer "what did this file look like then?"
+	var current *fileVersion      // Newest version fetched at or before the given time
+	for index := range versions { // Walk the log in fetch order
+		if versions[index].FetchedAt.After(at) { // Skip versions fetched later
+			continue // Move to the next entry
+		}
+		if current == nil || versions[index].FetchedAt.After(current.FetchedAt) { // Keep the newest qualifying version
+			current = &versions[index] // Remember it
+		}
+	}
+	return current // Return the match, if any
+} // End of fileVersionAt function
+
+// Prints which version of an archived file (e.g. "TXTs/dump.txt") was current on the given date (YYYY-MM-DD) and
+// where it is stored
+func printFileVersionAt(archivePath, date string) { // Function backing the -history command-line mode
+	at := time.Now().UTC() // Default to the latest version
+	if date != "" {        // Parse the requested date when one was given
+		parsed, err := time.Parse(time.DateOnly, date) // Expect YYYY-MM-DD
+		if err != nil {                                // Handle malformed dates
+			log.Println(err) // Log the parse error
+			return           // Nothing more to do
+		}
+		at = parsed.Add(24*time.Hour - time.Nanosecond) // Include everything fetched on that day
+	}
+
+	logPath := filepath.Join(fileHistoryDirectoryFor(archivePath), versionLogFilename) // Path of the file's version log
+	versions, err := readVersionLog(logPath)                                           // Load the log
+	if err != nil {                                                                    // Handle unreadable logs
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	version := fileVersionAt(versions, at) // Find the version current at that time
+	if version == nil {                    // Nothing had been fetched yet
+		log.Printf("No recorded version of %s at %s", archivePath, at.Format(time.DateOnly)) // Report the miss
+		return                                                                               // Nothing more to do
+	}
+
+	location := version.ArchivedAs // Superseded versions live in history
+	if location == "" {            // The version is still the live copy
+		location = "(current copy)" // Point at the live file
+	}
+	fmt.Printf("%s\t%s\t%d bytes\t%s\t%s\n", version.FetchedAt.Format(time.RFC3339), version.SHA256, version.Size, version.SourcePage, location) // Print the version details
+} // End of printFileVersionAt function
+
+// Returns the hexadecimal SHA-256 hash of the given data
+func sha256Hex(data []byte) string { // Function to hash data
+	sum := sha256.Sum256(data)        // Compute the SHA-256 digest
+	return hex.EncodeToString(sum[:]) // Encode it as lowercase hex
+} // End of sha256Hex function
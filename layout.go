@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json" // Writes per-product index files
+	"fmt"           // Formats layout errors
+	"io"            // Copies files when hard links are unavailable
+	"log"           // Logs layout progress
+	"net/url"       // Parses source page URLs
+	"os"            // Provides file system access
+	"path"          // Splits URL paths
+	"path/filepath" // Builds layout paths
+	"regexp"        // Sanitizes product slugs
+	"sort"          // Orders product listings
+	"strings"       // Manipulates paths and slugs
+)
+
+const productsDirectory = "products/"     // Root of the product-oriented layout
+const sharedProductSlug = "_shared"       // Area for assets linked from several products (or none)
+const productIndexFilename = "index.json" // Per-product listing of every asset, including shared ones
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9-]+`) // Anything not allowed in a product slug
+
+// Category directory inside a product for each archived file extension
+var productCategories = map[string]string{ // Keyed by lowercase extension
+	".pdf": "manuals",  // User manuals and guides
+	".txt": "cli",      // Betaflight CLI dumps
+	".zip": "firmware", // Firmware bundles and tools
+	".rar": "firmware", // Firmware bundles and tools published as RAR
+	".7z":  "firmware", // Firmware bundles and tools published as 7z
+	".tar": "firmware", // Firmware bundles and tools published as tar
+	".tgz": "firmware", // Firmware bundles and tools published as tar.gz
+	".gz":  "firmware", // ".tar.gz" archives (getFileExtension only sees ".gz")
+} // End of productCategories map
+
+// One asset listed in a product's index
+type productAsset struct {
+	Path     string `json:"path"`     // Path inside products/ (may point into _shared)
+	Archive  string `json:"archive"`  // Path in the by-type archive (e.g. "PDFs/x.pdf")
+	Category string `json:"category"` // manuals, cli or firmware
+	Shared   bool   `json:"shared"`   // Whether several products link to the asset
+} // End of productAsset struct
+
+// Derives a product slug from a source page URL ("https://geprc.com/downloads/cinelog35-v2/" → "cinelog35-v2").
+// Top-level listing pages such as /downloads/ are not products and return "".
+func productSlugFromPage(pageURL string) string { // Function to map a page to a product
+	parsedURL, err := url.Parse(pageURL) // Parse the page URL
+	if err != nil {                      // Handle malformed URLs
+		return "" // Not a product page
+	}
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/") // Split the path into segments
+	if len(segments) < 2 {                                            // Listing pages sit directly under the site root
+		return "" // Not a product page
+	}
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(path.Base(parsedURL.Path)), "-") // Sanitize the last segment
+	return strings.Trim(slug, "-")                                                              // Drop stray separators
+} // End of productSlugFromPage function
+
+// Returns the distinct product slugs that link to a manifest entry, sorted
+func productSlugsForEntry(entry *manifestEntry) []string { // Function to find an asset's products
+	var slugs []string                             // Collected product slugs
+	for _, sourcePage := range entry.SourcePages { // Check every linking page
+		addSorted(&slugs, productSlugFromPage(sourcePage)) // Ignores listing pages (empty slugs)
+	}
+	return slugs // Return the sorted, unique slugs
+} // End of productSlugsForEntry function
+
+// Rebuilds products/<slug>/{manuals,cli,firmware} from the manifest. Assets linked from one product
+// live in that product; assets linked from several (or only from listing pages) live in products/_shared
+// and are listed in each product's index.json.
+func rebuildProductLayout(manifest *assetManifest) error { // Function to regenerate the product layout
+	if err := os.RemoveAll(productsDirectory); err != nil { // The layout is derived data; start from scratch
+		return err // Propagate the error
+	}
+
+	archivePaths := make([]string, 0, len(manifest.entries)) // Archive paths in a stable order
+	for archivePath := range manifest.entries {              // Collect every manifest key
+		archivePaths = append(archivePaths, archivePath) // Collect the path
+	}
+	sort.Strings(archivePaths) // Process files in a deterministic order
+
+	productIndexes := map[string][]productAsset{} // Product slug → its assets
+	for _, archivePath := range archivePaths {    // Place every archived file
+		if !fileExists(archivePath) { // The manifest may describe files that were pruned
+			continue // Nothing to place
+		}
+		category, ok := productCategories[strings.ToLower(getFileExtension(archivePath))] // Pick the category directory
+		if !ok {                                                                          // Unknown file types are left out
+			continue // Nothing to place
+		}
+
+		slugs := productSlugsForEntry(manifest.entries[archivePath]) // Products linking to the file
+		ownerSlug := sharedProductSlug                               // Default to the shared area
+		if len(slugs) == 1 {                                         // Exactly one product links to the file
+			ownerSlug = slugs[0] // The file belongs to that product
+		}
+
+		layoutPath := filepath.Join(productsDirectory, ownerSlug, category, filepath.Base(archivePath)) // Where the file goes
+		if err := linkOrCopyFile(archivePath, layoutPath); err != nil {                                 // Place the file
+			return fmt.Errorf("place %s: %w", archivePath, err) // Report which file failed
+		}
+
+		relativePath, err := filepath.Rel(productsDirectory, layoutPath) // Path relative to products/
+		if err != nil {                                                  // Handle unrelated paths
+			return err // Propagate the error
+		}
+		asset := productAsset{ // Describe the placed file
+			Path:     filepath.ToSlash(relativePath), // Path relative to products/
+			Archive:  archivePath,                    // By-type archive path
+			Category: category,                       // Category directory
+			Shared:   ownerSlug == sharedProductSlug, // Whether it lives in the shared area
+		} // End of asset description
+		if len(slugs) == 0 { // Only listing pages link to the file
+			slugs = []string{sharedProductSlug} // List it under the shared area itself
+		}
+		for _, slug := range slugs { // Add the asset to every linking product's index
+			productIndexes[slug] = append(productIndexes[slug], asset) // Append to the product's listing
+		}
+	}
+
+	for slug, assets := range productIndexes { // Write every product's index
+		data, err := json.MarshalIndent(assets, "", "  ") // Encode the listing
+		if err != nil {                                   // Handle encoding errors
+			return err // Propagate the error
+		}
+		indexPath := filepath.Join(productsDirectory, slug, productIndexFilename) // Path of the index
+		if err := os.MkdirAll(filepath.Dir(indexPath), 0o755); err != nil {       // Ensure the product directory exists
+			return err // Propagate the error
+		}
+		if err := os.WriteFile(indexPath, append(data, '\n'), 0o644); err != nil { // Write the index
+			return err // Propagate the error
+		}
+	}
+	log.Printf("Rebuilt %s for %d products from %d manifest entries", productsDirectory, len(productIndexes), len(manifest.entries)) // Log the result
+	return nil                                                                                                                       // Layout rebuilt
+} // End of rebuildProductLayout function
+
+// Hard-links source to destination (git stores identical blobs once either way), copying when linking fails
+func linkOrCopyFile(source, destination string) error { // Function to place a file in the layout
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil { // Ensure the target directory exists
+		return err // Propagate the error
+	}
+	if err := os.Link(source, destination); err == nil { // Try a hard link first
+		return nil // Linked without copying
+	}
+	sourceFile, err := os.Open(source) // Fall back to copying (e.g. across file systems)
+	if err != nil {                    // Handle open errors
+		return err // Propagate the error
+	}
+	defer sourceFile.Close()                       // Close the source when done
+	destinationFile, err := os.Create(destination) // Create the copy
+	if err != nil {                                // Handle creation errors
+		return err // Propagate the error
+	}
+	if _, err := io.Copy(destinationFile, sourceFile); err != nil { // Copy the contents
+		destinationFile.Close() // Release the handle
+		return err              // Propagate the error
+	}
+	return destinationFile.Close() // Flush and close the copy
+} // End of linkOrCopyFile function
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"    // Buffers response bodies
+	"errors"   // Defines the limit error
+	"fmt"      // Formats limit reasons
+	"io"       // Streams response bodies through a limit
+	"log"      // Logs stopped downloads
+	"net/http" // Reads Content-Length from responses
+)
+
+const defaultRunByteBudget = 2 << 30 // Default total number of bytes a single run may download (2 GiB)
+
+// Maximum accepted size per asset type
+var downloadSizeLimits = map[string]int64{ // Keyed by the file type passed to downloadFile
+	"PDF": 200 << 20, // Manuals rarely exceed a few dozen MiB
+	"ZIP": 500 << 20, // Firmware bundles and tools
+	"TXT": 5 << 20,   // CLI dumps are a few dozen KiB
+	"TAR": 500 << 20, // Firmware bundles and tools published as tar or tar.gz
+	"RAR": 500 << 20, // Firmware bundles and tools published as RAR
+	"7Z":  500 << 20, // Firmware bundles and tools published as 7z
+} // End of downloadSizeLimits map
+
+var errDownloadLimit = errors.New("download limit exceeded") // Returned when a size limit or the run budget stops a download
+
+// Records a download that was stopped by a limit
+type stoppedDownload struct {
+	URL      string `json:"url"`       // URL that was being downloaded
+	FileType string `json:"file_type"` // Asset type (PDF, ZIP, TXT)
+	Reason   string `json:"reason"`    // Which limit was hit
+} // End of stoppedDownload struct
+
+// Tracks bytes transferred during a run against a total budget
+type downloadBudget struct {
+	limit   int64             // Total bytes allowed for the run
+	used    int64             // Bytes transferred so far
+	stopped []stoppedDownload // Downloads stopped by any limit
+} // End of downloadBudget struct
+
+var runBudget = &downloadBudget{limit: defaultRunByteBudget} // Budget shared by every download in this run
+
+// Reads a response body, enforcing the per-type size limit and the run budget
+// both against Content-Length and while streaming
+func readLimitedBody(fileURL, fileType string, response *http.Response) ([]byte, error) { // Function to read a body under limits
+	typeLimit, ok := downloadSizeLimits[fileType] // Look up the limit for this asset type
+	if !ok {                                      // Unknown asset types only get the run budget
+		typeLimit = runBudget.limit // Fall back to the whole budget
+	}
+	remaining := runBudget.limit - runBudget.used // Bytes still available in this run
+
+	if response.ContentLength > typeLimit { // Reject oversized files before reading anything
+		return nil, runBudget.stop(fileURL, fileType, fmt.Sprintf("Content-Length %d exceeds %s limit of %d bytes", response.ContentLength, fileType, typeLimit)) // Record and report the stop
+	}
+	if response.ContentLength > remaining { // Reject files the run can no longer afford
+		return nil, runBudget.stop(fileURL, fileType, fmt.Sprintf("Content-Length %d exceeds remaining run budget of %d bytes", response.ContentLength, remaining)) // Record and report the stop
+	}
+
+	limit := min(typeLimit, remaining)                                         // Effective limit for this download
+	var buffer bytes.Buffer                                                    // Buffer to store the downloaded data
+	bytesRead, err := io.Copy(&buffer, io.LimitReader(response.Body, limit+1)) // Read one byte past the limit to detect overruns
+	runBudget.used += bytesRead                                                // Partial transfers still count against the budget
+	if err != nil {                                                            // Handle read errors
+		return nil, err // Propagate the error
+	}
+	if bytesRead > limit { // The stream kept going past the limit
+		if limit == typeLimit { // The per-type limit was hit
+			return nil, runBudget.stop(fileURL, fileType, fmt.Sprintf("stream exceeded %s limit of %d bytes", fileType, typeLimit)) // Record and report the stop
+		}
+		return nil, runBudget.stop(fileURL, fileType, fmt.Sprintf("stream exceeded remaining run budget of %d bytes", remaining)) // Record and report the stop
+	}
+	return buffer.Bytes(), nil // Return the complete body
+} // End of readLimitedBody function
+
+// Records a stopped download and returns the matching error
+func (budget *downloadBudget) stop(fileURL, fileType, reason string) error { // Method to record a limit stop
+	budget.stopped = append(budget.stopped, stoppedDownload{URL: fileURL, FileType: fileType, Reason: reason}) // Remember the stop for the report
+	return fmt.Errorf("%w: %s", errDownloadLimit, reason)                                                      // Wrap the sentinel error with the reason
+} // End of stop method
+
+// Logs the bytes transferred in this run and every download stopped by a limit
+func (budget *downloadBudget) logReport() { // Method to print the limit report
+	log.Printf("Transferred %d of %d budgeted bytes", budget.used, budget.limit) // Log budget usage
+	for _, stopped := range budget.stopped {                                     // Log every stopped download
+		log.Printf("Stopped by limit: %s [%s] %s", stopped.URL, stopped.FileType, stopped.Reason) // Log the stop
+	}
+} // End of logReport method
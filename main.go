@@ -1,23 +1,192 @@
 package main
 
 import (
-	"bytes"         // Provides a way to work with byte slices (like a buffer)
 	"context"       // Manages request-scoped values, cancellation signals, and deadlines
-	"io"            // Provides basic interfaces for I/O primitives
+	"errors"        // Inspects wrapped errors
+	"flag"          // Parses command-line flags
+	"fmt"           // Formats error messages
 	"log"           // Implements simple logging, often to os.Stderr
 	"net/http"      // Provides HTTP client and server implementations
 	"net/url"       // Parses URLs and implements query escaping
 	"os"            // Provides platform-independent interface to operating system functionality
 	"path/filepath" // Implements utility routines for manipulating filepaths in a way appropriate for the operating system
 	"regexp"        // Implements regular expression search
+	"slices"        // Concatenates link lists
 	"strings"       // Implements simple functions to manipulate strings
 	"time"          // Provides functionality for measuring and displaying time
 
-	"github.com/chromedp/chromedp" // Chromedp library for driving a headless Chrome browser
-	"golang.org/x/net/html"        // Provides an HTML parser
+	"github.com/Strong-Foundation/geprc-com-documentation/betaflight" // CLI dump parser and validators
+	"github.com/chromedp/chromedp"                                    // Chromedp library for driving a headless Chrome browser
+	"golang.org/x/net/html"                                           // Provides an HTML parser
 )
 
 func main() { // Main function, the entry point of the program
+	historyFile := flag.String("history", "", "print the archived version of this file (e.g. TXTs/mark5_analog_4_2_11_crsf.txt) instead of scraping")                    // Name of a file to look up in history
+	historyDate := flag.String("at", "", "date (YYYY-MM-DD) used with -history; defaults to today")                                                                      // Date to look up
+	proxy := flag.String("proxy", "", "proxy URL used by Chrome and all downloads (defaults to HTTP(S)_PROXY)")                                                          // Optional proxy
+	browserFallback := flag.Bool("browser-fallback", true, "retry HTTP downloads blocked by the server (403, 429, 503, challenge page) in Chrome")                       // Chrome download fallback
+	migrateFilenames := flag.Bool("migrate-filenames", false, "rename files archived under the legacy naming scheme instead of downloading")                             // Filename migration mode
+	layout := flag.String("layout", "type", `archive layout: "type" (PDFs/, ZIPs/, TXTs/ only) or "products" (also products/<slug>/{manuals,cli,firmware})`)             // Archive layout
+	rebuildLayout := flag.Bool("rebuild-layout", false, "rebuild products/ from manifest.json without scraping")                                                         // Layout rebuild mode
+	storageSpec := flag.String("storage", "local", "where archived files are written: local, tar:<path>, zip:<path> or s3://<bucket>/<prefix>")                          // Storage backend
+	exportOnly := flag.Bool("export", false, "copy the local archive into -storage without scraping (e.g. to build a release bundle)")                                   // Export mode
+	orphans := flag.Bool("orphans", false, "list archived files no longer linked by the latest crawl and mark them withdrawn upstream")                                  // Orphan detection mode
+	prune := flag.Bool("prune", false, "permanently delete the archived files given as arguments (e.g. PDFs/wrong.pdf) with their history")                              // Prune mode
+	extractOnly := flag.Bool("extract", false, "re-extract every downloaded archive into its normalized directory without scraping")                                     // Extraction mode
+	firmwareCatalog := flag.Bool("firmware-catalog", false, "rebuild firmware.json from the extracted .bin images without scraping")                                     // Firmware catalog mode
+	checkDumpFiles := flag.Bool("check-dumps", false, "parse every CLI dump in TXTs/ and print the lines that could not be parsed, without scraping")                    // Dump check mode
+	dumpCatalog := flag.Bool("dump-catalog", false, "rebuild dumps.json from the CLI dumps in TXTs/ without scraping")                                                   // Dump catalog mode
+	dumpQuery := flag.String("find-dumps", "", `list the CLI dumps matching a query such as "mcu=STM32G47X version=4.5.x" without scraping`)                             // Dump query mode
+	checkPins := flag.Bool("check-pins", false, "list pins claimed by several resources in every CLI dump in TXTs/, without scraping")                                   // Pin conflict mode
+	checkTimers := flag.Bool("check-timers", false, "list timer and DMA conflicts in every CLI dump in TXTs/, without scraping")                                         // Timer and DMA check mode
+	timerMap := flag.String("timer-map", "", "print the timer channels and DMA streams of one CLI dump (e.g. TXTs/mark5_analog_4_2_11_crsf.txt), without scraping")      // Timer map mode
+	uartMap := flag.Bool("uart-map", false, "print the decoded serial ports (UART, function names, baud rates) of every CLI dump in TXTs/, without scraping")            // UART map mode
+	switchTable := flag.Bool("switch-table", false, "print the decoded aux mode switches of every CLI dump in TXTs/ with overlap and ARM warnings, without scraping")    // Switch table mode
+	checkFeatures := flag.Bool("check-features", false, "list feature names unknown to the dump's firmware release in every CLI dump in TXTs/, without scraping")        // Feature check mode
+	featureMatrix := flag.Bool("feature-matrix", false, "print a CSV matrix of the features every CLI dump in TXTs/ turns on or off, without scraping")                  // Feature matrix mode
+	ledLayout := flag.String("led-layout", "", "draw the LED strip layout of one CLI dump (e.g. TXTs/cinelog35_analog_sbus_4_2_3.txt) as a text grid, without scraping") // LED layout mode
+	ledSVG := flag.Bool("led-svg", false, "with -led-layout, print the layout as SVG instead of a text grid")                                                            // LED layout output format
+	checkVTXTables := flag.Bool("check-vtxtables", false, "validate the vtxtable of every CLI dump and vtxtable file in TXTs/, without scraping")                        // VTX table check mode
+	vtxTableJSON := flag.String("vtxtable-json", "", "print the vtxtable of one file in TXTs/ as a Betaflight Configurator VTX config file, without scraping")           // VTX table export mode
+	dryRun := flag.Bool("dry-run", false, "scrape pages and print what would be fetched, updated, skipped or rejected, downloading nothing")                             // Dry-run mode
+	planJSON := flag.Bool("plan-json", false, "with -dry-run, print the plan as JSON instead of tab-separated lines")                                                    // Dry-run output format
+	maxRunBytes := flag.Int64("max-run-bytes", defaultRunByteBudget, "total number of bytes this run may download")                                                      // Run bandwidth budget
+	flag.Parse()                                                                                                                                                         // Parse command-line flags
+
+	if *historyFile != "" { // History lookup mode
+		printFileVersionAt(*historyFile, *historyDate) // Print the matching version
+		return                                         // Skip scraping
+	}
+	localArchive := isLocalStorageSpec(*storageSpec)                                                                      // Whether archived files live in the working tree
+	if !localArchive && (*orphans || *prune || *extractOnly || *firmwareCatalog || *rebuildLayout || *migrateFilenames) { // These modes edit the working tree directly
+		log.Fatalf("-storage %s only applies to scraping and -export; -orphans, -prune, -extract, -firmware-catalog, -rebuild-layout and -migrate-filenames work on the local archive", *storageSpec) // Refuse the combination
+	}
+	if *dryRun && *migrateFilenames { // Migration renames files, which a dry run must not do
+		log.Fatalln("-dry-run cannot be combined with -migrate-filenames, which renames archived files") // Refuse the combination
+	}
+	if *exportOnly { // Export mode
+		storage, err := openStorage(*storageSpec) // Open the export target
+		if err != nil {                           // Handle bad specs or missing credentials
+			log.Fatalln(err) // Nothing can be exported without a backend
+		}
+		if err := exportLocalArchive(storage, append(archiveDirectories, historyDirectory)); err != nil { // Copy the local archive
+			log.Println(err) // Log the failure
+		}
+		if err := storage.Close(); err != nil { // Finish bundles and release connections
+			log.Println(err) // Log the close error
+		}
+		return // Skip scraping
+	}
+	if *orphans { // Orphan detection mode
+		printOrphans() // List and mark files no longer linked upstream
+		return         // Skip scraping
+	}
+	if *prune { // Prune mode
+		pruneArchivedFiles(flag.Args()) // Delete the listed files
+		return                          // Skip scraping
+	}
+	if *extractOnly { // Extraction mode
+		extractArchivedFiles("ZIPs/") // Unpack every downloaded archive
+		return                        // Skip scraping
+	}
+	if *checkDumpFiles { // Dump check mode
+		if checkDumps(dumpDirectory) > 0 { // Some dumps have unparsable lines
+			os.Exit(1) // Fail CI
+		}
+		return // Skip scraping
+	}
+	if *checkPins { // Pin conflict mode
+		printDumpFindings(dumpDirectory, betaflight.CheckPins) // Validate every dump's resources
+		return                                                 // Skip scraping
+	}
+	if *checkTimers { // Timer and DMA check mode
+		printDumpFindings(dumpDirectory, betaflight.CheckTimers) // Validate every dump's timers and DMA streams
+		return                                                   // Skip scraping
+	}
+	if *timerMap != "" { // Timer map mode
+		if err := printTimerMap(*timerMap); err != nil { // Print the board's timer and DMA usage
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *uartMap { // UART map mode
+		printUARTMaps(dumpDirectory) // Print every product's serial ports
+		return                       // Skip scraping
+	}
+	if *switchTable { // Switch table mode
+		printSwitchTables(dumpDirectory) // Print every factory config's switches
+		return                           // Skip scraping
+	}
+	if *checkFeatures { // Feature check mode
+		printDumpFindings(dumpDirectory, betaflight.CheckFeatures) // Validate every dump's feature names
+		return                                                     // Skip scraping
+	}
+	if *featureMatrix { // Feature matrix mode
+		if err := printFeatureMatrix(dumpDirectory); err != nil { // Print the matrix
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *ledLayout != "" { // LED layout mode
+		if err := printLEDLayout(*ledLayout, *ledSVG); err != nil { // Draw the board's LED strip
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *checkVTXTables { // VTX table check mode
+		printDumpFindings(dumpDirectory, betaflight.CheckVTXTable) // Validate every vtxtable
+		return                                                     // Skip scraping
+	}
+	if *vtxTableJSON != "" { // VTX table export mode
+		if err := printVTXTableJSON(*vtxTableJSON); err != nil { // Export the table
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *dumpCatalog { // Dump catalog mode
+		if err := rebuildDumpCatalog(); err != nil { // Regenerate dumps.json
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *dumpQuery != "" { // Dump query mode
+		if err := findDumps(*dumpQuery); err != nil { // Print the matching dumps
+			log.Fatalln(err) // Report the bad query
+		}
+		return // Skip scraping
+	}
+	if *firmwareCatalog { // Firmware catalog mode
+		if err := rebuildFirmwareCatalog(downloadManifest); err != nil { // Regenerate firmware.json from the manifest
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	if *rebuildLayout { // Layout rebuild mode
+		if err := rebuildProductLayout(downloadManifest); err != nil { // Regenerate products/ from the manifest
+			log.Fatalln(err) // Report the failure
+		}
+		return // Skip scraping
+	}
+	runBudget.limit = *maxRunBytes                         // Apply the run bandwidth budget
+	browserFallbackEnabled = *browserFallback              // Enable or disable the Chrome download fallback
+	if err := sharedSession.setProxy(*proxy); err != nil { // Configure the optional proxy
+		log.Fatalln(err) // A bad proxy URL would make every request fail
+	}
+	if *dryRun { // Dry-run mode
+		dryRunPlan = &downloadPlan{byURL: map[string]*plannedAsset{}} // Downloads are planned instead of performed
+	} else { // Real runs write archived files
+		storage, err := openStorage(*storageSpec) // Open the storage backend for archived files
+		if err != nil {                           // Handle bad specs or missing credentials
+			log.Fatalln(err) // Nothing can be stored without a backend
+		}
+		archiveStorage = storage // Route every archive write through the backend
+		defer func() {           // Flush the backend when main returns
+			if err := archiveStorage.Close(); err != nil { // Finish bundles and release connections
+				log.Println(err) // Log the close error
+			}
+		}() // End of deferred storage close
+	}
+
 	outputDirectory := "PDFs/"             // Directory where downloaded PDF files will be saved
 	if !directoryExists(outputDirectory) { // Check if the directory already exists
 		createDirectory(outputDirectory, 0o755) // Create the directory with full read, write, and execute permissions (rwxr-xr-x)
@@ -82,29 +251,94 @@ func main() { // Main function, the entry point of the program
 		// Validate the URL
 		if isUrlValid(url) { // Checks if the current URL is syntactically valid
 			// Fetch HTML content from the URL
+			scrapeStarted := time.Now()                  // Start timing the page for the run report
 			htmlContent := scrapePageHTMLWithChrome(url) // Scrapes the fully rendered HTML using a headless Chrome instance
+			scrapeDuration := time.Since(scrapeStarted)  // Time spent rendering the page
+
+			anchorTexts := extractAnchorTexts(htmlContent) // Maps each link to the text shown on the page
 
 			// Extract PDF URLs from the HTML content
 			pdfUrls := extractPDFUrls(htmlContent) // Finds all links ending in ".pdf" in the scraped HTML
 			// Download each PDF URL into the designated PDF directory
 			for _, pdfUrl := range pdfUrls { // Iterates over all found PDF links
-				downloadPDF(pdfUrl, outputDirectory) // Correctly downloads the PDF into the 'PDFs/' directory
+				if *migrateFilenames { // Rename legacy files instead of downloading
+					migrateArchivedFilename(pdfUrl, outputDirectory) // Move the file to its collision-safe name
+					continue                                         // Skip the download
+				}
+				downloadPDF(pdfUrl, outputDirectory, url, anchorTexts[pdfUrl]) // Correctly downloads the PDF into the 'PDFs/' directory
 			}
 
 			// Extract ZIP URLs from the HTML content
 			zipUrls := extractZIPUrls(htmlContent) // Correctly finds all links ending in ".zip" using the new function
 			// Download each ZIP URL into the designated ZIP directory
 			for _, zipUrl := range zipUrls { // Iterates over all found ZIP links
-				downloadZIP(zipUrl, outputDirZIP) // Correctly downloads the ZIP into the 'ZIPs/' directory
+				if *migrateFilenames { // Rename legacy files instead of downloading
+					migrateArchivedFilename(zipUrl, outputDirZIP) // Move the file to its collision-safe name
+					continue                                      // Skip the download
+				}
+				downloadZIP(zipUrl, outputDirZIP, url, anchorTexts[zipUrl]) // Correctly downloads the ZIP into the 'ZIPs/' directory
+			}
+			// Extract RAR, 7z and tar archive URLs from the HTML content
+			archiveUrls := extractArchiveUrls(htmlContent) // Finds all links ending in ".rar", ".7z", ".tar", ".tar.gz" or ".tgz"
+			// Download each archive URL into the ZIP directory next to the ZIP archives
+			for _, archiveUrl := range archiveUrls { // Iterates over all found archive links
+				if *migrateFilenames { // Rename legacy files instead of downloading
+					migrateArchivedFilename(archiveUrl, outputDirZIP) // Move the file to its collision-safe name
+					continue                                          // Skip the download
+				}
+				downloadArchive(archiveUrl, outputDirZIP, url, anchorTexts[archiveUrl]) // Downloads the archive into the 'ZIPs/' directory
 			}
 			// Extract TXT URLs from the HTML content
 			txtUrls := extractTXTUrls(htmlContent) // Finds all links ending in ".txt" in the scraped HTML
 			// Download each TXT URL into the designated TXT directory
 			for _, txtUrl := range txtUrls { // Iterates over all found TXT links
-				downloadTXT(txtUrl, outputDirTXT) // Correctly downloads the TXT into the 'TXTs/' directory
+				if *migrateFilenames { // Rename legacy files instead of downloading
+					migrateArchivedFilename(txtUrl, outputDirTXT) // Move the file to its collision-safe name
+					continue                                      // Skip the download
+				}
+				downloadTXT(txtUrl, outputDirTXT, url, anchorTexts[txtUrl]) // Correctly downloads the TXT into the 'TXTs/' directory
+			}
+			latestCrawl.recordPage(url, htmlContent, slices.Concat(pdfUrls, zipUrls, archiveUrls, txtUrls))                // Remember what this page links to
+			currentRun.recordPage(url, scrapeDuration, htmlContent, slices.Concat(pdfUrls, zipUrls, archiveUrls, txtUrls)) // Add the page to the run report
+			if dryRunPlan != nil {                                                                                         // Dry-run mode
+				dryRunPlan.recordPage(url, htmlContent, slices.Concat(pdfUrls, zipUrls, archiveUrls, txtUrls)) // Add the page to the plan
 			}
 		} // End of URL validation block
 	} // End of the main URL iteration loop
+	if dryRunPlan != nil { // Dry-run mode writes nothing
+		if err := dryRunPlan.print(*planJSON); err != nil { // Output the plan
+			log.Println(err) // Log the write error
+		}
+		return // Leave every file untouched
+	}
+	if err := archiveFilenames.save(); err != nil { // Persist the filename → URL map
+		log.Println(err) // Log the write error
+	}
+	if err := downloadManifest.save(); err != nil { // Persist the provenance manifest
+		log.Println(err) // Log the write error
+	}
+	if err := latestCrawl.save(crawlPath); err != nil { // Persist the links found by this crawl
+		log.Println(err) // Log the write error
+	}
+	if localArchive { // The catalogs and the product layout read archived files from the working tree
+		if err := rebuildFirmwareCatalog(downloadManifest); err != nil { // Catalog the firmware images extracted so far
+			log.Println(err) // Log the failure
+		}
+		if err := rebuildDumpCatalog(); err != nil { // Catalog the CLI dumps archived so far
+			log.Println(err) // Log the failure
+		}
+		if *layout == "products" { // Product layout requested
+			if err := rebuildProductLayout(downloadManifest); err != nil { // Regenerate products/ from the updated manifest
+				log.Println(err) // Log the failure
+			}
+		}
+	} else { // Archived files went to another backend
+		log.Printf("Skipping firmware.json, dumps.json and products/: archived files are in %s, not the working tree", *storageSpec) // Log the skip
+	}
+	runBudget.logReport()                              // Report bytes transferred and downloads stopped by a limit
+	if err := currentRun.save(runBudget); err != nil { // Write run-report.json and run-report.md
+		log.Println(err) // Log the write error
+	}
 } // End of the main function
 
 // Uses headless Chrome via chromedp to get the fully rendered HTML from a webpage,
@@ -112,17 +346,8 @@ func main() { // Main function, the entry point of the program
 func scrapePageHTMLWithChrome(targetURL string) string { // Function to scrape dynamic content using Chrome
 	log.Println("Scraping:", targetURL) // Log which page is being scraped
 
-	// Configure Chrome options for the browser session
-	chromeOptions := append(chromedp.DefaultExecAllocatorOptions[:], // Starts with default Chrome execution options
-		chromedp.Flag("headless", false),              // Set to true for actual headless mode
-		chromedp.Flag("disable-gpu", true),            // Disable GPU acceleration (good for headless/servers)
-		chromedp.WindowSize(1, 1),                     // Set browser window size
-		chromedp.Flag("no-sandbox", true),             // Disable sandbox (useful for servers/containers)
-		chromedp.Flag("disable-setuid-sandbox", true), // Fix for Linux permission issues
-	) // End of Chrome options slice
-
 	// Create a new Chrome execution allocator with the configured options
-	execAllocatorContext, cancelAllocator := chromedp.NewExecAllocator(context.Background(), chromeOptions...) // Creates the context and cleanup function for the Chrome process
+	execAllocatorContext, cancelAllocator := chromedp.NewExecAllocator(context.Background(), chromeAllocatorOptions()...) // Creates the context and cleanup function for the Chrome process
 
 	// Set a timeout context to automatically stop the Chrome session after 5 minutes
 	timeoutContext, cancelTimeout := context.WithTimeout(execAllocatorContext, 5*time.Minute) // Creates a context with a 5-minute timeout
@@ -150,9 +375,29 @@ func scrapePageHTMLWithChrome(targetURL string) string { // Function to scrape d
 		return ""             // Return an empty string to indicate failure
 	} // End of error check
 
+	fileURLs := slices.Concat(extractPDFUrls(renderedHTML), extractZIPUrls(renderedHTML), extractArchiveUrls(renderedHTML), extractTXTUrls(renderedHTML)) // Downloads may live on other hosts (CDNs)
+	captureError := chromedp.Run(browserContext, chromedp.ActionFunc(func(ctx context.Context) error {                                                    // Share the session with the Go downloaders
+		return sharedSession.captureFromBrowser(ctx, targetURL, fileURLs) // Copy cookies and User-Agent into the shared jar
+	})) // End of session capture
+	if captureError != nil { // The page was read; only the shared session is missing
+		log.Printf("Could not copy the Chrome session for %s: %v", targetURL, captureError) // Log and keep the page
+	}
+
 	return renderedHTML // Return the fully rendered HTML source
 } // End of scrapePageHTMLWithChrome function
 
+// Returns the Chrome options shared by page scraping and browser downloads
+func chromeAllocatorOptions() []chromedp.ExecAllocatorOption { // Function to build Chrome execution options
+	chromeOptions := append(chromedp.DefaultExecAllocatorOptions[:], // Starts with default Chrome execution options
+		chromedp.Flag("headless", false),              // Set to true for actual headless mode
+		chromedp.Flag("disable-gpu", true),            // Disable GPU acceleration (good for headless/servers)
+		chromedp.WindowSize(1, 1),                     // Set browser window size
+		chromedp.Flag("no-sandbox", true),             // Disable sandbox (useful for servers/containers)
+		chromedp.Flag("disable-setuid-sandbox", true), // Fix for Linux permission issues
+	) // End of Chrome options slice
+	return append(chromeOptions, sharedSession.chromeOptions()...) // Route Chrome through the configured proxy, if any
+} // End of chromeAllocatorOptions function
+
 // Removes duplicate strings from a slice
 func removeDuplicatesFromSlice(slice []string) []string { // Function to filter a string slice for uniqueness
 	check := make(map[string]bool) // Create a map to track which strings have already been seen
@@ -191,68 +436,6 @@ func isUrlValid(uri string) bool { // Function to perform basic URL format valid
 	return err == nil                  // Return true if valid (parsing was successful, err is nil)
 } // End of isUrlValid function
 
-// Downloads a ZIP file from the given URL and saves it in the specified directory
-func downloadZIP(finalURL, outputDir string) bool { // Function to download and save a ZIP file
-	filename := strings.ToLower(urlToFilename(finalURL)) // Convert the URL into a safe lowercase filename
-	filePath := filepath.Join(outputDir, filename)       // Combine output directory and filename into a full path
-
-	if fileExists(filePath) { // Check if the file already exists
-		log.Printf("File already exists, skipping: %s", filePath) // Log that it’s being skipped
-		return false                                              // Return false since no download is needed
-	}
-
-	client := &http.Client{Timeout: 15 * time.Minute} // Create an HTTP client with a 15-minute timeout
-
-	resp, err := client.Get(finalURL) // Perform an HTTP GET request to download the file
-	if err != nil {                   // Handle network or connection errors
-		log.Printf("Failed to download %s %v", finalURL, err) // Log the error
-		return false                                          // Return false to indicate failure
-	}
-	defer resp.Body.Close() // Ensure the response body is closed to prevent resource leaks
-
-	if resp.StatusCode != http.StatusOK { // Verify that the response status is 200 OK
-		log.Printf("Download failed for %s %s", finalURL, resp.Status) // Log non-OK status
-		return false                                                   // Return false for failed downloads
-	}
-
-	contentType := resp.Header.Get("Content-Type") // Retrieve the Content-Type header from the response
-
-	// Verify that the file type is a ZIP or binary stream (expected for ZIP files)
-	if !strings.Contains(contentType, "binary/octet-stream") && // Check for generic binary/octet-stream
-		!strings.Contains(contentType, "application/zip") && // Check for standard application/zip
-		!strings.Contains(contentType, "application/x-zip-compressed") { // Check for common non-standard ZIP type
-		log.Printf("Invalid content type for %s %s (expected binary/octet-stream or application/zip or application/x-zip-compressed)", finalURL, contentType) // Log the invalid content type
-		return false                                                                                                                                          // Return false if the content type doesn’t match expected ZIP types
-	}
-
-	var buf bytes.Buffer                     // Initialize a buffer to hold the downloaded data temporarily
-	written, err := io.Copy(&buf, resp.Body) // Copy the response body into the buffer, capturing bytes written
-	if err != nil {                          // Handle read errors
-		log.Printf("Failed to read ZIP data from %s %v", finalURL, err) // Log the read failure
-		return false                                                    // Return false if unable to read data
-	}
-	if written == 0 { // Check if zero bytes were downloaded
-		log.Printf("Downloaded 0 bytes for %s; not creating file", finalURL) // Log that the file is empty
-		return false                                                         // Return false since there’s nothing to save
-	}
-
-	out, err := os.Create(filePath) // Create a new file in the output directory
-	if err != nil {                 // Handle file creation errors
-		log.Printf("Failed to create file for %s %v", finalURL, err) // Log the error
-		return false                                                 // Return false if file creation fails
-	}
-	defer out.Close() // Ensure the file is properly closed after writing
-
-	if _, err := buf.WriteTo(out); err != nil { // Write the buffered data to the output file
-		log.Printf("Failed to write ZIP to file for %s %v", finalURL, err) // Log the write failure
-		return false                                                       // Return false if writing fails
-	}
-
-	// Log success including bytes written, source URL, and destination path
-	log.Printf("Successfully downloaded %d bytes: %s → %s", written, finalURL, filePath)
-	return true // Return true to indicate success
-} // End of downloadZIP function
-
 // Checks if a file exists at the specified path
 func fileExists(filename string) bool { // Function to check if a file exists (and is not a directory)
 	info, err := os.Stat(filename) // Try to get file information
@@ -262,48 +445,51 @@ func fileExists(filename string) bool { // Function to check if a file exists (a
 	return !info.IsDir() // Return true only if the path exists and is not a directory
 } // End of fileExists function
 
-// Converts a raw URL into a sanitized filename safe for filesystem
+// Converts a raw URL into a sanitized filename safe for filesystem.
+// Only the real extension is split off, so names like "gep_pdb_vtx_pdf_guide.pdf" survive intact.
 func urlToFilename(rawURL string) string { // Function to create a clean filename from a URL
-	lower := strings.ToLower(rawURL) // Convert the input URL to lowercase for consistency
-	lower = getFilename(lower)       // Extract just the filename part from the URL
-
-	// Get the file extension from the extracted filename
-	ext := getFileExtension(lower) // Get the original file extension (e.g., ".pdf" or ".zip")
-
-	reNonAlnum := regexp.MustCompile(`[^a-z0-9]`)   // Create a regex to match any non-alphanumeric characters
-	safe := reNonAlnum.ReplaceAllString(lower, "_") // Replace all non-alphanumeric characters with underscores
-
-	safe = regexp.MustCompile(`_+`).ReplaceAllString(safe, "_") // Replace multiple consecutive underscores with a single underscore
-	safe = strings.Trim(safe, "_")                              // Remove leading and trailing underscores from the filename
-
-	var invalidSubstrings = []string{ // Define a list of unwanted substrings to clean from the filename
-		"_pdf", // Common redundant suffix
-		"_zip", // Common redundant suffix
-		"_txt", // Common redundant suffix
-	} // End of invalid substrings slice
+	urlPath := rawURL                                    // Fall back to the raw string if it cannot be parsed
+	if parsedURL, err := url.Parse(rawURL); err == nil { // Parse the URL to drop query strings and fragments
+		urlPath = parsedURL.EscapedPath() // Keep the escaped path so names stay stable with earlier runs
+	}
+	return sanitizeFilename(getFilename(urlPath)) // Sanitize just the filename part of the URL
+} // End of urlToFilename function
 
-	for _, invalidPre := range invalidSubstrings { // Iterate over the unwanted substrings
-		safe = removeSubstring(safe, invalidPre) // Remove each unwanted substring from the filename
-	} // End of substring removal loop
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]`)       // Anything not allowed in a sanitized name
+var nonAlphanumericOrDot = regexp.MustCompile(`[^a-z0-9.]`) // Anything not allowed in a sanitized extension
 
-	if getFileExtension(safe) == "" { // Check if the sanitized filename has no extension
-		safe = safe + ext // Append the original file extension (e.g., .pdf) to ensure completeness
+// Turns any filename into a lowercase, underscore-separated name with a clean extension
+// (e.g. "GEPRC_Nano_2G4RX-3.2.0.bin" → "geprc_nano_2g4rx_3_2_0.bin")
+func sanitizeFilename(name string) string { // Function to normalize a filename
+	lower := strings.ToLower(name)           // Work on the lowercase name
+	ext := getFileExtension(lower)           // Get the original file extension (e.g. ".pdf" or ".zip")
+	if strings.HasSuffix(lower, ".tar.gz") { // Keep both parts of compressed tar extensions
+		ext = ".tar.gz" // Treat ".tar.gz" as one extension
+	}
+	safe := sanitizeNameStem(strings.TrimSuffix(lower, ext)) // Sanitize everything before the extension
+	if ext != "" {                                           // Keep the extension, stripped of anything unusual
+		ext = "." + nonAlphanumericOrDot.ReplaceAllString(strings.TrimPrefix(ext, "."), "") // Sanitize the extension
 	}
+	return safe + ext // Return the sanitized, safe filename
+} // End of sanitizeFilename function
 
-	return safe // Return the sanitized, safe filename
-} // End of urlToFilename function
+// Turns a name without extension (or a directory name) into lowercase words joined by single underscores
+// (e.g. "GEPRC ELRS Nano 2.4G Firmware 3.2.0" → "geprc_elrs_nano_2_4g_firmware_3_2_0")
+func sanitizeNameStem(stem string) string { // Function to normalize a name stem
+	safe := nonAlphanumeric.ReplaceAllString(strings.ToLower(stem), "_") // Replace all non-alphanumeric characters with underscores
+	safe = regexp.MustCompile(`_+`).ReplaceAllString(safe, "_")          // Replace multiple consecutive underscores with a single underscore
+	safe = strings.Trim(safe, "_")                                       // Remove leading and trailing underscores from the filename
+	if safe == "" {                                                      // Names made only of punctuation
+		safe = "file" // Use a neutral placeholder
+	}
+	return safe // Return the sanitized stem
+} // End of sanitizeNameStem function
 
 // Gets the file extension from a given file path
 func getFileExtension(path string) string { // Function to extract the file extension
 	return filepath.Ext(path) // Use filepath.Ext to extract and return the file extension
 } // End of getFileExtension function
 
-// Removes all instances of a specific substring from input string
-func removeSubstring(input string, toRemove string) string { // Function to remove all occurrences of a substring
-	result := strings.ReplaceAll(input, toRemove, "") // Replace every occurrence of 'toRemove' with an empty string
-	return result                                     // Return the cleaned string after removal
-} // End of removeSubstring function
-
 // Extracts filename from full path (e.g. "/dir/file.pdf" → "file.pdf")
 func getFilename(path string) string { // Function to get only the base filename
 	return filepath.Base(path) // Use Base function to get file name only
@@ -375,126 +561,239 @@ func extractZIPUrls(htmlContent string) []string { // Function to find links end
 	return zipLinks         // Return all found ZIP links
 } // End of extractZIPUrls function
 
-// Downloads a PDF from the given URL and saves it in the specified directory
-func downloadPDF(pdfURL, outputDirectory string) bool { // Function to download and save a PDF file
-	safeFilename := strings.ToLower(urlToFilename(pdfURL))       // Generate a sanitized, lowercase filename
-	fullFilePath := filepath.Join(outputDirectory, safeFilename) // Build the complete file path for saving
+// Extracts all links to RAR, 7z and tar archives (".rar", ".7z", ".tar", ".tar.gz", ".tgz") from the given HTML string
+func extractArchiveUrls(htmlContent string) []string { // Function to find links to non-ZIP archives
+	var archiveLinks []string // Slice to store all found archive links
 
-	if fileExists(fullFilePath) { // Skip download if the file already exists
-		log.Printf("File already exists, skipping: %s", fullFilePath) // Log the skip message
-		return false                                                  // Return false since no download occurred
+	parsedHTML, parseError := html.Parse(strings.NewReader(htmlContent)) // Parse the input HTML content
+	if parseError != nil {                                               // Check if HTML parsing failed
+		log.Println(parseError) // Log the parsing error
+		return nil              // Return nil since parsing failed
 	}
 
-	httpClient := &http.Client{Timeout: 15 * time.Minute} // Create an HTTP client with a 15-minute timeout
+	var exploreHTML func(*html.Node) // Define a recursive function to explore HTML nodes
 
-	httpResponse, requestError := httpClient.Get(pdfURL) // Send an HTTP GET request
-	if requestError != nil {                             // Check for request errors
-		log.Printf("Failed to download %s %v", pdfURL, requestError) // Log the error
-		return false                                                 // Return false on failure
-	}
-	defer httpResponse.Body.Close() // Ensure the response body is closed
+	exploreHTML = func(currentNode *html.Node) { // The implementation of the recursive traversal function
+		if currentNode.Type == html.ElementNode && currentNode.Data == "a" { // Check if the node is an <a> tag
+			for _, attribute := range currentNode.Attr { // Iterate over the <a> tag's attributes
+				if attribute.Key == "href" { // Look for the href attribute
+					link := strings.TrimSpace(attribute.Val)                      // Get the href value and trim spaces
+					extension := archiveExtensionOf(removeQueryAndFragment(link)) // Match the extension of the link path
+					if extension != "" && extension != ".zip" {                   // ZIP links are collected by extractZIPUrls
+						archiveLinks = append(archiveLinks, link) // Add the link to the archiveLinks slice
+					}
+				}
+			}
+		}
 
-	if httpResponse.StatusCode != http.StatusOK { // Verify that the HTTP status is 200 OK
-		log.Printf("Download failed for %s %s", pdfURL, httpResponse.Status) // Log the non-OK status
-		return false                                                         // Return false on non-200 status
+		for childNode := currentNode.FirstChild; childNode != nil; childNode = childNode.NextSibling { // Recursively traverse child nodes
+			exploreHTML(childNode)
+		}
 	}
 
-	contentType := httpResponse.Header.Get("Content-Type") // Get the content type of the response
-
-	// Validate that the response is a PDF or binary stream
-	if !strings.Contains(contentType, "binary/octet-stream") && // Check for generic binary/octet-stream
-		!strings.Contains(contentType, "application/pdf") { // Check for standard application/pdf
-		log.Printf("Invalid content type for %s %s (expected binary/octet-stream or application/pdf)", pdfURL, contentType) // Log the invalid content type
-		return false                                                                                                        // Return false if content type is incorrect
-	}
+	exploreHTML(parsedHTML) // Begin traversal from the root node
+	return archiveLinks     // Return all found archive links
+} // End of extractArchiveUrls function
 
-	var responseBuffer bytes.Buffer                                        // Buffer to store the downloaded data
-	bytesWritten, copyError := io.Copy(&responseBuffer, httpResponse.Body) // Copy data from response body into buffer
-	if copyError != nil {                                                  // Check for read errors
-		log.Printf("Failed to read PDF data from %s %v", pdfURL, copyError) // Log the read failure
-		return false                                                        // Return false on read error
-	}
-	if bytesWritten == 0 { // Handle empty downloads
-		log.Printf("Downloaded 0 bytes for %s; not creating file", pdfURL) // Log empty download
-		return false                                                       // Return false if no data was downloaded
-	}
+// Drops the query string and fragment from a link ("a.rar?dl=1#x" → "a.rar")
+func removeQueryAndFragment(link string) string { // Function to isolate a link's path
+	link, _, _ = strings.Cut(link, "#") // Drop the fragment
+	link, _, _ = strings.Cut(link, "?") // Drop the query string
+	return link                         // Return the remaining link
+} // End of removeQueryAndFragment function
 
-	outputFile, fileCreateError := os.Create(fullFilePath) // Create the output file for saving
-	if fileCreateError != nil {                            // Handle file creation errors
-		log.Printf("Failed to create file for %s %v", pdfURL, fileCreateError) // Log the creation failure
-		return false                                                           // Return false on file creation error
-	}
-	defer outputFile.Close() // Ensure the file is closed after writing
+// Downloads a PDF from the given URL and saves it in the specified directory
+func downloadPDF(pdfURL, outputDirectory, sourcePage, anchorText string) bool { // Function to download and save a PDF file
+	allowedContentTypes := []string{ // Content types accepted for PDF downloads
+		"binary/octet-stream", // Generic binary stream
+		"application/pdf",     // Standard PDF type
+	} // End of allowed content types
+	return downloadFile(pdfURL, outputDirectory, sourcePage, anchorText, "PDF", allowedContentTypes) // Delegate to the shared downloader
+} // End of downloadPDF function
 
-	if _, writeError := responseBuffer.WriteTo(outputFile); writeError != nil { // Write buffer contents to file
-		log.Printf("Failed to write PDF to file for %s %v", pdfURL, writeError) // Log the write failure
-		return false                                                            // Return false on write error
-	}
+// Downloads a ZIP file from the given URL and saves it in the specified directory
+func downloadZIP(zipURL, outputDirectory, sourcePage, anchorText string) bool { // Function to download and save a ZIP file
+	allowedContentTypes := []string{ // Content types accepted for ZIP downloads
+		"binary/octet-stream",          // Generic binary stream
+		"application/zip",              // Standard ZIP type
+		"application/x-zip-compressed", // Common non-standard ZIP type
+	} // End of allowed content types
+	return downloadFile(zipURL, outputDirectory, sourcePage, anchorText, "ZIP", allowedContentTypes) // Delegate to the shared downloader
+} // End of downloadZIP function
 
-	log.Printf("Successfully downloaded %d bytes: %s → %s", bytesWritten, pdfURL, fullFilePath) // Log success message
-	return true                                                                                 // Indicate successful download
-} // End of downloadPDF function
+// Downloads a RAR, 7z or tar archive; the file type (and with it the size limit and validator) follows the extension
+func downloadArchive(archiveURL, outputDirectory, sourcePage, anchorText string) bool { // Function to download and save a non-ZIP archive
+	allowedContentTypes := []string{ // Content types accepted for archive downloads
+		"binary/octet-stream",          // Generic binary stream
+		"application/octet-stream",     // Generic binary stream
+		"application/vnd.rar",          // Registered RAR type
+		"application/x-rar-compressed", // Common non-standard RAR type
+		"application/x-7z-compressed",  // 7z type
+		"application/x-tar",            // Plain tar
+		"application/gzip",             // Gzip-compressed tar
+		"application/x-gzip",           // Common non-standard gzip type
+		"application/x-gtar",           // GNU tar
+		"application/x-compressed-tar", // Gzip-compressed tar as named by some servers
+	} // End of allowed content types
+	fileType := archiveFileTypeOf(removeQueryAndFragment(archiveURL))                                       // RAR, 7Z or TAR
+	return downloadFile(archiveURL, outputDirectory, sourcePage, anchorText, fileType, allowedContentTypes) // Delegate to the shared downloader
+} // End of downloadArchive function
 
 // Downloads a TXT file from the given URL and saves it in the specified directory
-func downloadTXT(txtURL, outputDirectory string) bool { // Function to download and save a TXT file
-	safeFilename := strings.ToLower(urlToFilename(txtURL))       // Generate a sanitized, lowercase filename
+func downloadTXT(txtURL, outputDirectory, sourcePage, anchorText string) bool { // Function to download and save a TXT file
+	allowedContentTypes := []string{ // Content types accepted for TXT downloads
+		"text/plain",          // Standard text type
+		"charset=utf-8",       // Sometimes text/plain; charset=utf-8
+		"binary/octet-stream", // Fallback generic binary type
+	} // End of allowed content types
+	return downloadFile(txtURL, outputDirectory, sourcePage, anchorText, "TXT", allowedContentTypes) // Delegate to the shared downloader
+} // End of downloadTXT function
+
+// Downloads a file from the given URL into the output directory, keeping any previous version in history
+func downloadFile(fileURL, outputDirectory, sourcePage, anchorText, fileType string, allowedContentTypes []string) bool { // Shared download logic for all asset types
+	safeFilename := archiveFilenames.filenameFor(fileURL)        // Generate a sanitized, collision-free filename
 	fullFilePath := filepath.Join(outputDirectory, safeFilename) // Build the complete file path for saving
+	if dryRunPlan != nil {                                       // Dry-run mode
+		dryRunPlan.recordAsset(fileURL, filepath.ToSlash(fullFilePath), sourcePage, fileType, allowedContentTypes) // Plan the download instead
+		return false                                                                                               // Nothing was downloaded
+	}
 
-	if fileExists(fullFilePath) { // Skip download if the file already exists
-		log.Printf("File already exists, skipping: %s", fullFilePath)
-		return false
+	_, statError := archiveStorage.Stat(filepath.ToSlash(fullFilePath))  // Look for the stored copy
+	storedCopy := statError == nil                                       // Whether the backend already has the file
+	previous := downloadManifest.entries[filepath.ToSlash(fullFilePath)] // Metadata of the archived copy, if any
+	if !storedCopy {                                                     // Nothing stored (new file, or a write-only bundle)
+		previous = nil // Download unconditionally
 	}
 
-	httpClient := &http.Client{Timeout: 10 * time.Minute} // Create an HTTP client with a 10-minute timeout
+	fetched, fetchError := fetchWithHTTP(fileURL, sourcePage, fileType, allowedContentTypes, previous) // Download with the shared Go HTTP client
+	if errors.Is(fetchError, errNotModified) {                                                         // The archived copy is current
+		keepUnchangedFile(fileURL, fullFilePath, safeFilename, sourcePage, anchorText, fileType, previous) // Record the link without downloading
+		return false                                                                                       // Nothing new was written
+	}
+	if browserFallbackEnabled && errors.Is(fetchError, errBlockedByServer) { // Retry with Chrome only when the server turned the client away
+		log.Printf("HTTP download failed for %s (%v); retrying with Chrome", fileURL, fetchError) // Log the fallback
+		fetched, fetchError = downloadWithBrowser(fileURL, sourcePage, fileType)                  // Let Chrome fetch the file itself
+	}
+	if fetchError != nil { // Both download paths failed
+		log.Printf("Failed to download %s %v", fileURL, fetchError)                                             // Log the error
+		currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, assetFailed, fetchError.Error(), 0) // Report the failure
+		return false                                                                                            // Return false on failure
+	}
 
-	httpResponse, requestError := httpClient.Get(txtURL) // Send an HTTP GET request
-	if requestError != nil {
-		log.Printf("Failed to download %s %v", txtURL, requestError)
-		return false
+	if validationError := validateDownload(fileType, fetched.data); validationError != nil { // Check the file structure before keeping it
+		quarantineDownload(safeFilename, fetched.data, fileURL, sourcePage, validationError)                                                               // Keep the bad file out of the archive
+		currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, assetFailed, "validation failed: "+validationError.Error(), len(fetched.data)) // Report the rejection
+		return false                                                                                                                                       // Return false on validation failure
 	}
-	defer httpResponse.Body.Close()
 
-	if httpResponse.StatusCode != http.StatusOK { // Verify that the HTTP status is 200 OK
-		log.Printf("Download failed for %s %s", txtURL, httpResponse.Status)
-		return false
+	existed := storedCopy || downloadManifest.entries[filepath.ToSlash(fullFilePath)] != nil // Whether this is an update rather than a new file
+	changed, storeError := storeFileVersion(fullFilePath, fetched.data, fileURL, sourcePage) // Write the file, archiving any previous version
+	if storeError != nil {                                                                   // Handle write errors
+		log.Printf("Failed to write %s to file for %s %v", fileType, fileURL, storeError)                                                        // Log the write failure
+		currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, assetFailed, "write failed: "+storeError.Error(), len(fetched.data)) // Report the failure
+		return false                                                                                                                             // Return false on write error
+	}
+	archiveFilenames.claim(safeFilename, fileURL)                                        // Record which URL owns this filename
+	downloadManifest.recordFetch(fullFilePath, fileURL, sourcePage, anchorText, fetched) // Record the file's provenance
+	if archiveFileTypeOf(fullFilePath) != "" {                                           // Archive download
+		extractArchive(fullFilePath, fetched.data, false) // Unpack it next to the archive unless already extracted from these bytes
 	}
 
-	contentType := httpResponse.Header.Get("Content-Type") // Get the content type of the response
+	if !changed { // The upstream file is identical to the local copy
+		log.Printf("File unchanged, skipping: %s", fullFilePath)                                                 // Log the skip message
+		currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, assetSkipped, "", len(fetched.data)) // Report the skip
+		return false                                                                                             // Return false since nothing new was written
+	}
+	outcome := assetDownloaded // A new file
+	if existed {               // An archived file was replaced
+		outcome = assetUpdated // A new upstream version
+	}
+	currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, outcome, "", len(fetched.data)) // Report the download
 
-	// Validate that the response is a plain text or generic stream
-	if !strings.Contains(contentType, "text/plain") && // Standard text type
-		!strings.Contains(contentType, "charset=utf-8") && // Sometimes text/plain; charset=utf-8
-		!strings.Contains(contentType, "binary/octet-stream") { // Fallback generic binary type
-		log.Printf("Invalid content type for %s %s (expected text/plain, text/plain; charset=utf-8, or binary/octet-stream)", txtURL, contentType)
-		return false
+	log.Printf("Successfully downloaded %d bytes: %s → %s", len(fetched.data), fileURL, fullFilePath) // Log success message
+	return true                                                                                       // Indicate successful download
+} // End of downloadFile function
+
+// Handles a file the server reported as unchanged: records the link and extracts archives not yet unpacked from these bytes
+func keepUnchangedFile(fileURL, fullFilePath, safeFilename, sourcePage, anchorText, fileType string, previous *manifestEntry) { // Function to keep an unchanged file
+	archiveFilenames.claim(safeFilename, fileURL)                                                                        // Record which URL owns this filename
+	downloadManifest.recordLink(fullFilePath, sourcePage, anchorText)                                                    // Record the page linking to it
+	if archiveFileTypeOf(fullFilePath) != "" && !downloadManifest.archiveListingMatches(fullFilePath, previous.SHA256) { // Archive never extracted from these bytes
+		if data, err := archiveStorage.Get(filepath.ToSlash(fullFilePath)); err == nil { // Read the archived copy
+			extractArchive(fullFilePath, data, false) // Unpack it next to the archive
+		}
 	}
+	log.Printf("File unchanged (304), skipping: %s", fullFilePath)                                            // Log the skip message
+	currentRun.recordAsset(fileURL, fullFilePath, fileType, sourcePage, assetSkipped, "", int(previous.Size)) // Report the skip
+} // End of keepUnchangedFile function
 
-	var responseBuffer bytes.Buffer
-	bytesWritten, copyError := io.Copy(&responseBuffer, httpResponse.Body) // Copy data to buffer
-	if copyError != nil {
-		log.Printf("Failed to read TXT data from %s %v", txtURL, copyError)
-		return false
+var errNotModified = errors.New("not modified") // Returned when the server confirms the archived copy is current
+
+// Downloads a file with the shared Go HTTP client, checking status, content type and size limits. When previous
+// describes the archived copy the request is conditional, and a 304 answer returns errNotModified.
+func fetchWithHTTP(fileURL, sourcePage, fileType string, allowedContentTypes []string, previous *manifestEntry) (*fetchResult, error) { // Function to fetch a file over HTTP
+	httpRequest, requestError := sharedSession.newRequest(fileURL, sourcePage) // Build a request carrying the browser's User-Agent and Referer
+	if requestError != nil {                                                   // Check for malformed URLs
+		return nil, requestError // Propagate the error
 	}
-	if bytesWritten == 0 {
-		log.Printf("Downloaded 0 bytes for %s; not creating file", txtURL)
-		return false
+	switch { // Make the request conditional on the archived copy
+	case previous == nil: // Nothing archived yet
+	case previous.ETag != "": // The server gave an ETag last time
+		httpRequest.Header.Set("If-None-Match", previous.ETag) // Ask for the body only if it changed
+	case !previous.FetchedAt.IsZero(): // No ETag; fall back to the fetch time
+		httpRequest.Header.Set("If-Modified-Since", previous.FetchedAt.UTC().Format(http.TimeFormat)) // Ask for the body only if it changed since
 	}
 
-	outputFile, fileCreateError := os.Create(fullFilePath)
-	if fileCreateError != nil {
-		log.Printf("Failed to create file for %s %v", txtURL, fileCreateError)
-		return false
+	httpResponse, requestError := sharedSession.client.Do(httpRequest) // Send the request with the shared cookie jar and transport
+	if requestError != nil {                                           // Check for request errors
+		return nil, requestError // Propagate the error
 	}
-	defer outputFile.Close()
+	defer httpResponse.Body.Close() // Ensure the response body is closed
 
-	if _, writeError := responseBuffer.WriteTo(outputFile); writeError != nil {
-		log.Printf("Failed to write TXT to file for %s %v", txtURL, writeError)
-		return false
+	if httpResponse.StatusCode == http.StatusNotModified && previous != nil { // The archived copy is current
+		return nil, errNotModified // Nothing to download
+	}
+	switch httpResponse.StatusCode { // Verify that the HTTP status is 200 OK
+	case http.StatusOK: // Success
+	case http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable: // Bot protection or rate limiting
+		return nil, fmt.Errorf("unexpected status %s: %w", httpResponse.Status, errBlockedByServer) // A browser may get through
+	default: // Missing file, server error, ...
+		return nil, fmt.Errorf("unexpected status %s", httpResponse.Status) // Report the non-OK status
 	}
 
-	log.Printf("Successfully downloaded %d bytes: %s → %s", bytesWritten, txtURL, fullFilePath)
-	return true
-} // End of downloadTXT function
+	contentType := httpResponse.Header.Get("Content-Type")     // Get the content type of the response
+	if !contentTypeAllowed(contentType, allowedContentTypes) { // Validate the content type against the accepted list
+		if strings.Contains(contentType, "text/html") { // A challenge page served instead of the file
+			return nil, fmt.Errorf("HTML page served instead of the %s: %w", fileType, errBlockedByServer) // A browser may pass the challenge
+		}
+		return nil, fmt.Errorf("invalid content type %s (expected %s)", contentType, strings.Join(allowedContentTypes, " or ")) // Report the invalid content type
+	}
+
+	responseData, readError := readLimitedBody(fileURL, fileType, httpResponse) // Read the body under the size limits and run budget
+	if readError != nil {                                                       // Check for read errors or exceeded limits
+		return nil, fmt.Errorf("read %s data: %w", fileType, readError) // Report the read failure
+	}
+	if len(responseData) == 0 { // Handle empty downloads
+		return nil, errors.New("downloaded 0 bytes") // Report the empty download
+	}
+	return &fetchResult{ // Return the data with its response metadata
+		data:        responseData,                      // Downloaded bytes
+		finalURL:    httpResponse.Request.URL.String(), // URL after redirects
+		status:      httpResponse.StatusCode,           // HTTP status code
+		contentType: contentType,                       // Content-Type header
+		etag:        httpResponse.Header.Get("ETag"),   // ETag header, if any
+	}, nil // No error
+} // End of fetchWithHTTP function
+
+// Reports whether a Content-Type header matches any of the accepted values
+func contentTypeAllowed(contentType string, allowedContentTypes []string) bool { // Function to check a content type against a list
+	for _, allowed := range allowedContentTypes { // Iterate over the accepted values
+		if strings.Contains(contentType, allowed) { // Check if the header contains the accepted value
+			return true // Return true on the first match
+		}
+	}
+	return false // Return false if nothing matched
+} // End of contentTypeAllowed function
 
 // Extracts all links to TXT files from the given HTML string
 func extractTXTUrls(htmlContent string) []string { // Function to find links ending in ".txt"
@@ -528,3 +827,49 @@ func extractTXTUrls(htmlContent string) []string { // Function to find links end
 	exploreHTML(parsedHTML) // Begin traversal from the root node
 	return txtLinks         // Return all found TXT links
 } // End of extractTXTUrls function
+
+// Extracts the visible text of every link on the page, keyed by href
+func extractAnchorTexts(htmlContent string) map[string]string { // Function to map links to their anchor text
+	anchorTexts := make(map[string]string) // Map to store href → text
+
+	parsedHTML, parseError := html.Parse(strings.NewReader(htmlContent)) // Parse the input HTML content
+	if parseError != nil {                                               // Check if HTML parsing failed
+		log.Println(parseError) // Log the parsing error
+		return anchorTexts      // Return the empty map since parsing failed
+	}
+
+	var collectText func(*html.Node, *strings.Builder)                  // Define a recursive function to gather text nodes
+	collectText = func(currentNode *html.Node, text *strings.Builder) { // The implementation of the text collector
+		if currentNode.Type == html.TextNode { // Text nodes carry the visible words
+			text.WriteString(currentNode.Data) // Append the text
+			text.WriteString(" ")              // Separate adjacent nodes
+		}
+		for childNode := currentNode.FirstChild; childNode != nil; childNode = childNode.NextSibling { // Recursively visit child nodes
+			collectText(childNode, text)
+		}
+	}
+
+	var exploreHTML func(*html.Node)             // Define a recursive function to explore HTML nodes
+	exploreHTML = func(currentNode *html.Node) { // The implementation of the recursive traversal function
+		if currentNode.Type == html.ElementNode && currentNode.Data == "a" { // Check if the node is an <a> tag
+			for _, attribute := range currentNode.Attr { // Iterate over the <a> tag's attributes
+				if attribute.Key == "href" { // Look for the href attribute
+					var text strings.Builder                                       // Buffer for the anchor text
+					collectText(currentNode, &text)                                // Gather the text inside the link
+					link := strings.TrimSpace(attribute.Val)                       // Get the href value and trim spaces
+					anchorText := strings.Join(strings.Fields(text.String()), " ") // Collapse whitespace
+					if anchorText != "" && anchorTexts[link] == "" {               // Keep the first non-empty text for each link
+						anchorTexts[link] = anchorText // Store the text
+					}
+				}
+			}
+		}
+
+		for childNode := currentNode.FirstChild; childNode != nil; childNode = childNode.NextSibling { // Recursively traverse child nodes
+			exploreHTML(childNode)
+		}
+	}
+
+	exploreHTML(parsedHTML) // Begin traversal from the root node
+	return anchorTexts      // Return the collected anchor texts
+} // End of extractAnchorTexts function
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json" // Reads and writes manifest.json
+	"fmt"           // Formats manifest errors
+	"log"           // Logs manifest problems
+	"os"            // Provides file system access
+	"path/filepath" // Normalizes archive paths
+	"reflect"       // Compares archive listings
+	"slices"        // Keeps source page and anchor lists sorted and unique
+	"time"          // Timestamps fetches
+)
+
+const manifestPath = "manifest.json" // Provenance manifest for every archived file
+
+// Result of fetching a file, with the response metadata recorded in the manifest
+type fetchResult struct {
+	data        []byte // Downloaded bytes
+	finalURL    string // URL after following redirects
+	status      int    // HTTP status code (0 for browser downloads)
+	contentType string // Content-Type header
+	etag        string // ETag header
+} // End of fetchResult struct
+
+// Provenance of one archived file
+type manifestEntry struct {
+	OriginURL   string          `json:"origin_url,omitempty"`   // URL the file was linked as (empty when unknown)
+	FinalURL    string          `json:"final_url,omitempty"`    // URL after redirects
+	SourcePages []string        `json:"source_pages,omitempty"` // Pages that link to the file
+	AnchorTexts []string        `json:"anchor_texts,omitempty"` // Link texts used on those pages
+	FetchedAt   time.Time       `json:"fetched_at,omitzero"`    // When the current contents were fetched (zero when unknown)
+	HTTPStatus  int             `json:"http_status,omitempty"`  // HTTP status of that fetch
+	ContentType string          `json:"content_type,omitempty"` // Content-Type of that fetch
+	ETag        string          `json:"etag,omitempty"`         // ETag of that fetch
+	Size        int64           `json:"size"`                   // File size in bytes
+	SHA256      string          `json:"sha256"`                 // SHA-256 of the file contents
+	WithdrawnAt *time.Time      `json:"withdrawn_at,omitempty"` // Crawl that first found the file no longer linked upstream
+	Archive     *archiveListing `json:"archive,omitempty"`      // Contents of archives such as ZIP files
+} // End of manifestEntry struct
+
+// Provenance records keyed by archive path (e.g. "PDFs/mark5_manual.pdf")
+type assetManifest struct {
+	path    string                    // Where the manifest is persisted
+	entries map[string]*manifestEntry // Archive path → provenance
+	dirty   bool                      // Whether anything changed since loading
+} // End of assetManifest struct
+
+var downloadManifest = loadManifest(manifestPath) // Manifest shared by all downloaders
+
+// Loads the manifest, starting empty when it does not exist yet
+func loadManifest(path string) *assetManifest { // Function to load manifest.json
+	manifest := &assetManifest{path: path, entries: map[string]*manifestEntry{}} // Start with an empty manifest
+	data, err := os.ReadFile(path)                                               // Read the persisted manifest
+	if err != nil {                                                              // A missing manifest simply means nothing was recorded yet
+		if !os.IsNotExist(err) { // Other errors deserve a log line
+			log.Println(err) // Log the read error
+		}
+		return manifest // Return the empty manifest
+	}
+	if err := json.Unmarshal(data, &manifest.entries); err != nil { // Decode the entries
+		log.Fatalf("parse %s: %v", path, err) // Refuse to run and overwrite a corrupt manifest
+	}
+	return manifest // Return the loaded manifest
+} // End of loadManifest function
+
+// Records where an archived file came from. Fetch metadata only changes when the contents do,
+// so re-downloading an unchanged file does not churn the manifest.
+func (manifest *assetManifest) recordFetch(filePath, originURL, sourcePage, anchorText string, fetched *fetchResult) { // Method to record a fetch
+	key := filepath.ToSlash(filePath) // Archive paths always use forward slashes
+	entry := manifest.entries[key]    // Existing entry, if any
+	if entry == nil {                 // First time this file is recorded
+		entry = &manifestEntry{OriginURL: originURL} // Create the entry
+		manifest.entries[key] = entry                // Store it
+		manifest.dirty = true                        // Remember to persist it
+	}
+
+	hash := sha256Hex(fetched.data) // Hash of the fetched contents
+	if entry.SHA256 != hash {       // New or changed contents
+		entry.OriginURL = originURL             // URL the contents came from
+		entry.FinalURL = fetched.finalURL       // URL after redirects
+		entry.FetchedAt = time.Now().UTC()      // Fetch time
+		entry.HTTPStatus = fetched.status       // Response status
+		entry.ContentType = fetched.contentType // Response Content-Type
+		entry.ETag = fetched.etag               // Response ETag
+		entry.Size = int64(len(fetched.data))   // Content size
+		entry.SHA256 = hash                     // Content hash
+		manifest.dirty = true                   // Remember to persist the change
+	}
+	manifest.recordLink(filePath, sourcePage, anchorText) // Remember the link that led here
+} // End of recordFetch method
+
+// Records that an archived file is still linked from a page, e.g. after the server answered 304 Not Modified
+func (manifest *assetManifest) recordLink(filePath, sourcePage, anchorText string) { // Method to record a link
+	entry := manifest.existingEntry(filePath) // Entry of the archived file
+	if entry.WithdrawnAt != nil {             // The file is linked again
+		entry.WithdrawnAt = nil // Clear the withdrawn marker
+		manifest.dirty = true   // Remember to persist the change
+	}
+	if addSorted(&entry.SourcePages, sourcePage) { // Remember every page linking to the file
+		manifest.dirty = true // Remember to persist the change
+	}
+	if addSorted(&entry.AnchorTexts, anchorText) { // Remember every link text used for the file
+		manifest.dirty = true // Remember to persist the change
+	}
+} // End of recordLink method
+
+// Marks an archived file as withdrawn upstream, keeping the earliest withdrawal time
+func (manifest *assetManifest) markWithdrawn(archivePath string, crawledAt time.Time) { // Method to flag a withdrawn file
+	entry := manifest.existingEntry(archivePath) // Entry of the archived file
+	if entry.WithdrawnAt != nil {                // Already marked by an earlier crawl
+		return // Keep the first withdrawal time
+	}
+	withdrawnAt := crawledAt.UTC()   // Copy the crawl time
+	entry.WithdrawnAt = &withdrawnAt // Mark the file
+	manifest.dirty = true            // Remember to persist the change
+} // End of markWithdrawn method
+
+// Records the contents of an archived archive file
+func (manifest *assetManifest) recordArchive(archivePath string, listing *archiveListing) { // Method to record an archive listing
+	entry := manifest.existingEntry(archivePath)   // Entry of the archive
+	if reflect.DeepEqual(entry.Archive, listing) { // Re-extracting identical contents
+		return // Nothing changed
+	}
+	entry.Archive = listing // Store the listing
+	manifest.dirty = true   // Remember to persist the change
+} // End of recordArchive method
+
+// Reports whether an archive's contents were already recorded
+func (manifest *assetManifest) hasArchiveListing(archivePath string) bool { // Method to check for a listing
+	entry := manifest.entries[filepath.ToSlash(archivePath)] // Entry of the archive, if any
+	return entry != nil && entry.Archive != nil              // Whether it has a listing
+} // End of hasArchiveListing method
+
+// Reports whether an archive's recorded listing was made from the contents with this SHA-256
+func (manifest *assetManifest) archiveListingMatches(archivePath, sum string) bool { // Method to check a listing is current
+	entry := manifest.entries[filepath.ToSlash(archivePath)]                   // Entry of the archive, if any
+	return entry != nil && entry.Archive != nil && entry.Archive.SHA256 == sum // Whether the listing is of these bytes
+} // End of archiveListingMatches method
+
+// Returns the entry of a file already in the archive, creating one for files archived before the manifest existed
+func (manifest *assetManifest) existingEntry(archivePath string) *manifestEntry { // Method to look up or create an entry
+	key := filepath.ToSlash(archivePath) // Archive paths always use forward slashes
+	entry := manifest.entries[key]       // Existing entry, if any
+	if entry == nil {                    // Files archived before the manifest existed
+		entry = &manifestEntry{OriginURL: archiveFilenames.byName[filepath.Base(key)]} // Recover the URL from the filename map if known
+		if data, err := os.ReadFile(filepath.FromSlash(key)); err == nil {             // Describe the local copy when there is one
+			entry.Size = int64(len(data))  // File size
+			entry.SHA256 = sha256Hex(data) // File hash
+		}
+		manifest.entries[key] = entry // Store the entry
+		manifest.dirty = true         // Remember to persist it
+	}
+	return entry // Return the entry
+} // End of existingEntry method
+
+// Forgets an archived file entirely
+func (manifest *assetManifest) remove(archivePath string) { // Method to drop an entry
+	key := filepath.ToSlash(archivePath)     // Archive paths always use forward slashes
+	if _, ok := manifest.entries[key]; !ok { // Nothing recorded
+		return // Nothing to remove
+	}
+	delete(manifest.entries, key) // Drop the entry
+	manifest.dirty = true         // Remember to persist the change
+} // End of remove method
+
+// Moves an entry to a new archive path after the file was renamed
+func (manifest *assetManifest) renamePath(oldPath, newPath string) { // Method to follow a file rename
+	oldKey, newKey := filepath.ToSlash(oldPath), filepath.ToSlash(newPath) // Normalize both keys
+	entry, ok := manifest.entries[oldKey]                                  // Look up the old entry
+	if !ok {                                                               // Nothing recorded for the old path
+		return // Nothing to move
+	}
+	delete(manifest.entries, oldKey) // Drop the old key
+	manifest.entries[newKey] = entry // Store under the new key
+	manifest.dirty = true            // Remember to persist the change
+} // End of renamePath method
+
+// Writes the manifest atomically as indented JSON with sorted keys so it diffs cleanly in git
+func (manifest *assetManifest) save() error { // Method to persist manifest.json
+	if !manifest.dirty { // Nothing new to write
+		return nil // Skip the write
+	}
+	data, err := json.MarshalIndent(manifest.entries, "", "  ") // Encode with sorted map keys
+	if err != nil {                                             // Handle encoding errors
+		return err // Propagate the error
+	}
+	if err := writeFileAtomically(manifest.path, append(data, '\n')); err != nil { // Replace the manifest in one step
+		return fmt.Errorf("write %s: %w", manifest.path, err) // Report which file failed
+	}
+	manifest.dirty = false // The manifest is now in sync
+	return nil             // Save succeeded
+} // End of save method
+
+// Inserts a value into a sorted slice if it is not empty and not already present; reports whether it was added
+func addSorted(values *[]string, value string) bool { // Function to maintain sorted unique lists
+	if value == "" { // Ignore empty values
+		return false // Nothing added
+	}
+	index, found := slices.BinarySearch(*values, value) // Find the insertion point
+	if found {                                          // Already present
+		return false // Nothing added
+	}
+	*values = slices.Insert(*values, index, value) // Insert in sorted position
+	return true                                    // Value added
+} // End of addSorted function
+
+// Writes data to a temporary file next to path and renames it into place, so readers never see a partial file
+func writeFileAtomically(path string, data []byte) error { // Function to replace a file atomically
+	temporaryFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*") // Create the temp file in the same directory
+	if err != nil {                                                                           // Handle creation errors
+		return err // Propagate the error
+	}
+	temporaryPath := temporaryFile.Name() // Remember the temp path for cleanup
+	defer os.Remove(temporaryPath)        // Clean up if anything below fails (no-op after rename)
+
+	if _, err := temporaryFile.Write(data); err != nil { // Write the contents
+		temporaryFile.Close() // Release the handle
+		return err            // Propagate the error
+	}
+	if err := temporaryFile.Sync(); err != nil { // Flush to disk before renaming
+		temporaryFile.Close() // Release the handle
+		return err            // Propagate the error
+	}
+	if err := temporaryFile.Close(); err != nil { // Close the temp file
+		return err // Propagate the error
+	}
+	if err := os.Chmod(temporaryPath, 0o644); err != nil { // CreateTemp uses 0600; match the other archive files
+		return err // Propagate the error
+	}
+	return os.Rename(temporaryPath, path) // Atomically replace the target
+} // End of writeFileAtomically function
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json" // Reads and writes crawl.json
+	"fmt"           // Prints orphan listings
+	"log"           // Logs orphan detection and pruning
+	"os"            // Provides file system access
+	"path/filepath" // Builds archive paths
+	"sort"          // Orders listings
+	"time"          // Timestamps crawls and withdrawals
+)
+
+const crawlPath = "crawl.json" // Links found by the most recent crawl
+
+var archiveDirectories = []string{"PDFs/", "ZIPs/", "TXTs/"} // Directories holding archived upstream files
+
+// Links found on each page during a crawl
+type crawlRecord struct {
+	CrawledAt   time.Time           `json:"crawled_at"`             // When the crawl ran
+	Pages       map[string][]string `json:"pages"`                  // Page URL → asset links found on it
+	FailedPages []string            `json:"failed_pages,omitempty"` // Pages that could not be scraped
+} // End of crawlRecord struct
+
+var latestCrawl = &crawlRecord{CrawledAt: time.Now().UTC(), Pages: map[string][]string{}} // Crawl being recorded by this run
+
+// Records the asset links found on a page; an empty page means the scrape failed
+func (crawl *crawlRecord) recordPage(pageURL, htmlContent string, links []string) { // Method to record a scraped page
+	if htmlContent == "" { // The scrape failed, so missing links mean nothing
+		addSorted(&crawl.FailedPages, pageURL) // Remember the failure
+		return                                 // Nothing else to record
+	}
+	sortedLinks := removeDuplicatesFromSlice(links) // Drop duplicate links
+	sort.Strings(sortedLinks)                       // Keep crawl.json diffable
+	crawl.Pages[pageURL] = sortedLinks              // Store the page's links
+} // End of recordPage method
+
+// Writes the crawl record atomically as indented JSON
+func (crawl *crawlRecord) save(path string) error { // Method to persist crawl.json
+	data, err := json.MarshalIndent(crawl, "", "  ") // Encode the record
+	if err != nil {                                  // Handle encoding errors
+		return err // Propagate the error
+	}
+	return writeFileAtomically(path, append(data, '\n')) // Replace the file in one step
+} // End of save method
+
+// Loads the crawl record written by the latest run
+func loadCrawl(path string) (*crawlRecord, error) { // Function to read crawl.json
+	data, err := os.ReadFile(path) // Read the file
+	if err != nil {                // Handle missing or unreadable files
+		return nil, err // Propagate the error
+	}
+	crawl := &crawlRecord{}                             // Decoded record
+	if err := json.Unmarshal(data, crawl); err != nil { // Decode the JSON
+		return nil, fmt.Errorf("parse %s: %w", path, err) // Report the corrupt file
+	}
+	return crawl, nil // Return the record
+} // End of loadCrawl function
+
+// Lists archived files that the latest crawl no longer links to and marks them withdrawn upstream in the
+// manifest (files are never deleted here). Files linked only from pages that failed to scrape are left alone.
+func detectOrphans(crawl *crawlRecord, manifest *assetManifest) []string { // Function to find and mark orphaned files
+	linkedNames := map[string]bool{}    // Filenames produced by links in the crawl
+	for _, links := range crawl.Pages { // Every link on every scraped page
+		for _, link := range links { // Map the link to its archive name
+			linkedNames[archiveFilenames.filenameFor(link)] = true // Mark the name as linked
+		}
+	}
+	failedPages := map[string]bool{}         // Pages whose links are unknown
+	for _, page := range crawl.FailedPages { // Index the failed pages
+		failedPages[page] = true // Mark the page
+	}
+
+	var orphans []string                           // Archive paths no longer linked anywhere
+	for _, directory := range archiveDirectories { // Check every archive directory
+		entries, err := os.ReadDir(directory) // List the directory
+		if err != nil {                       // Skip directories that do not exist
+			continue // Nothing to check
+		}
+		for _, directoryEntry := range entries { // Check every file
+			if directoryEntry.IsDir() || linkedNames[directoryEntry.Name()] { // Extracted folders and linked files are fine
+				continue // Not an orphan
+			}
+			archivePath := filepath.ToSlash(filepath.Join(directory, directoryEntry.Name())) // Manifest key of the file
+			entry := manifest.entries[archivePath]                                           // Provenance, if recorded
+			if entry != nil && linkedFromAny(entry.SourcePages, failedPages) {               // One of its pages failed to load
+				log.Printf("Not marking %s: a linking page failed to scrape", archivePath) // Log the skip
+				continue                                                                   // Cannot tell whether it was withdrawn
+			}
+			if entry == nil && len(failedPages) > 0 { // Unknown linking pages, and some pages failed to load
+				log.Printf("Not marking %s: its linking pages are unknown and some pages failed to scrape", archivePath) // Log the skip
+				continue                                                                                                 // Cannot tell whether it was withdrawn
+			}
+			orphans = append(orphans, archivePath)               // Record the orphan
+			manifest.markWithdrawn(archivePath, crawl.CrawledAt) // Flag it as withdrawn upstream
+		}
+	}
+	return orphans // Return the orphaned paths
+} // End of detectOrphans function
+
+// Reports whether any of the pages is in the set
+func linkedFromAny(pages []string, set map[string]bool) bool { // Function to intersect page lists
+	for _, page := range pages { // Check every page
+		if set[page] { // Found in the set
+			return true // At least one match
+		}
+	}
+	return false // No match
+} // End of linkedFromAny function
+
+// Prints the orphaned files from the latest crawl and marks them withdrawn upstream
+func printOrphans() { // Function backing the -orphans command-line mode
+	crawl, err := loadCrawl(crawlPath) // Load the latest crawl
+	if err != nil {                    // A crawl is required to compare against
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	orphans := detectOrphans(crawl, downloadManifest) // Find and mark orphans
+	for _, orphan := range orphans {                  // Print every orphan
+		fmt.Printf("withdrawn upstream\t%s\n", orphan) // One line per file
+	}
+	log.Printf("%d archived files are no longer linked (crawl of %s)", len(orphans), crawl.CrawledAt.Format(time.RFC3339)) // Log the summary
+	if err := downloadManifest.save(); err != nil {                                                                        // Persist the withdrawn markers
+		log.Println(err) // Log the write error
+	}
+} // End of printOrphans function
+
+// Permanently removes archived files that were downloaded by mistake, together with their history,
+// extracted contents, manifest entry (including the archive listing) and filename mapping
+func pruneArchivedFiles(archivePaths []string) { // Function backing the -prune command-line mode
+	prunedArchive := false                     // Whether an archive listing went away
+	for _, archivePath := range archivePaths { // Remove every requested file
+		archivePath = filepath.ToSlash(filepath.Clean(archivePath)) // Normalize the path
+		if !fileExists(archivePath) {                               // Refuse to guess at missing files
+			log.Printf("Not archived, cannot prune: %s", archivePath) // Log the miss
+			continue                                                  // Move on
+		}
+		if err := os.Remove(archivePath); err != nil { // Delete the file
+			log.Println(err) // Log the error
+			continue         // Move on
+		}
+		if err := os.RemoveAll(fileHistoryDirectoryFor(archivePath)); err != nil { // Delete its history
+			log.Println(err) // Log the error
+		}
+		if archiveFileTypeOf(archivePath) != "" { // Archives leave extracted contents behind
+			if err := os.RemoveAll(filepath.FromSlash(extractionDirectoryFor(archivePath))); err != nil { // Delete the extraction directory
+				log.Println(err) // Log the error
+			}
+			prunedArchive = prunedArchive || downloadManifest.hasArchiveListing(archivePath) // Remember the dropped listing
+		}
+		downloadManifest.remove(archivePath)                 // Forget its provenance and archive listing
+		archiveFilenames.release(filepath.Base(archivePath)) // Free its filename
+		log.Printf("Pruned %s", archivePath)                 // Log the removal
+	}
+	if err := downloadManifest.save(); err != nil { // Persist the manifest
+		log.Println(err) // Log the write error
+	}
+	if err := archiveFilenames.save(); err != nil { // Persist the filename map
+		log.Println(err) // Log the write error
+	}
+	if prunedArchive { // firmware.json may list images from the pruned archives
+		if err := rebuildFirmwareCatalog(downloadManifest); err != nil { // Catalog the remaining images
+			log.Println(err) // Log the failure
+		}
+	}
+} // End of pruneArchivedFiles function
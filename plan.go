@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json" // Emits the plan as JSON
+	"fmt"           // Prints the plan
+	"net/http"      // Sends HEAD requests
+	"os"            // Writes the plan to stdout
+	"strings"       // Normalizes ETags
+)
+
+// Actions a real run would take for a linked asset
+const (
+	planFetch  = "fetch"  // Not archived yet; would be downloaded
+	planSkip   = "skip"   // Archived and unchanged upstream
+	planUpdate = "update" // Archived, but upstream looks different (or could not be checked)
+	planReject = "reject" // Would fail: over a size limit, or a bad status or content type with the Chrome fallback off
+) // End of plan actions
+
+// One linked asset in the dry-run plan
+type plannedAsset struct {
+	Action      string   `json:"action"`       // fetch, skip, update or reject
+	URL         string   `json:"url"`          // Linked URL
+	Path        string   `json:"path"`         // Archive path it would be written to
+	FileType    string   `json:"file_type"`    // PDF, ZIP, TXT, TAR, RAR or 7Z
+	SourcePages []string `json:"source_pages"` // Pages linking to it
+	Reason      string   `json:"reason"`       // Why the action was chosen
+} // End of plannedAsset struct
+
+// One scraped page in the dry-run plan
+type plannedPage struct {
+	URL    string `json:"url"`    // Page URL
+	Links  int    `json:"links"`  // Asset links found on it
+	Failed bool   `json:"failed"` // Whether the scrape returned nothing
+} // End of plannedPage struct
+
+// What a run would crawl and download, collected instead of downloading when -dry-run is set
+type downloadPlan struct {
+	Pages  []plannedPage            `json:"pages"`  // Pages in crawl order
+	Assets []*plannedAsset          `json:"assets"` // Assets in discovery order
+	byURL  map[string]*plannedAsset // Planned assets by URL, to merge links from several pages
+} // End of downloadPlan struct
+
+var dryRunPlan *downloadPlan // Non-nil when -dry-run is set; downloadFile records here instead of downloading
+
+// Records a scraped page and how many asset links it had
+func (plan *downloadPlan) recordPage(pageURL, htmlContent string, links []string) { // Method to record a page
+	plan.Pages = append(plan.Pages, plannedPage{URL: pageURL, Links: len(removeDuplicatesFromSlice(links)), Failed: htmlContent == ""}) // Append the page
+} // End of recordPage method
+
+// Decides what a real run would do with a linked asset, using a HEAD request instead of downloading it
+func (plan *downloadPlan) recordAsset(fileURL, archivePath, sourcePage, fileType string, allowedContentTypes []string) { // Method to plan one asset
+	if planned, ok := plan.byURL[fileURL]; ok { // Linked from another page already
+		addSorted(&planned.SourcePages, sourcePage) // Add the page
+		return                                      // Already planned
+	}
+	planned := &plannedAsset{URL: fileURL, Path: archivePath, FileType: fileType, SourcePages: []string{sourcePage}} // New asset
+	planned.Action, planned.Reason = planAction(fileURL, archivePath, sourcePage, fileType, allowedContentTypes)     // Decide the action
+	plan.byURL[fileURL] = planned                                                                                    // Index it
+	plan.Assets = append(plan.Assets, planned)                                                                       // Keep discovery order
+} // End of recordAsset method
+
+// Compares the HEAD response for a URL with the archived copy and its manifest entry
+func planAction(fileURL, archivePath, sourcePage, fileType string, allowedContentTypes []string) (string, string) { // Function to choose a plan action
+	archived := fileExists(archivePath)                           // Whether a local copy exists
+	request, err := sharedSession.newRequest(fileURL, sourcePage) // Same headers as a real download
+	if err != nil {                                               // Malformed URL
+		return planReject, err.Error() // A real run would fail too
+	}
+	request.Method = http.MethodHead                  // Ask for the headers only
+	response, err := sharedSession.client.Do(request) // Send the request
+	if err != nil {                                   // Network error or blocked request
+		if archived { // A real run would re-download and compare
+			return planUpdate, fmt.Sprintf("archived; HEAD failed (%v), a real run re-downloads and compares", err) // Explain the uncertainty
+		}
+		return planFetch, fmt.Sprintf("not archived; HEAD failed (%v)", err) // A real run would still try (and may fall back to Chrome)
+	}
+	response.Body.Close() // HEAD responses have no body
+
+	problem := ""                                      // Why a plain HTTP download would fail
+	contentType := response.Header.Get("Content-Type") // Advertised content type
+	if response.StatusCode != http.StatusOK {          // A real download would fail on this status
+		problem = fmt.Sprintf("unexpected status %s", response.Status) // Describe the failure
+	} else if !contentTypeAllowed(contentType, allowedContentTypes) { // A real download would reject it
+		problem = fmt.Sprintf("invalid content type %s (expected %s)", contentType, strings.Join(allowedContentTypes, " or ")) // Describe the failure
+	}
+	if problem != "" && !browserFallbackEnabled { // Nothing would retry the download
+		return planReject, problem // Explain the rejection
+	}
+	if problem != "" && archived { // Chrome would retry; the result is compared with the archived copy
+		return planUpdate, problem + "; a real run retries through Chrome and compares" // Explain the uncertainty
+	}
+	if problem != "" { // Chrome would retry a new file
+		return planFetch, problem + "; a real run retries through Chrome" // Explain the uncertainty
+	}
+	if typeLimit, ok := downloadSizeLimits[fileType]; ok && response.ContentLength > typeLimit { // Over the per-type limit
+		return planReject, fmt.Sprintf("Content-Length %d exceeds %s limit of %d bytes", response.ContentLength, fileType, typeLimit) // Explain the rejection
+	}
+	if !archived { // Nothing archived under that name
+		return planFetch, "not archived yet" // A new file
+	}
+
+	entry := downloadManifest.entries[archivePath] // Provenance of the archived copy
+	etag := response.Header.Get("ETag")            // Upstream validator
+	switch {                                       // Compare what the server reports with what was archived
+	case entry == nil: // Archived before the manifest existed
+		return planUpdate, "archived without a manifest entry; a real run re-downloads and compares" // Cannot compare
+	case etag != "" && entry.ETag != "": // Both sides have an ETag
+		if strings.TrimPrefix(etag, "W/") == strings.TrimPrefix(entry.ETag, "W/") { // Same version upstream
+			return planSkip, "ETag unchanged" // Nothing to do
+		}
+		return planUpdate, fmt.Sprintf("ETag changed from %s to %s", entry.ETag, etag) // New version upstream
+	case response.ContentLength >= 0 && response.ContentLength != entry.Size: // Size differs
+		return planUpdate, fmt.Sprintf("size changed from %d to %d bytes", entry.Size, response.ContentLength) // New version upstream
+	case response.ContentLength >= 0: // Same size, no ETag to compare
+		return planSkip, "same size as the archived copy (no ETag to compare)" // Most likely unchanged
+	}
+	return planUpdate, "archived; the server sent neither ETag nor Content-Length, a real run re-downloads and compares" // Cannot compare
+} // End of planAction function
+
+// Prints the plan as tab-separated lines, or as indented JSON for scripts
+func (plan *downloadPlan) print(asJSON bool) error { // Method to output the plan
+	if asJSON { // Machine-readable output
+		encoder := json.NewEncoder(os.Stdout) // Write to stdout
+		encoder.SetIndent("", "  ")           // Match the other JSON files
+		return encoder.Encode(plan)           // Encode the plan
+	}
+	for _, page := range plan.Pages { // Print every page
+		status := fmt.Sprintf("%d links", page.Links) // Links found
+		if page.Failed {                              // The scrape failed
+			status = "scrape failed" // Say so
+		}
+		fmt.Printf("page\t%s\t%s\n", page.URL, status) // One line per page
+	}
+	counts := map[string]int{}          // Assets per action
+	for _, asset := range plan.Assets { // Print every asset
+		fmt.Printf("%s\t%s\t%s\t%s\n", asset.Action, asset.URL, asset.Path, asset.Reason) // One line per asset
+		counts[asset.Action]++                                                            // Count the action
+	}
+	fmt.Printf("%d pages; %d to fetch, %d to update, %d to skip, %d rejected\n", len(plan.Pages), counts[planFetch], counts[planUpdate], counts[planSkip], counts[planReject]) // Summary line
+	return nil                                                                                                                                                                 // Printed
+} // End of print method
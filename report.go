@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json" // Writes run-report.json
+	"fmt"           // Formats the Markdown summary
+	"log"           // Logs report problems
+	"path/filepath" // Normalizes archive paths
+	"strings"       // Builds the Markdown summary
+	"time"          // Timestamps the run and measures pages
+)
+
+const runReportPath = "run-report.json" // Machine-readable report of the latest run
+const runSummaryPath = "run-report.md"  // Markdown summary of the same run (e.g. for a CI job summary)
+
+// Outcomes of one linked asset in a run
+const (
+	assetDownloaded = "downloaded" // New file written to the archive
+	assetUpdated    = "updated"    // Archived file replaced by a new upstream version
+	assetSkipped    = "skipped"    // Upstream file identical to the archived copy
+	assetFailed     = "failed"     // Download, validation or write failed
+) // End of asset outcomes
+
+// One scraped page in the run report
+type pageReport struct {
+	URL             string  `json:"url"`              // Page URL
+	DurationSeconds float64 `json:"duration_seconds"` // Time spent rendering the page in Chrome
+	Links           int     `json:"links"`            // Asset links found on it
+	Failed          bool    `json:"failed"`           // Whether the scrape returned nothing
+} // End of pageReport struct
+
+// One linked asset in the run report
+type assetReport struct {
+	URL        string `json:"url"`              // Linked URL
+	Path       string `json:"path"`             // Archive path
+	FileType   string `json:"file_type"`        // PDF, ZIP, TXT, TAR, RAR or 7Z
+	SourcePage string `json:"source_page"`      // Page the link was found on
+	Outcome    string `json:"outcome"`          // downloaded, updated, skipped or failed
+	Reason     string `json:"reason,omitempty"` // Why it failed
+	Bytes      int64  `json:"bytes"`            // Bytes fetched for it
+} // End of assetReport struct
+
+// Everything a run did, written at the end of main instead of being left in interleaved log lines
+type runReport struct {
+	StartedAt        time.Time         `json:"started_at"`        // When the run started
+	FinishedAt       time.Time         `json:"finished_at"`       // When the report was written
+	Summary          string            `json:"summary"`           // One-line summary (e.g. for a commit message)
+	Counts           map[string]int    `json:"counts"`            // Assets per outcome
+	BytesTransferred int64             `json:"bytes_transferred"` // Bytes downloaded, including partial transfers
+	ByteBudget       int64             `json:"byte_budget"`       // Bytes the run was allowed to download
+	Pages            []pageReport      `json:"pages"`             // Pages in crawl order
+	Assets           []assetReport     `json:"assets"`            // Assets in download order
+	StoppedByLimit   []stoppedDownload `json:"stopped_by_limit"`  // Downloads stopped by a size limit or the budget
+} // End of runReport struct
+
+var currentRun = &runReport{StartedAt: time.Now().UTC(), Counts: map[string]int{}} // Report being collected by this run
+
+// Records a scraped page with how long it took and how many asset links it had
+func (report *runReport) recordPage(pageURL string, duration time.Duration, htmlContent string, links []string) { // Method to record a page
+	report.Pages = append(report.Pages, pageReport{ // Append the page
+		URL:             pageURL,                                    // Page URL
+		DurationSeconds: duration.Round(time.Millisecond).Seconds(), // Scrape time
+		Links:           len(removeDuplicatesFromSlice(links)),      // Distinct links
+		Failed:          htmlContent == "",                          // Empty HTML means the scrape failed
+	}) // End of page report
+} // End of recordPage method
+
+// Records what happened to a linked asset
+func (report *runReport) recordAsset(fileURL, archivePath, fileType, sourcePage, outcome, reason string, bytes int) { // Method to record an asset
+	report.Assets = append(report.Assets, assetReport{ // Append the asset
+		URL:        fileURL,                       // Linked URL
+		Path:       filepath.ToSlash(archivePath), // Archive path
+		FileType:   fileType,                      // Asset type
+		SourcePage: sourcePage,                    // Linking page
+		Outcome:    outcome,                       // What happened
+		Reason:     reason,                        // Why it failed, if it did
+		Bytes:      int64(bytes),                  // Bytes fetched
+	}) // End of asset report
+	report.Counts[outcome]++ // Count the outcome
+} // End of recordAsset method
+
+// Completes the report with the budget figures and writes run-report.json and run-report.md
+func (report *runReport) save(budget *downloadBudget) error { // Method to persist the run report
+	report.FinishedAt = time.Now().UTC()   // Finish time
+	report.BytesTransferred = budget.used  // Bytes downloaded
+	report.ByteBudget = budget.limit       // Bytes allowed
+	report.StoppedByLimit = budget.stopped // Downloads stopped by a limit
+
+	report.Summary = fmt.Sprintf("%d downloaded, %d updated, %d skipped, %d failed, %s transferred", // One-line summary
+		report.Counts[assetDownloaded], report.Counts[assetUpdated], report.Counts[assetSkipped], report.Counts[assetFailed], formatBytes(report.BytesTransferred)) // Counts and bytes
+
+	data, err := json.MarshalIndent(report, "", "  ") // Encode the report
+	if err != nil {                                   // Handle encoding errors
+		return err // Propagate the error
+	}
+	if err := writeFileAtomically(runReportPath, append(data, '\n')); err != nil { // Replace the report in one step
+		return fmt.Errorf("write %s: %w", runReportPath, err) // Report which file failed
+	}
+	if err := writeFileAtomically(runSummaryPath, []byte(report.markdown())); err != nil { // Replace the summary in one step
+		return fmt.Errorf("write %s: %w", runSummaryPath, err) // Report which file failed
+	}
+	log.Printf("Run report: %s (%s, %s)", report.Summary, runReportPath, runSummaryPath) // Log the summary
+	return nil                                                                           // Report written
+} // End of save method
+
+// Renders the report as Markdown: the summary, every page, and every asset that was not skipped
+func (report *runReport) markdown() string { // Method to build the Markdown summary
+	var builder strings.Builder                                                                                        // Output buffer
+	fmt.Fprintf(&builder, "# Archive run %s\n\n", report.StartedAt.Format("2006-01-02 15:04 UTC"))                     // Title
+	fmt.Fprintf(&builder, "%s in %s.\n\n", report.Summary, report.FinishedAt.Sub(report.StartedAt).Round(time.Second)) // Summary line
+	fmt.Fprintf(&builder, "## Pages\n\n| Page | Duration | Links | Status |\n| --- | ---: | ---: | --- |\n")           // Page table header
+	for _, page := range report.Pages {                                                                                // One row per page
+		status := "ok"   // Default status
+		if page.Failed { // The scrape failed
+			status = "**failed**" // Highlight it
+		}
+		fmt.Fprintf(&builder, "| %s | %.1fs | %d | %s |\n", page.URL, page.DurationSeconds, page.Links, status) // Page row
+	}
+	fmt.Fprintf(&builder, "\n## Assets\n\n")                                       // Asset section
+	for _, outcome := range []string{assetFailed, assetUpdated, assetDownloaded} { // Skipped assets are only counted
+		fmt.Fprintf(&builder, "### %s (%d)\n\n", strings.ToUpper(outcome[:1])+outcome[1:], report.Counts[outcome]) // Section title
+		if report.Counts[outcome] == 0 {                                                                           // Nothing in this section
+			continue // Move on
+		}
+		fmt.Fprintf(&builder, "| File | Type | Bytes | Reason |\n| --- | --- | ---: | --- |\n") // Asset table header
+		for _, asset := range report.Assets {                                                   // One row per asset with this outcome
+			if asset.Outcome == outcome { // Matching outcome
+				fmt.Fprintf(&builder, "| [%s](%s) | %s | %d | %s |\n", asset.Path, asset.URL, asset.FileType, asset.Bytes, strings.ReplaceAll(asset.Reason, "|", `\|`)) // Asset row
+			}
+		}
+		builder.WriteString("\n") // Separate the sections
+	}
+	fmt.Fprintf(&builder, "%d unchanged assets were skipped.\n", report.Counts[assetSkipped]) // Skipped count
+	if len(report.StoppedByLimit) > 0 {                                                       // Limits stopped some downloads
+		builder.WriteString("\n## Stopped by a limit\n\n") // Section title
+		for _, stopped := range report.StoppedByLimit {    // One line per stop
+			fmt.Fprintf(&builder, "- %s [%s]: %s\n", stopped.URL, stopped.FileType, stopped.Reason) // Stop line
+		}
+	}
+	return builder.String() // Return the Markdown
+} // End of markdown method
+
+// Formats a byte count with a binary unit ("12.3 MiB")
+func formatBytes(bytes int64) string { // Function to format sizes for people
+	const unit = 1024 // Binary units
+	if bytes < unit { // Small counts stay in bytes
+		return fmt.Sprintf("%d B", bytes) // Plain bytes
+	}
+	value := float64(bytes) / unit                   // Start at KiB
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB"} // Unit names
+	index := 0                                       // Current unit
+	for value >= unit && index < len(suffixes)-1 {   // Scale up while large
+		value /= unit // Next unit
+		index++       // Next suffix
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[index]) // Formatted size
+} // End of formatBytes function
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"            // Carries the chromedp browser context
+	"log"                // Logs captured session details
+	"net/http"           // Provides the shared HTTP client and transport
+	"net/http/cookiejar" // Stores cookies shared between Chrome and the downloaders
+	"net/url"            // Parses page and proxy URLs
+	"strings"            // Trims the leading dot of cookie domains
+	"time"               // Sets client timeouts and cookie expiry
+
+	"github.com/chromedp/cdproto/network" // Reads cookies from the Chrome session
+	"github.com/chromedp/chromedp"        // Evaluates JavaScript in the Chrome session
+)
+
+// Holds the cookies, User-Agent and proxy shared by Chrome and every Go HTTP download
+type browserSession struct {
+	jar       *cookiejar.Jar // Cookies copied from the Chrome session
+	userAgent string         // User-Agent reported by Chrome
+	proxyURL  *url.URL       // Optional proxy used by both Chrome and the downloaders
+	client    *http.Client   // HTTP client shared by all downloaders
+} // End of browserSession struct
+
+var sharedSession = newBrowserSession() // Session shared by the scraper and all downloaders
+
+// Creates a session with an empty cookie jar and a client that honours the configured proxy
+func newBrowserSession() *browserSession { // Function to build the shared session
+	jar, _ := cookiejar.New(nil)                                 // cookiejar.New never fails without options
+	session := &browserSession{jar: jar}                         // Start with no User-Agent or proxy
+	transport := http.DefaultTransport.(*http.Transport).Clone() // Start from the default transport settings
+	transport.Proxy = session.proxy                              // Route requests through the configured proxy, if any
+	session.client = &http.Client{                               // Build the shared client
+		Jar:       jar,              // Send cookies captured from Chrome
+		Transport: transport,        // Use the proxy-aware transport
+		Timeout:   15 * time.Minute, // Allow large files to finish
+	} // End of client
+	return session // Return the new session
+} // End of newBrowserSession function
+
+// Sets the proxy used by both Chrome and the Go downloaders; an empty string keeps the environment defaults
+func (session *browserSession) setProxy(rawProxy string) error { // Method to configure the optional proxy
+	if rawProxy == "" { // No proxy requested
+		session.proxyURL = nil // Fall back to HTTP(S)_PROXY from the environment
+		return nil             // Nothing more to do
+	}
+	proxyURL, err := url.Parse(rawProxy) // Parse the proxy address
+	if err != nil {                      // Handle malformed proxy URLs
+		return err // Propagate the error
+	}
+	session.proxyURL = proxyURL // Remember the proxy
+	return nil                  // Proxy configured
+} // End of setProxy method
+
+// Chooses the proxy for a request, preferring the configured proxy over the environment
+func (session *browserSession) proxy(request *http.Request) (*url.URL, error) { // Method used as http.Transport.Proxy
+	if session.proxyURL != nil { // An explicit proxy was configured
+		return session.proxyURL, nil // Use it for every request
+	}
+	return http.ProxyFromEnvironment(request) // Otherwise honour HTTP(S)_PROXY
+} // End of proxy method
+
+// Returns Chrome options that route the browser through the configured proxy
+func (session *browserSession) chromeOptions() []chromedp.ExecAllocatorOption { // Method to build proxy options for Chrome
+	if session.proxyURL == nil { // No proxy configured
+		return nil // Nothing to add
+	}
+	return []chromedp.ExecAllocatorOption{chromedp.ProxyServer(session.proxyURL.String())} // Send Chrome through the same proxy
+} // End of chromeOptions method
+
+// Copies the User-Agent and the cookies Chrome would send to pageURL or any of the linked files (which may live on a CDN
+// host) into the shared session
+func (session *browserSession) captureFromBrowser(ctx context.Context, pageURL string, fileURLs []string) error { // Method run as a chromedp action after the page was read
+	var userAgent string                                                                 // User-Agent reported by the browser
+	if err := chromedp.Evaluate(`navigator.userAgent`, &userAgent).Do(ctx); err != nil { // Ask Chrome for its User-Agent
+		return err // Propagate the error
+	}
+	session.userAgent = userAgent // Downloads should look like the browser that passed the challenge
+
+	cookieURLs := append([]string{pageURL}, fileURLs...)                     // Every URL the Go downloaders will request
+	browserCookies, err := network.GetCookies().WithURLs(cookieURLs).Do(ctx) // Read the cookies Chrome would send to them
+	if err != nil {                                                          // Handle protocol errors
+		return err // Propagate the error
+	}
+
+	for _, browserCookie := range browserCookies { // Convert every Chrome cookie
+		httpCookie := &http.Cookie{ // Map the fields net/http understands
+			Name:     browserCookie.Name,     // Cookie name
+			Value:    browserCookie.Value,    // Cookie value
+			Domain:   browserCookie.Domain,   // Cookie domain (may start with a dot)
+			Path:     browserCookie.Path,     // Cookie path
+			Secure:   browserCookie.Secure,   // HTTPS-only flag
+			HttpOnly: browserCookie.HTTPOnly, // Hidden from JavaScript
+		} // End of cookie conversion
+		if !browserCookie.Session && browserCookie.Expires > 0 { // Persistent cookies carry an expiry
+			httpCookie.Expires = time.Unix(int64(browserCookie.Expires), 0) // Convert seconds since the epoch
+		}
+		cookieURL := &url.URL{Scheme: "https", Host: strings.TrimPrefix(browserCookie.Domain, "."), Path: browserCookie.Path} // The jar only accepts cookies for their own domain
+		session.jar.SetCookies(cookieURL, []*http.Cookie{httpCookie})                                                         // Share the cookie with the Go downloaders
+	}
+
+	log.Printf("Captured %d cookies and User-Agent from Chrome for %s", len(browserCookies), pageURL) // Log what was shared
+	return nil                                                                                        // Capture succeeded
+} // End of captureFromBrowser method
+
+// Builds a GET request that carries the browser's User-Agent and the linking page as Referer
+func (session *browserSession) newRequest(fileURL, referer string) (*http.Request, error) { // Method to build a download request
+	request, err := http.NewRequest(http.MethodGet, fileURL, nil) // Create the GET request
+	if err != nil {                                               // Handle malformed URLs
+		return nil, err // Propagate the error
+	}
+	if session.userAgent != "" { // Only override once Chrome has reported its User-Agent
+		request.Header.Set("User-Agent", session.userAgent) // Match the browser that passed the challenge
+	}
+	if referer != "" { // Downloads are linked from a product page
+		request.Header.Set("Referer", referer) // Send the linking page as Referer
+	}
+	return request, nil // Return the prepared request
+} // End of newRequest method
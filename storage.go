@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"        // Detects missing objects
+	"fmt"           // Formats storage errors
+	"io/fs"         // Provides the shared not-exist error
+	"log"           // Logs export progress
+	"os"            // Provides file system access
+	"path/filepath" // Builds local paths
+	"strings"       // Parses storage specifications
+	"time"          // Reports modification times
+)
+
+// Where archived files (PDFs/, ZIPs/, TXTs/ and their history/) are written. Names always use
+// forward slashes and are relative to the archive root (e.g. "PDFs/mark5_manual.pdf").
+// Metadata such as manifest.json and version logs stays on the local disk so it can be committed.
+type Storage interface {
+	Get(name string) ([]byte, error)        // Reads a stored file; errors wrap fs.ErrNotExist when it is missing
+	Put(name string, data []byte) error     // Writes a file, replacing any previous contents
+	Move(oldName, newName string) error     // Renames a stored file
+	Stat(name string) (storedObject, error) // Describes a stored file; errors wrap fs.ErrNotExist when it is missing
+	Close() error                           // Flushes and releases the backend
+} // End of Storage interface
+
+// Size and modification time of a stored file
+type storedObject struct {
+	Size    int64     // Size in bytes
+	ModTime time.Time // Last modification time
+} // End of storedObject struct
+
+var archiveStorage Storage = localStorage{} // Backend used for archived files; the local file system by default
+
+// Creates the storage backend described by spec: "local", "tar:<path>", "zip:<path>" or "s3://<bucket>/<prefix>"
+func openStorage(spec string) (Storage, error) { // Function to select a storage backend
+	switch { // Pick the backend from the spec prefix
+	case spec == "" || spec == "local": // Default: the working tree
+		return localStorage{}, nil // Write next to the repository
+	case strings.HasPrefix(spec, "tar:"), strings.HasPrefix(spec, "zip:"): // Release snapshot bundle
+		return newBundleStorage(spec[4:]) // Open the bundle file
+	case strings.HasPrefix(spec, "s3://"): // S3-compatible object store
+		return newS3StorageFromEnvironment(strings.TrimPrefix(spec, "s3://")) // Configure from S3_* / AWS_* variables
+	}
+	return nil, fmt.Errorf("unknown storage %q (expected local, tar:<path>, zip:<path> or s3://<bucket>/<prefix>)", spec) // Report the bad spec
+} // End of openStorage function
+
+// Reports whether a storage spec selects the working tree, which is the only archive the offline modes read and edit
+func isLocalStorageSpec(spec string) bool { // Function to detect the local backend
+	return spec == "" || spec == "local" // Default backend
+} // End of isLocalStorageSpec function
+
+// Stores files in the working tree, exactly where the scraper always put them
+type localStorage struct{} // No state needed
+
+// Reads a local file
+func (localStorage) Get(name string) ([]byte, error) { // Method to read a local file
+	return os.ReadFile(filepath.FromSlash(name)) // os errors already wrap fs.ErrNotExist
+} // End of Get method
+
+// Writes a local file, creating parent directories as needed
+func (localStorage) Put(name string, data []byte) error { // Method to write a local file
+	localPath := filepath.FromSlash(name)                               // Convert to an OS path
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil { // Ensure the directory exists
+		return err // Propagate the error
+	}
+	return os.WriteFile(localPath, data, 0o644) // Write the file
+} // End of Put method
+
+// Renames a local file, creating the target directory as needed
+func (localStorage) Move(oldName, newName string) error { // Method to rename a local file
+	newPath := filepath.FromSlash(newName)                            // Convert to an OS path
+	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil { // Ensure the directory exists
+		return err // Propagate the error
+	}
+	return os.Rename(filepath.FromSlash(oldName), newPath) // Rename the file
+} // End of Move method
+
+// Describes a local file
+func (localStorage) Stat(name string) (storedObject, error) { // Method to stat a local file
+	info, err := os.Stat(filepath.FromSlash(name)) // Stat the file
+	if err != nil {                                // Handle missing files
+		return storedObject{}, err // os errors already wrap fs.ErrNotExist
+	}
+	if info.IsDir() { // Directories are not stored files
+		return storedObject{}, fmt.Errorf("%s is a directory: %w", name, fs.ErrNotExist) // Treat as missing
+	}
+	return storedObject{Size: info.Size(), ModTime: info.ModTime()}, nil // Return the description
+} // End of Stat method
+
+// Nothing to release for local storage
+func (localStorage) Close() error { // Method to close local storage
+	return nil // Nothing to do
+} // End of Close method
+
+// Reports whether a storage error means the file does not exist
+func isNotExist(err error) bool { // Function to detect missing objects
+	return errors.Is(err, fs.ErrNotExist) // All backends wrap fs.ErrNotExist
+} // End of isNotExist function
+
+// Copies every file under the given local directories into the storage backend (e.g. to build a
+// release bundle or seed an S3 bucket from the current working tree)
+func exportLocalArchive(storage Storage, directories []string) error { // Function to export the local archive
+	exported := 0                           // Number of files copied
+	for _, directory := range directories { // Walk every archive directory
+		if !directoryExists(directory) { // Skip directories that were never created
+			continue // Nothing to export
+		}
+		err := filepath.WalkDir(directory, func(walkPath string, entry fs.DirEntry, walkError error) error { // Visit every file
+			if walkError != nil { // Handle unreadable directories
+				return walkError // Stop the walk
+			}
+			if entry.IsDir() { // Directories are implied by file names
+				return nil // Continue the walk
+			}
+			data, err := os.ReadFile(walkPath) // Read the local file
+			if err != nil {                    // Handle read errors
+				return err // Stop the walk
+			}
+			exported++                                           // Count the file
+			return storage.Put(filepath.ToSlash(walkPath), data) // Store it under the same name
+		}) // End of directory walk
+		if err != nil { // Handle walk or storage errors
+			return err // Propagate the error
+		}
+	}
+	log.Printf("Exported %d files to storage", exported) // Log the result
+	return nil                                           // Export succeeded
+} // End of exportLocalArchive function
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"archive/tar"   // Writes tar bundles
+	"archive/zip"   // Writes zip bundles
+	"compress/gzip" // Compresses .tar.gz bundles
+	"errors"        // Creates bundle errors
+	"fmt"           // Formats bundle errors
+	"io"            // Abstracts the bundle writers
+	"io/fs"         // Provides the shared not-exist error
+	"log"           // Logs skipped duplicates
+	"os"            // Creates the bundle file
+	"strings"       // Inspects the bundle extension
+	"time"          // Stamps bundle entries
+)
+
+// Write-only storage that packs every stored file into a single tar, tar.gz or zip release snapshot.
+// The bundle starts empty, so every linked file is downloaded again and ends up in the snapshot; the version logs only
+// grow when the contents differ from the last logged version.
+type bundleStorage struct {
+	file      *os.File            // Underlying bundle file
+	gzipper   *gzip.Writer        // Compression layer for .tar.gz / .tgz bundles
+	tarWriter *tar.Writer         // Writer for tar bundles
+	zipWriter *zip.Writer         // Writer for zip bundles
+	written   map[string]struct{} // Names already in the bundle
+} // End of bundleStorage struct
+
+// Creates a bundle writer; the format follows the extension (.zip, .tar, .tar.gz or .tgz)
+func newBundleStorage(bundlePath string) (*bundleStorage, error) { // Function to open a bundle for writing
+	lowerPath := strings.ToLower(bundlePath)                                             // Compare extensions case-insensitively
+	if !strings.HasSuffix(lowerPath, ".zip") && !strings.HasSuffix(lowerPath, ".tar") && // Accept only known formats
+		!strings.HasSuffix(lowerPath, ".tar.gz") && !strings.HasSuffix(lowerPath, ".tgz") {
+		return nil, fmt.Errorf("bundle %s must end in .zip, .tar, .tar.gz or .tgz", bundlePath) // Report the bad name
+	}
+
+	file, err := os.Create(bundlePath) // Create the bundle file
+	if err != nil {                    // Handle creation errors
+		return nil, err // Propagate the error
+	}
+	bundle := &bundleStorage{file: file, written: map[string]struct{}{}} // Start with no entries
+
+	switch { // Set up the writer for the format
+	case strings.HasSuffix(lowerPath, ".zip"): // Zip bundle
+		bundle.zipWriter = zip.NewWriter(file) // Write zip entries directly
+	case strings.HasSuffix(lowerPath, ".tar"): // Plain tar bundle
+		bundle.tarWriter = tar.NewWriter(file) // Write tar entries directly
+	default: // Compressed tar bundle
+		bundle.gzipper = gzip.NewWriter(file)            // Compress the stream
+		bundle.tarWriter = tar.NewWriter(bundle.gzipper) // Write tar entries into it
+	}
+	return bundle, nil // Return the bundle
+} // End of newBundleStorage function
+
+// Bundles are write-only, so nothing is ever found
+func (bundle *bundleStorage) Get(name string) ([]byte, error) { // Method to read from a bundle
+	return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist) // Treat every file as new
+} // End of Get method
+
+// Appends a file to the bundle; a second write of the same name is skipped
+func (bundle *bundleStorage) Put(name string, data []byte) error { // Method to add a file to the bundle
+	if _, ok := bundle.written[name]; ok { // Archives cannot replace entries
+		log.Printf("Already in bundle, skipping: %s", name) // Log the duplicate
+		return nil                                          // Keep the first copy
+	}
+	bundle.written[name] = struct{}{} // Remember the entry
+
+	var entryWriter io.Writer    // Writer for the entry contents
+	if bundle.zipWriter != nil { // Zip bundle
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now().UTC()} // Describe the entry
+		writer, err := bundle.zipWriter.CreateHeader(header)                                   // Start the entry
+		if err != nil {                                                                        // Handle writer errors
+			return err // Propagate the error
+		}
+		entryWriter = writer // Write into the entry
+	} else { // Tar bundle
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now().UTC(), Typeflag: tar.TypeReg} // Describe the entry
+		if err := bundle.tarWriter.WriteHeader(header); err != nil {                                                             // Start the entry
+			return err // Propagate the error
+		}
+		entryWriter = bundle.tarWriter // Write into the entry
+	}
+	_, err := entryWriter.Write(data) // Write the contents
+	return err                        // Report write errors
+} // End of Put method
+
+// Bundles never contain an earlier copy to move
+func (bundle *bundleStorage) Move(oldName, newName string) error { // Method to rename inside a bundle
+	return errors.New("bundle storage is write-only and cannot move " + oldName) // Moves are impossible in an archive stream
+} // End of Move method
+
+// Bundles are write-only, so nothing is ever found
+func (bundle *bundleStorage) Stat(name string) (storedObject, error) { // Method to stat inside a bundle
+	return storedObject{}, fmt.Errorf("%s: %w", name, fs.ErrNotExist) // Treat every file as new
+} // End of Stat method
+
+// Finishes the archive and closes the bundle file
+func (bundle *bundleStorage) Close() error { // Method to finalize the bundle
+	var errs []error             // Collect every close error
+	if bundle.zipWriter != nil { // Finish the zip central directory
+		errs = append(errs, bundle.zipWriter.Close()) // Write the directory
+	}
+	if bundle.tarWriter != nil { // Finish the tar stream
+		errs = append(errs, bundle.tarWriter.Close()) // Write the end-of-archive blocks
+	}
+	if bundle.gzipper != nil { // Finish the gzip stream
+		errs = append(errs, bundle.gzipper.Close()) // Write the gzip footer
+	}
+	errs = append(errs, bundle.file.Close()) // Close the file
+	return errors.Join(errs...)              // Report every failure
+} // End of Close method
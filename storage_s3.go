@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"         // Sends object bodies
+	"crypto/hmac"   // Derives SigV4 signing keys
+	"crypto/sha256" // Hashes payloads and canonical requests
+	"encoding/hex"  // Encodes hashes and signatures
+	"errors"        // Creates configuration errors
+	"fmt"           // Formats requests and errors
+	"io"            // Reads response bodies
+	"io/fs"         // Provides the shared not-exist error
+	"net/http"      // Talks to the S3 API
+	"os"            // Reads credentials from the environment
+	"strings"       // Builds canonical requests
+	"time"          // Timestamps signatures and parses Last-Modified
+)
+
+// Stores files in an S3-compatible bucket (AWS S3, MinIO, ...) using path-style requests signed with SigV4
+type s3Storage struct {
+	endpoint  string       // Base URL of the S3 API, e.g. http://localhost:9000
+	region    string       // Signing region
+	bucket    string       // Bucket name
+	prefix    string       // Key prefix inside the bucket (may be empty)
+	accessKey string       // Access key ID
+	secretKey string       // Secret access key
+	client    *http.Client // HTTP client for API calls
+} // End of s3Storage struct
+
+// Configures S3 storage for "<bucket>/<prefix>" from S3_ENDPOINT, AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+// For a local MinIO: S3_ENDPOINT=http://localhost:9000 AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin.
+func newS3StorageFromEnvironment(bucketAndPrefix string) (*s3Storage, error) { // Function to configure S3 storage
+	bucket, prefix, _ := strings.Cut(bucketAndPrefix, "/") // Split the bucket from the key prefix
+	if bucket == "" {                                      // A bucket is mandatory
+		return nil, errors.New("s3 storage needs a bucket: s3://<bucket>/<prefix>") // Report the missing bucket
+	}
+	storage := &s3Storage{ // Read the configuration
+		endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"), // API endpoint
+		region:    os.Getenv("AWS_REGION"),                          // Signing region
+		bucket:    bucket,                                           // Bucket name
+		prefix:    strings.Trim(prefix, "/"),                        // Key prefix
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),                   // Access key
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),               // Secret key
+		client:    &http.Client{Timeout: 15 * time.Minute},          // Allow large uploads to finish
+	} // End of configuration
+	if storage.region == "" { // MinIO and most S3 clones accept the default region
+		storage.region = "us-east-1" // Default signing region
+	}
+	if storage.endpoint == "" { // Default to AWS itself
+		storage.endpoint = "https://s3." + storage.region + ".amazonaws.com" // Regional AWS endpoint
+	}
+	if storage.accessKey == "" || storage.secretKey == "" { // Credentials are mandatory
+		return nil, errors.New("s3 storage needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY") // Report the missing credentials
+	}
+	return storage, nil // Return the configured storage
+} // End of newS3StorageFromEnvironment function
+
+// Downloads an object
+func (storage *s3Storage) Get(name string) ([]byte, error) { // Method to read an object
+	response, err := storage.do(http.MethodGet, storage.objectPath(name), nil, nil) // Send a signed GET
+	if err != nil {                                                                 // Handle transport errors
+		return nil, err // Propagate the error
+	}
+	defer response.Body.Close()                             // Close the body when done
+	if err := checkS3Response(response, name); err != nil { // Handle API errors
+		return nil, err // Propagate the error
+	}
+	return io.ReadAll(response.Body) // Return the object contents
+} // End of Get method
+
+// Uploads an object
+func (storage *s3Storage) Put(name string, data []byte) error { // Method to write an object
+	response, err := storage.do(http.MethodPut, storage.objectPath(name), data, nil) // Send a signed PUT
+	if err != nil {                                                                  // Handle transport errors
+		return err // Propagate the error
+	}
+	defer response.Body.Close()            // Close the body when done
+	return checkS3Response(response, name) // Report API errors
+} // End of Put method
+
+// Copies an object to its new key and deletes the old one (S3 has no rename)
+func (storage *s3Storage) Move(oldName, newName string) error { // Method to rename an object
+	copySource := map[string]string{"x-amz-copy-source": storage.objectPath(oldName)}         // Server-side copy source
+	response, err := storage.do(http.MethodPut, storage.objectPath(newName), nil, copySource) // Send a signed copy request
+	if err != nil {                                                                           // Handle transport errors
+		return err // Propagate the error
+	}
+	body, _ := io.ReadAll(response.Body)                       // Copy errors can arrive with status 200
+	response.Body.Close()                                      // Close the body
+	if err := checkS3Response(response, oldName); err != nil { // Handle API errors
+		return err // Propagate the error
+	}
+	if bytes.Contains(body, []byte("<Error>")) { // Errors reported inside a 200 response
+		return fmt.Errorf("copy %s to %s: %s", oldName, newName, body) // Report the failed copy
+	}
+
+	response, err = storage.do(http.MethodDelete, storage.objectPath(oldName), nil, nil) // Delete the old key
+	if err != nil {                                                                      // Handle transport errors
+		return err // Propagate the error
+	}
+	defer response.Body.Close()               // Close the body when done
+	return checkS3Response(response, oldName) // Report API errors
+} // End of Move method
+
+// Reads an object's size and modification time
+func (storage *s3Storage) Stat(name string) (storedObject, error) { // Method to stat an object
+	response, err := storage.do(http.MethodHead, storage.objectPath(name), nil, nil) // Send a signed HEAD
+	if err != nil {                                                                  // Handle transport errors
+		return storedObject{}, err // Propagate the error
+	}
+	defer response.Body.Close()                             // Close the body when done
+	if err := checkS3Response(response, name); err != nil { // Handle API errors
+		return storedObject{}, err // Propagate the error
+	}
+	modTime, _ := http.ParseTime(response.Header.Get("Last-Modified"))       // Zero time if the header is missing
+	return storedObject{Size: response.ContentLength, ModTime: modTime}, nil // Return the description
+} // End of Stat method
+
+// Nothing to release for S3 storage
+func (storage *s3Storage) Close() error { // Method to close S3 storage
+	storage.client.CloseIdleConnections() // Drop pooled connections
+	return nil                            // Nothing else to do
+} // End of Close method
+
+// Returns the URI-encoded path-style path of an object: /<bucket>/<prefix>/<name>
+func (storage *s3Storage) objectPath(name string) string { // Method to build an object path
+	key := name               // Key without prefix
+	if storage.prefix != "" { // Apply the key prefix
+		key = storage.prefix + "/" + name // Prefix the key
+	}
+	return "/" + s3URIEncode(storage.bucket) + "/" + s3URIEncode(key) // Encode bucket and key
+} // End of objectPath method
+
+// Sends a request signed with AWS Signature Version 4
+func (storage *s3Storage) do(method, encodedPath string, body []byte, extraHeaders map[string]string) (*http.Response, error) { // Method to send a signed request
+	request, err := http.NewRequest(method, storage.endpoint+encodedPath, bytes.NewReader(body)) // Build the request
+	if err != nil {                                                                              // Handle malformed endpoints
+		return nil, err // Propagate the error
+	}
+	request.ContentLength = int64(len(body)) // Always send an explicit length
+
+	now := time.Now().UTC()                                                        // Signing time
+	amzDate := now.Format("20060102T150405Z")                                      // Full timestamp
+	shortDate := now.Format("20060102")                                            // Date for the credential scope
+	payloadHash := sha256.Sum256(body)                                             // Hash of the request body
+	request.Header.Set("x-amz-date", amzDate)                                      // Signed timestamp header
+	request.Header.Set("x-amz-content-sha256", hex.EncodeToString(payloadHash[:])) // Signed payload hash header
+	for headerName, headerValue := range extraHeaders {                            // Add request-specific headers
+		request.Header.Set(headerName, headerValue) // Set the header
+	}
+
+	signedHeaderNames := []string{"host", "x-amz-content-sha256", "x-amz-date"} // Headers covered by the signature
+	for headerName := range extraHeaders {                                      // Sign the extra headers too
+		addSorted(&signedHeaderNames, strings.ToLower(headerName)) // Keep the list sorted
+	}
+	var canonicalHeaders strings.Builder           // Canonical header block
+	for _, headerName := range signedHeaderNames { // Build "name:value\n" lines
+		headerValue := request.Header.Get(headerName) // Look up the value
+		if headerName == "host" {                     // Go sends Host from the URL, not the header map
+			headerValue = request.URL.Host // Use the URL host
+		}
+		canonicalHeaders.WriteString(headerName + ":" + strings.TrimSpace(headerValue) + "\n") // Append the line
+	}
+	signedHeaders := strings.Join(signedHeaderNames, ";") // Semicolon-separated signed header list
+
+	canonicalRequest := strings.Join([]string{ // Assemble the canonical request
+		method,                             // HTTP method
+		encodedPath,                        // Canonical URI
+		"",                                 // Canonical query string (none)
+		canonicalHeaders.String(),          // Canonical headers
+		signedHeaders,                      // Signed header list
+		hex.EncodeToString(payloadHash[:]), // Payload hash
+	}, "\n") // End of canonical request
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))                                                    // Hash of the canonical request
+	scope := shortDate + "/" + storage.region + "/s3/aws4_request"                                              // Credential scope
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:]) // String to sign
+
+	signingKey := hmacSHA256([]byte("AWS4"+storage.secretKey), shortDate) // Derive the date key
+	signingKey = hmacSHA256(signingKey, storage.region)                   // Derive the region key
+	signingKey = hmacSHA256(signingKey, "s3")                             // Derive the service key
+	signingKey = hmacSHA256(signingKey, "aws4_request")                   // Derive the signing key
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign)) // Sign the request
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", storage.accessKey, scope, signedHeaders, signature)) // Attach the signature
+	return storage.client.Do(request)                                                                                                                                         // Send the request
+} // End of do method
+
+// Converts an S3 API response status into an error, wrapping fs.ErrNotExist for 404
+func checkS3Response(response *http.Response, name string) error { // Function to check S3 responses
+	switch { // Map the status code
+	case response.StatusCode == http.StatusNotFound: // Missing object
+		return fmt.Errorf("%s: %w", name, fs.ErrNotExist) // Report as not existing
+	case response.StatusCode >= 300: // Any other failure
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))     // Read the error document
+		return fmt.Errorf("s3 %s: %s %s", name, response.Status, body) // Report the failure
+	}
+	return nil // Success
+} // End of checkS3Response function
+
+// URI-encodes a key the way SigV4 expects: everything except unreserved characters and '/'
+func s3URIEncode(key string) string { // Function to encode object keys
+	var encoded strings.Builder     // Encoded output
+	for _, b := range []byte(key) { // Encode byte by byte
+		switch { // Decide whether to escape the byte
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~', b == '/': // Unreserved characters and path separators
+			encoded.WriteByte(b) // Keep the byte
+		default: // Everything else
+			fmt.Fprintf(&encoded, "%%%02X", b) // Percent-encode the byte
+		}
+	}
+	return encoded.String() // Return the encoded key
+} // End of s3URIEncode function
+
+// Returns HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte { // Function to compute an HMAC
+	mac := hmac.New(sha256.New, key) // Create the HMAC
+	mac.Write([]byte(data))          // Hash the data
+	return mac.Sum(nil)              // Return the digest
+} // End of hmacSHA256 function
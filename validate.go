@@ -0,0 +1,176 @@
+package main
+
+import (
+	"archive/zip"   // Reads ZIP central directories and verifies entry CRCs
+	"bytes"         // Searches downloaded data for structural markers
+	"encoding/json" // Writes quarantine reason files
+	"errors"        // Creates validation errors
+	"fmt"           // Formats validation errors
+	"io"            // Drains ZIP entries to trigger CRC checks
+	"log"           // Logs quarantined files
+	"os"            // Provides file system access
+	"path/filepath" // Builds quarantine paths
+	"regexp"        // Matches PDF cross-reference structures
+	"strconv"       // Parses the startxref offset
+	"strings"       // Normalizes text for HTML detection
+	"time"          // Timestamps quarantine records
+	"unicode/utf8"  // Validates TXT encoding
+)
+
+const quarantineDirectory = "quarantine/" // Directory holding downloads that failed validation
+
+var pdfXrefSubsection = regexp.MustCompile(`^xref\s+\d+\s+\d+\s`)         // Classic cross-reference table with its first subsection header
+var pdfXrefStream = regexp.MustCompile(`^\d+\s+\d+\s+obj\b[\s\S]*?/XRef`) // Cross-reference stream object (PDF 1.5+)
+
+// Describes why a download was quarantined
+type quarantineRecord struct {
+	SourceURL     string    `json:"source_url"`            // URL the file was downloaded from
+	SourcePage    string    `json:"source_page,omitempty"` // Page that linked to the file
+	QuarantinedAt time.Time `json:"quarantined_at"`        // When the file failed validation
+	Reason        string    `json:"reason"`                // Validation failure message
+} // End of quarantineRecord struct
+
+// Checks that downloaded data is structurally valid for its file type
+func validateDownload(fileType string, data []byte) error { // Function to dispatch format-aware validation
+	switch fileType { // Pick the validator for the asset type
+	case "PDF": // Portable Document Format
+		return validatePDF(data) // Check header, trailer and xref
+	case "ZIP": // ZIP archive
+		return validateZIP(data) // Check central directory and CRCs
+	case "TXT": // Plain text
+		return validateTXT(data) // Check encoding and reject HTML
+	case "TAR": // tar or tar.gz archive
+		return validateTAR(data) // Check gzip checksum and tar headers
+	case "RAR": // RAR archive
+		return validateRAR(data) // Check signature and header CRCs
+	case "7Z": // 7-Zip archive
+		return validate7z(data) // Check signature and header CRCs
+	}
+	return nil // Unknown types are not validated
+} // End of validateDownload function
+
+// Checks the PDF header, the %%EOF trailer and that startxref points at a parseable cross-reference section
+func validatePDF(data []byte) error { // Function to validate PDF structure
+	headerWindow := data[:min(len(data), 1024)]         // The header may be preceded by up to 1 KiB of junk
+	if !bytes.Contains(headerWindow, []byte("%PDF-")) { // Look for the PDF signature
+		return errors.New("missing %PDF- header") // Not a PDF at all
+	}
+
+	trailerWindow := data[max(0, len(data)-1024):]       // The trailer lives in the last 1 KiB
+	if !bytes.Contains(trailerWindow, []byte("%%EOF")) { // Look for the end-of-file marker
+		return errors.New("missing %%EOF trailer (truncated download?)") // Most likely a partial transfer
+	}
+
+	startxrefIndex := bytes.LastIndex(trailerWindow, []byte("startxref")) // Find the last startxref keyword
+	if startxrefIndex == -1 {                                             // Every PDF must end with startxref
+		return errors.New("missing startxref") // Report the broken trailer
+	}
+	offsetFields := strings.Fields(string(trailerWindow[startxrefIndex+len("startxref"):])) // The offset follows the keyword
+	if len(offsetFields) == 0 {                                                             // Nothing after the keyword
+		return errors.New("startxref has no offset") // Report the broken trailer
+	}
+	xrefOffset, err := strconv.ParseInt(offsetFields[0], 10, 64)        // Parse the byte offset
+	if err != nil || xrefOffset < 0 || xrefOffset >= int64(len(data)) { // Reject offsets outside the file
+		return fmt.Errorf("startxref offset %q out of range", offsetFields[0]) // Report the bad offset
+	}
+
+	xrefSection := bytes.TrimLeft(data[xrefOffset:min(int64(len(data)), xrefOffset+4096)], " \t\r\n") // Section the offset points at
+	if !pdfXrefSubsection.Match(xrefSection) && !pdfXrefStream.Match(xrefSection) {                   // Accept either xref table or xref stream
+		return fmt.Errorf("no cross-reference section at offset %d", xrefOffset) // Report the unparseable xref
+	}
+	return nil // The PDF looks structurally sound
+} // End of validatePDF function
+
+// Checks that the ZIP central directory is readable and every entry matches its CRC-32
+func validateZIP(data []byte) error { // Function to validate ZIP structure
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data))) // Read the central directory
+	if err != nil {                                                        // Handle unreadable archives
+		return fmt.Errorf("unreadable central directory: %w", err) // Report the failure
+	}
+	if len(archive.File) == 0 { // Empty archives are useless
+		return errors.New("archive has no entries") // Report the empty archive
+	}
+	for _, entry := range archive.File { // Check every entry
+		entryReader, err := entry.Open() // Open the entry for reading
+		if err != nil {                  // Handle unsupported or corrupt entries
+			return fmt.Errorf("entry %s: %w", entry.Name, err) // Report the entry
+		}
+		_, err = io.Copy(io.Discard, entryReader) // Reading to EOF verifies the CRC-32
+		entryReader.Close()                       // Release the entry reader
+		if err != nil {                           // Handle checksum or decompression errors
+			return fmt.Errorf("entry %s: %w", entry.Name, err) // Report the entry
+		}
+	}
+	return nil // Every entry decompressed and matched its checksum
+} // End of validateZIP function
+
+// Checks that a tar (or tar.gz) archive inflates completely and every header parses
+func validateTAR(data []byte) error { // Function to validate tar structure
+	members, err := tarMembers(data) // Inflate and read every header
+	if err != nil {                  // Handle corrupt or truncated archives
+		return fmt.Errorf("unreadable tar archive: %w", err) // Report the failure
+	}
+	if len(members) == 0 { // Empty archives are useless
+		return errors.New("archive has no entries") // Report the empty archive
+	}
+	return nil // The archive is complete
+} // End of validateTAR function
+
+// Checks the RAR signature and every header CRC up to the end-of-archive block. Archives with encrypted
+// headers are accepted because their structure cannot be checked without the password.
+func validateRAR(data []byte) error { // Function to validate RAR structure
+	_, err := listRAR(data)                                    // Walk every block
+	if err != nil && !errors.Is(err, errRARHeadersEncrypted) { // Encrypted headers are not an error
+		return err // Report the corruption
+	}
+	return nil // The headers are intact
+} // End of validateRAR function
+
+// Checks the 7z signature header and the CRCs of the start and end headers (which also catches truncation,
+// since the end header sits at the end of the file)
+func validate7z(data []byte) error { // Function to validate 7z structure
+	_, err := check7zHeaders(data) // Verify the headers
+	return err                     // Report any corruption
+} // End of validate7z function
+
+// Checks that text is valid UTF-8 and not an HTML page served in place of the file
+func validateTXT(data []byte) error { // Function to validate TXT content
+	if !utf8.Valid(data) { // Reject invalid byte sequences
+		return errors.New("not valid UTF-8") // Report the encoding problem
+	}
+	head := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(string(data[:min(len(data), 512)]), "\uFEFF")))           // Normalized start of the file
+	if strings.HasPrefix(head, "<!doctype html") || strings.HasPrefix(head, "<html") || strings.Contains(head, "<head>") { // Look for HTML markers
+		return errors.New("looks like an HTML page, not a text file") // Most likely an error or challenge page
+	}
+	return nil // The text looks genuine
+} // End of validateTXT function
+
+// Moves a download that failed validation into the quarantine directory alongside a JSON reason file
+func quarantineDownload(filename string, data []byte, sourceURL, sourcePage string, reason error) { // Function to quarantine a bad download
+	if err := os.MkdirAll(quarantineDirectory, 0o755); err != nil { // Ensure the quarantine directory exists
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	quarantinePath := filepath.Join(quarantineDirectory, filename)    // Path of the quarantined file
+	if err := os.WriteFile(quarantinePath, data, 0o644); err != nil { // Keep the bad bytes for inspection
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+
+	record := quarantineRecord{ // Describe why the file was rejected
+		SourceURL:     sourceURL,        // Origin URL
+		SourcePage:    sourcePage,       // Linking page
+		QuarantinedAt: time.Now().UTC(), // Rejection time
+		Reason:        reason.Error(),   // Validation failure
+	} // End of quarantine record
+	recordData, err := json.MarshalIndent(record, "", "  ") // Encode the record
+	if err != nil {                                         // Handle encoding errors
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	if err := os.WriteFile(quarantinePath+".json", append(recordData, '\n'), 0o644); err != nil { // Write the reason file
+		log.Println(err) // Log the error
+		return           // Nothing more to do
+	}
+	log.Printf("Quarantined %s: %v → %s", sourceURL, reason, quarantinePath) // Log the quarantine
+} // End of quarantineDownload function
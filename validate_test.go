@@ -0,0 +1,154 @@
+package main
+
+import (
+	"archive/tar"     // Builds tar fixtures
+	"archive/zip"     // Builds ZIP fixtures
+	"bytes"           // Assembles fixtures
+	"compress/gzip"   // Builds tar.gz fixtures
+	"encoding/binary" // Writes archive header fields
+	"fmt"             // Lays out the PDF fixture
+	"hash/crc32"      // Checksums RAR and 7z headers
+	"os"              // Reads archived files
+	"slices"          // Concatenates fixture parts
+	"strings"         // Matches error messages
+	"testing"         // Test framework
+)
+
+const fixtureText = "GEPRC firmware notes\n" // Contents of the archive fixtures
+
+// Returns a minimal PDF whose startxref points at its cross-reference table
+func pdfFixture() []byte { // Helper to build a valid PDF
+	var pdf bytes.Buffer                                                                                  // Document
+	pdf.WriteString("%PDF-1.4\n")                                                                         // Header
+	catalog := pdf.Len()                                                                                  // Offset of object 1
+	pdf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")                               // Catalog
+	pages := pdf.Len()                                                                                    // Offset of object 2
+	pdf.WriteString("2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n")                            // Empty page tree
+	xref := pdf.Len()                                                                                     // Offset of the table
+	fmt.Fprintf(&pdf, "xref\n0 3\n0000000000 65535 f \n%010d 00000 n \n%010d 00000 n \n", catalog, pages) // Cross-reference table
+	fmt.Fprintf(&pdf, "trailer\n<< /Size 3 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", xref)               // Trailer
+	return pdf.Bytes()                                                                                    // Return the document
+} // End of pdfFixture function
+
+// Returns a ZIP archive holding one stored entry, so its contents can be corrupted in place
+func zipFixture(t *testing.T) []byte { // Helper to build a valid ZIP
+	var archive bytes.Buffer                                                                  // Archive
+	writer := zip.NewWriter(&archive)                                                         // ZIP writer
+	entry, err := writer.CreateHeader(&zip.FileHeader{Name: "readme.txt", Method: zip.Store}) // Uncompressed entry
+	if err != nil {                                                                           // Should never happen
+		t.Fatal(err) // Stop the test
+	}
+	entry.Write([]byte(fixtureText))       // Entry contents
+	if err := writer.Close(); err != nil { // Write the central directory
+		t.Fatal(err) // Stop the test
+	}
+	return archive.Bytes() // Return the archive
+} // End of zipFixture function
+
+// Returns a tar.gz archive holding one file
+func tarFixture(t *testing.T) []byte { // Helper to build a valid tar.gz
+	var archive bytes.Buffer                                                                                                   // Compressed archive
+	gzipWriter := gzip.NewWriter(&archive)                                                                                     // gzip layer
+	tarWriter := tar.NewWriter(gzipWriter)                                                                                     // tar layer
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "readme.txt", Mode: 0o644, Size: int64(len(fixtureText))}); err != nil { // File header
+		t.Fatal(err) // Stop the test
+	}
+	tarWriter.Write([]byte(fixtureText))                                   // File contents
+	if err := tarWriter.Close(); err != nil || gzipWriter.Close() != nil { // Finish both layers
+		t.Fatal(err) // Stop the test
+	}
+	return archive.Bytes() // Return the archive
+} // End of tarFixture function
+
+// Returns a RAR 5 block: header CRC32, a one-byte header size, then the fields
+func rar5Block(fields ...byte) []byte { // Helper to build a RAR 5 block
+	sized := append([]byte{byte(len(fields))}, fields...)                                     // Size vint, then the header
+	return append(binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(sized)), sized...) // Checksum of size and header
+} // End of rar5Block function
+
+// Returns a RAR 5 archive with a main header, one stored file and an end block
+func rarFixture() []byte { // Helper to build a valid RAR
+	file := []byte{2, 0x02, byte(len(fixtureText)), 0, byte(len(fixtureText)), 0, 0, 0, 10}                                                       // Type, flags, data size, file flags, size, attributes, method, host, name length
+	return slices.Concat(rar5Signature, rar5Block(1, 0, 0), rar5Block(append(file, "readme.txt"...)...), []byte(fixtureText), rar5Block(5, 0, 0)) // Main header, file, data, end
+} // End of rarFixture function
+
+// Returns a 7z archive whose end header follows the signature header directly
+func sevenZipFixture() []byte { // Helper to build a valid 7z
+	header := []byte{0x01, 0x00}                                                   // Plain header with no contents
+	start := binary.LittleEndian.AppendUint64(nil, 0)                              // End header offset after the signature header
+	start = binary.LittleEndian.AppendUint64(start, uint64(len(header)))           // End header size
+	start = binary.LittleEndian.AppendUint32(start, crc32.ChecksumIEEE(header))    // End header CRC
+	startCRC := binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(start))   // Start header CRC
+	return slices.Concat(sevenZipSignature, []byte{0, 4}, startCRC, start, header) // Signature, version, start header, end header
+} // End of sevenZipFixture function
+
+// Checks that a well-formed file of every validated type passes
+func TestValidateDownloadValid(t *testing.T) { // Test of the valid fixtures
+	fixtures := map[string][]byte{ // Fixture per type
+		"PDF": pdfFixture(),                                                       // Minimal document
+		"ZIP": zipFixture(t),                                                      // One stored entry
+		"TXT": []byte("\ufeff# Betaflight / STM32F7X2 (S7X2) 4.2.11\r\nsave\r\n"), // CLI dump with a BOM and CRLF
+		"TAR": tarFixture(t),                                                      // One file, gzipped
+		"RAR": rarFixture(),                                                       // RAR 5, one stored file
+		"7Z":  sevenZipFixture(),                                                  // Empty plain header
+	} // End of fixtures
+	for fileType, data := range fixtures { // Validate every fixture
+		if err := validateDownload(fileType, data); err != nil { // Rejected
+			t.Errorf("%s: %v", fileType, err) // Report it
+		}
+	}
+} // End of TestValidateDownloadValid function
+
+// Checks that files already in the archive pass, one per type the site serves
+func TestValidateDownloadArchived(t *testing.T) { // Test of real downloads
+	files := map[string]string{ // Archived file per type
+		"PDF": "PDFs/tinygo_bind.pdf",              // Smallest manual
+		"ZIP": "ZIPs/geprc_nano_2g4rx_3_0_0.zip",   // Receiver firmware
+		"TXT": "TXTs/mark5_analog_4_2_11_crsf.txt", // Betaflight dump
+	} // End of files
+	for fileType, path := range files { // Validate every file
+		data, err := os.ReadFile(path) // Read it
+		if err != nil {                // Missing from the checkout
+			t.Fatal(err) // Stop the test
+		}
+		if err := validateDownload(fileType, data); err != nil { // Rejected
+			t.Errorf("%s: %v", path, err) // Report it
+		}
+	}
+} // End of TestValidateDownloadArchived function
+
+// Checks that broken downloads are rejected with the reason that is quarantined alongside them
+func TestValidateDownloadInvalid(t *testing.T) { // Test of the broken fixtures
+	pdf := pdfFixture()                                                                  // Valid document
+	badCRC := zipFixture(t)                                                              // Valid archive
+	index := bytes.Index(badCRC, []byte(fixtureText))                                    // Stored entry contents
+	badCRC[index] ^= 0xff                                                                // Flip one byte; the CRC no longer matches
+	badStartXref := bytes.Replace(pdf, []byte("startxref\n"), []byte("startxref\n9"), 1) // Offset past the end of the file
+	tar := tarFixture(t)                                                                 // Valid archive
+	rar := rarFixture()                                                                  // Valid archive
+	rar[len(rar5Signature)+4] ^= 0x01                                                    // Change the main header size; its CRC no longer matches
+	sevenZip := sevenZipFixture()                                                        // Valid archive
+	tests := []struct {
+		name     string // Case name
+		fileType string // Validator
+		data     []byte // File content
+		reason   string // Expected part of the error
+	}{
+		{name: "truncated pdf", fileType: "PDF", data: pdf[:len(pdf)/2], reason: "missing %%EOF trailer"},                                                         // Partial transfer
+		{name: "pdf without header", fileType: "PDF", data: []byte("<html><body>Not found</body></html>%%EOF"), reason: "missing %PDF- header"},                   // Error page
+		{name: "pdf xref out of range", fileType: "PDF", data: badStartXref, reason: "out of range"},                                                              // Broken trailer
+		{name: "zip bad crc", fileType: "ZIP", data: badCRC, reason: "checksum error"},                                                                            // Corrupted contents
+		{name: "truncated zip", fileType: "ZIP", data: zipFixture(t)[:40], reason: "unreadable central directory"},                                                // Partial transfer
+		{name: "html as txt", fileType: "TXT", data: []byte("\n<!DOCTYPE html>\n<html><head><title>Just a moment...</title></head></html>"), reason: "HTML page"}, // Challenge page
+		{name: "invalid utf8 txt", fileType: "TXT", data: []byte("set name = \xff\xfe\n"), reason: "not valid UTF-8"},                                             // Binary served as text
+		{name: "truncated tar", fileType: "TAR", data: tar[:len(tar)-12], reason: "unreadable tar archive"},                                                       // gzip trailer missing
+		{name: "rar bad crc", fileType: "RAR", data: rar, reason: "CRC mismatch"},                                                                                 // Corrupted header
+		{name: "truncated 7z", fileType: "7Z", data: sevenZip[:len(sevenZip)-1], reason: "truncated 7z archive"},                                                  // End header cut off
+	} // End of cases
+	for _, test := range tests { // Run every case
+		err := validateDownload(test.fileType, test.data)              // Validate the broken file
+		if err == nil || !strings.Contains(err.Error(), test.reason) { // Wrong verdict
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.reason) // Report it
+		}
+	}
+} // End of TestValidateDownloadInvalid function